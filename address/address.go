@@ -1,5 +1,13 @@
 package address
 
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+)
+
 const URL = "addresses"
 
 type ValidateReq struct {
@@ -12,11 +20,150 @@ type ValidateReq struct {
 	Zipcode  string `json:"zipcode"`
 }
 
+type Deliverability string
+
+const (
+	Deliverable              Deliverability = "deliverable"
+	DeliverableIncorrectUnit Deliverability = "deliverable_incorrect_unit"
+	DeliverableMissingUnit   Deliverability = "deliverable_missing_unit"
+	DeliverableUnnecessary   Deliverability = "deliverable_unnecessary_unit"
+	Undeliverable            Deliverability = "undeliverable"
+)
+
+// Flag is a yes/no indicator that Stannp may send as a bool, a number, a Y/N string or not at all
+type Flag int
+
+const (
+	FlagUnknown Flag = iota
+	FlagNo
+	FlagYes
+)
+
+// ComponentMatch describes how one part of the submitted address matched the reference data
+type ComponentMatch struct {
+	Component string `json:"component"`
+	Input     string `json:"input"`
+	Matched   string `json:"matched"`
+	Status    string `json:"status"`
+}
+
+// Data is the validation result. The address fields hold the standardized, possibly corrected, address.
 type Data struct {
-	IsValid bool `json:"is_valid"`
+	Address1       string           `json:"address1"`
+	Address2       string           `json:"address2"`
+	City           string           `json:"city"`
+	Company        string           `json:"company"`
+	Components     []ComponentMatch `json:"components"`
+	Country        string           `json:"country"`
+	Deliverability Deliverability   `json:"deliverability"`
+	DPVMatchCode   string           `json:"dpv_match_code"`
+	IsResidential  Flag             `json:"is_residential"`
+	IsVacant       Flag             `json:"is_vacant"`
+	IsValid        bool             `json:"is_valid"`
+	State          string           `json:"state"`
+	Zipcode        string           `json:"zipcode"`
 }
 
 type ValidateRes struct {
 	Data    Data `json:"data"`
 	Success bool `json:"success"`
 }
+
+func (f Flag) Bool() (value bool, known bool) {
+	return f == FlagYes, f != FlagUnknown
+}
+
+func (f Flag) MarshalJSON() ([]byte, error) {
+	switch f {
+	case FlagYes:
+		return []byte("true"), nil
+	case FlagNo:
+		return []byte("false"), nil
+	default:
+		return []byte("null"), nil
+	}
+}
+
+func (f *Flag) UnmarshalJSON(data []byte) error {
+	raw := strings.ToLower(strings.Trim(string(bytes.TrimSpace(data)), `"`))
+	switch raw {
+	case "true", "1", "y", "yes":
+		*f = FlagYes
+	case "false", "0", "n", "no":
+		*f = FlagNo
+	case "null", "", "u", "unknown":
+		*f = FlagUnknown
+	default:
+		return fmt.Errorf("unrecognised flag value [%s]", string(data))
+	}
+
+	return nil
+}
+
+// HasCorrectedAddress reports whether Stannp returned a standardized address
+func (d *Data) HasCorrectedAddress() bool {
+	return d.Address1 != ""
+}
+
+// IsDeliverable is true for every deliverable variant, including those with unit problems
+func (d *Data) IsDeliverable() bool {
+	return strings.HasPrefix(string(d.Deliverability), string(Deliverable))
+}
+
+// CorrectedAddress returns the standardized address, or req unchanged when none was returned
+func (d *Data) CorrectedAddress(req ValidateReq) ValidateReq {
+	if !d.HasCorrectedAddress() {
+		return req
+	}
+
+	corrected := ValidateReq{
+		Address1: d.Address1,
+		Address2: d.Address2,
+		City:     d.City,
+		Company:  d.Company,
+		Country:  d.Country,
+		State:    d.State,
+		Zipcode:  d.Zipcode,
+	}
+
+	if corrected.Company == "" {
+		corrected.Company = req.Company
+	}
+
+	if corrected.Country == "" {
+		corrected.Country = req.Country
+	}
+
+	return corrected
+}
+
+// RecipientDetails applies the standardized address to recipient, keeping the recipient's name and title
+func (d *Data) RecipientDetails(recipient letter.RecipientDetails) letter.RecipientDetails {
+	if !d.HasCorrectedAddress() {
+		return recipient
+	}
+
+	recipient.Address1 = d.Address1
+	recipient.Address2 = d.Address2
+	recipient.State = d.State
+	recipient.Town = d.City
+	recipient.Zipcode = d.Zipcode
+
+	if d.Country != "" {
+		recipient.Country = d.Country
+	}
+
+	return recipient
+}
+
+// ValidateReqFromRecipient builds the validation request for a letter recipient
+func ValidateReqFromRecipient(recipient letter.RecipientDetails) ValidateReq {
+	return ValidateReq{
+		Address1: recipient.Address1,
+		Address2: recipient.Address2,
+		City:     recipient.Town,
+		Country:  recipient.Country,
+		State:    recipient.State,
+		Zipcode:  recipient.Zipcode,
+	}
+}
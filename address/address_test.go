@@ -0,0 +1,71 @@
+package address
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestFlag_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected Flag
+	}{
+		{`true`, FlagYes},
+		{`false`, FlagNo},
+		{`1`, FlagYes},
+		{`0`, FlagNo},
+		{`"Y"`, FlagYes},
+		{`"n"`, FlagNo},
+		{`null`, FlagUnknown},
+		{`""`, FlagUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			var flag Flag
+			assert.Nil(t, json.Unmarshal([]byte(tt.raw), &flag))
+			assert.Equal(t, tt.expected, flag)
+		})
+	}
+
+	var flag Flag
+	assert.NotNil(t, json.Unmarshal([]byte(`"maybe"`), &flag))
+}
+
+func TestData_CorrectedAddress(t *testing.T) {
+	req := ValidateReq{Address1: "9355 burton wy", City: "beverly hills", Company: "Courthouse", Country: "US", Zipcode: "90210"}
+
+	uncorrected := Data{IsValid: false}
+	assert.Equal(t, req, uncorrected.CorrectedAddress(req))
+
+	corrected := Data{Address1: "9355 BURTON WAY", City: "BEVERLY HILLS", State: "CA", Zipcode: "90210-3669"}
+	assert.Equal(t, ValidateReq{
+		Address1: "9355 BURTON WAY",
+		City:     "BEVERLY HILLS",
+		Company:  "Courthouse",
+		Country:  "US",
+		State:    "CA",
+		Zipcode:  "90210-3669",
+	}, corrected.CorrectedAddress(req))
+
+	recipient := letter.RecipientDetails{Address1: "9355 burton wy", Country: "US", Firstname: "Judge", Title: "Mrs."}
+	assert.Equal(t, letter.RecipientDetails{
+		Address1:  "9355 BURTON WAY",
+		Country:   "US",
+		Firstname: "Judge",
+		State:     "CA",
+		Title:     "Mrs.",
+		Town:      "BEVERLY HILLS",
+		Zipcode:   "90210-3669",
+	}, corrected.RecipientDetails(recipient))
+}
+
+func TestData_IsDeliverable(t *testing.T) {
+	assert.True(t, (&Data{Deliverability: Deliverable}).IsDeliverable())
+	assert.True(t, (&Data{Deliverability: DeliverableMissingUnit}).IsDeliverable())
+	assert.False(t, (&Data{Deliverability: Undeliverable}).IsDeliverable())
+	assert.False(t, (&Data{}).IsDeliverable())
+}
@@ -0,0 +1,189 @@
+// Package cache keeps address validation results so the same address isn't paid for twice. Results are keyed on the
+// normalized address, so differently formatted copies of one address share an entry.
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/address"
+	"github.com/copilotiq/stannp-client-golang/address/normalize"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+const DefaultMaxEntries = 10000
+const DefaultNegativeTTL = 24 * time.Hour
+const DefaultTTL = 30 * 24 * time.Hour
+
+// Entry is a cached validation result and when it stops being served
+type Entry struct {
+	Expires time.Time           `json:"expires"`
+	Key     string              `json:"key"`
+	Result  address.ValidateRes `json:"result"`
+}
+
+// Backend stores cache entries. Backends bound their own size and must be safe for concurrent use. Get reports false
+// when the key isn't stored.
+type Backend interface {
+	Delete(ctx context.Context, key string) *util.APIError
+	Get(ctx context.Context, key string) (*Entry, bool, *util.APIError)
+	Set(ctx context.Context, entry *Entry) *util.APIError
+}
+
+// Stats counts cache lookups since the Cache was created. Negative hits are included in Hits. Errors are backend
+// failures, which are treated as misses.
+type Stats struct {
+	Errors       int64 `json:"errors"`
+	Hits         int64 `json:"hits"`
+	Misses       int64 `json:"misses"`
+	NegativeHits int64 `json:"negativeHits"`
+}
+
+type Option func(*Cache)
+
+// Cache serves validation results from a Backend until they expire. Invalid addresses are kept for a separate, usually
+// shorter, TTL so a corrected address on Stannp's side is picked up sooner.
+type Cache struct {
+	backend      Backend
+	errors       atomic.Int64
+	hits         atomic.Int64
+	misses       atomic.Int64
+	negativeHits atomic.Int64
+	negativeTTL  time.Duration
+	now          func() time.Time
+	onLookup     func(key string, hit bool)
+	ttl          time.Duration
+}
+
+// WithBackend replaces the default in-memory LRU backend, for example with a FileBackend
+func WithBackend(backend Backend) Option {
+	return func(c *Cache) {
+		c.backend = backend
+	}
+}
+
+// WithClock overrides time.Now, which is useful in tests
+func WithClock(now func() time.Time) Option {
+	return func(c *Cache) {
+		c.now = now
+	}
+}
+
+// WithNegativeTTL sets how long invalid addresses are cached. Zero or less disables caching them.
+func WithNegativeTTL(negativeTTL time.Duration) Option {
+	return func(c *Cache) {
+		c.negativeTTL = negativeTTL
+	}
+}
+
+// WithOnLookup registers a callback run after every lookup with the cache key and whether it was a hit
+func WithOnLookup(onLookup func(key string, hit bool)) Option {
+	return func(c *Cache) {
+		c.onLookup = onLookup
+	}
+}
+
+// WithTTL sets how long valid addresses are cached. Zero or less disables caching them.
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+func New(opts ...Option) *Cache {
+	c := &Cache{
+		backend:     NewMemoryBackend(DefaultMaxEntries),
+		negativeTTL: DefaultNegativeTTL,
+		now:         time.Now,
+		ttl:         DefaultTTL,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Key is the cache key for req, which is the same for every formatting of one address
+func Key(req *address.ValidateReq) string {
+	return normalize.Key(*req)
+}
+
+// Get returns the cached result for req. Expired entries and backend errors count as misses.
+func (c *Cache) Get(ctx context.Context, req *address.ValidateReq) (*address.ValidateRes, bool) {
+	key := Key(req)
+
+	entry, ok, getErr := c.backend.Get(ctx, key)
+	if getErr != nil {
+		c.errors.Add(1)
+		ok = false
+	}
+
+	if ok && !c.now().Before(entry.Expires) {
+		// best effort, an expired entry is never served
+		_ = c.backend.Delete(ctx, key)
+		ok = false
+	}
+
+	if c.onLookup != nil {
+		c.onLookup(key, ok)
+	}
+
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	if isNegative(&entry.Result) {
+		c.negativeHits.Add(1)
+	}
+
+	return clone(&entry.Result), true
+}
+
+// Set caches res for req with the TTL that matches its outcome. Unsuccessful responses are never cached.
+func (c *Cache) Set(ctx context.Context, req *address.ValidateReq, res *address.ValidateRes) *util.APIError {
+	if res == nil || !res.Success {
+		return nil
+	}
+
+	ttl := c.ttl
+	if isNegative(res) {
+		ttl = c.negativeTTL
+	}
+
+	if ttl <= 0 {
+		return nil
+	}
+
+	setErr := c.backend.Set(ctx, &Entry{Expires: c.now().Add(ttl), Key: Key(req), Result: *clone(res)})
+	if setErr != nil {
+		c.errors.Add(1)
+	}
+
+	return setErr
+}
+
+// Stats returns the lookup counters so far
+func (c *Cache) Stats() Stats {
+	return Stats{
+		Errors:       c.errors.Load(),
+		Hits:         c.hits.Load(),
+		Misses:       c.misses.Load(),
+		NegativeHits: c.negativeHits.Load(),
+	}
+}
+
+func isNegative(res *address.ValidateRes) bool {
+	return !res.Data.IsValid
+}
+
+// clone copies res so callers can't modify what's cached
+func clone(res *address.ValidateRes) *address.ValidateRes {
+	copied := *res
+	copied.Data.Components = append([]address.ComponentMatch(nil), res.Data.Components...)
+	return &copied
+}
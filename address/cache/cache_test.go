@@ -0,0 +1,214 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/address"
+	"github.com/jgroeneveld/trial/assert"
+)
+
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func validRes(zipcode string) *address.ValidateRes {
+	return &address.ValidateRes{Data: address.Data{Deliverability: address.Deliverable, IsValid: true, Zipcode: zipcode}, Success: true}
+}
+
+func TestCache_KeyedOnNormalizedAddress(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+
+	assert.True(t, reflect.ValueOf(c.Set(ctx, &address.ValidateReq{Address1: "123 Main Street", City: "Springfield", State: "Illinois"}, validRes("62701"))).IsNil())
+
+	cached, ok := c.Get(ctx, &address.ValidateReq{Address1: "123 MAIN ST.", City: "springfield", Country: "US", State: "IL"})
+	assert.True(t, ok)
+	assert.Equal(t, "62701", cached.Data.Zipcode)
+
+	_, ok = c.Get(ctx, &address.ValidateReq{Address1: "124 Main Street", City: "Springfield", State: "IL"})
+	assert.False(t, ok)
+
+	assert.Equal(t, Stats{Hits: 1, Misses: 1}, c.Stats())
+}
+
+func TestCache_TTLs(t *testing.T) {
+	ctx := context.Background()
+	now := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := New(WithClock(now.Now), WithTTL(48*time.Hour), WithNegativeTTL(time.Hour))
+
+	valid := &address.ValidateReq{Address1: "1 Valid Way"}
+	invalid := &address.ValidateReq{Address1: "1 Invalid Way"}
+	assert.True(t, reflect.ValueOf(c.Set(ctx, valid, validRes("10001"))).IsNil())
+	assert.True(t, reflect.ValueOf(c.Set(ctx, invalid, &address.ValidateRes{Data: address.Data{Deliverability: address.Undeliverable}, Success: true})).IsNil())
+
+	_, ok := c.Get(ctx, invalid)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), c.Stats().NegativeHits)
+
+	now.Advance(time.Hour)
+	_, ok = c.Get(ctx, invalid)
+	assert.False(t, ok)
+	_, ok = c.Get(ctx, valid)
+	assert.True(t, ok)
+
+	now.Advance(47 * time.Hour)
+	_, ok = c.Get(ctx, valid)
+	assert.False(t, ok)
+
+	assert.Equal(t, Stats{Hits: 2, Misses: 2, NegativeHits: 1}, c.Stats())
+}
+
+func TestCache_SkipsUncacheableResults(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryBackend(0)
+	c := New(WithBackend(backend), WithNegativeTTL(0))
+
+	assert.True(t, reflect.ValueOf(c.Set(ctx, &address.ValidateReq{Address1: "1 Failed Way"}, &address.ValidateRes{Success: false})).IsNil())
+	assert.True(t, reflect.ValueOf(c.Set(ctx, &address.ValidateReq{Address1: "1 Invalid Way"}, &address.ValidateRes{Success: true})).IsNil())
+	assert.True(t, reflect.ValueOf(c.Set(ctx, &address.ValidateReq{Address1: "1 Valid Way"}, nil)).IsNil())
+
+	assert.Equal(t, 0, backend.Len())
+}
+
+func TestCache_ReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+	req := &address.ValidateReq{Address1: "1 Main St"}
+
+	res := validRes("10001")
+	res.Data.Components = []address.ComponentMatch{{Component: "city", Status: "corrected"}}
+	assert.True(t, reflect.ValueOf(c.Set(ctx, req, res)).IsNil())
+	res.Data.Components[0].Status = "changed by caller"
+
+	cached, _ := c.Get(ctx, req)
+	cached.Data.Zipcode = "changed by caller"
+
+	cached, _ = c.Get(ctx, req)
+	assert.Equal(t, "10001", cached.Data.Zipcode)
+	assert.Equal(t, "corrected", cached.Data.Components[0].Status)
+}
+
+func TestCache_OnLookup(t *testing.T) {
+	ctx := context.Background()
+	var lookups []bool
+	c := New(WithOnLookup(func(key string, hit bool) {
+		assert.Equal(t, "|1 MAIN ST|||||US", key)
+		lookups = append(lookups, hit)
+	}))
+
+	req := &address.ValidateReq{Address1: "1 main street"}
+	c.Get(ctx, req)
+	assert.True(t, reflect.ValueOf(c.Set(ctx, req, validRes("10001"))).IsNil())
+	c.Get(ctx, req)
+
+	assert.Equal(t, 2, len(lookups))
+	assert.False(t, lookups[0])
+	assert.True(t, lookups[1])
+}
+
+func TestMemoryBackend_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryBackend(2)
+	c := New(WithBackend(backend))
+
+	first := &address.ValidateReq{Address1: "1 Main St"}
+	second := &address.ValidateReq{Address1: "2 Main St"}
+	third := &address.ValidateReq{Address1: "3 Main St"}
+
+	assert.True(t, reflect.ValueOf(c.Set(ctx, first, validRes("10001"))).IsNil())
+	assert.True(t, reflect.ValueOf(c.Set(ctx, second, validRes("10002"))).IsNil())
+	_, ok := c.Get(ctx, first)
+	assert.True(t, ok)
+
+	assert.True(t, reflect.ValueOf(c.Set(ctx, third, validRes("10003"))).IsNil())
+	assert.Equal(t, 2, backend.Len())
+
+	_, ok = c.Get(ctx, second)
+	assert.False(t, ok)
+	_, ok = c.Get(ctx, first)
+	assert.True(t, ok)
+	_, ok = c.Get(ctx, third)
+	assert.True(t, ok)
+}
+
+func TestFileBackend(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	backend, openErr := NewFileBackend(dir, 2)
+	assert.True(t, reflect.ValueOf(openErr).IsNil())
+	c := New(WithBackend(backend))
+
+	first := &address.ValidateReq{Address1: "1 Main St"}
+	second := &address.ValidateReq{Address1: "2 Main St"}
+	third := &address.ValidateReq{Address1: "3 Main St"}
+
+	assert.True(t, reflect.ValueOf(c.Set(ctx, first, validRes("10001"))).IsNil())
+	assert.True(t, reflect.ValueOf(c.Set(ctx, second, validRes("10002"))).IsNil())
+	assert.True(t, reflect.ValueOf(c.Set(ctx, third, validRes("10003"))).IsNil())
+	assert.Equal(t, 2, backend.Len())
+
+	// pin the modification times since writes in quick succession can share one on coarse filesystems
+	base := time.Now().Add(-time.Minute)
+	assert.Nil(t, os.Chtimes(backend.path(fileName(Key(second))), base, base))
+	assert.Nil(t, os.Chtimes(backend.path(fileName(Key(third))), base.Add(time.Second), base.Add(time.Second)))
+
+	// a new backend over the same directory picks up where the last one left off
+	reopened, reopenErr := NewFileBackend(dir, 1)
+	assert.True(t, reflect.ValueOf(reopenErr).IsNil())
+	assert.Equal(t, 1, reopened.Len())
+
+	c = New(WithBackend(reopened))
+	cached, ok := c.Get(ctx, third)
+	assert.True(t, ok)
+	assert.Equal(t, "10003", cached.Data.Zipcode)
+
+	_, ok = c.Get(ctx, first)
+	assert.False(t, ok)
+
+	assert.True(t, reflect.ValueOf(reopened.Delete(ctx, Key(third))).IsNil())
+	_, ok = c.Get(ctx, third)
+	assert.False(t, ok)
+}
+
+func TestCache_ConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithBackend(NewMemoryBackend(10)))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := &address.ValidateReq{Address1: fmt.Sprintf("%d Main St", i%15)}
+			for j := 0; j < 50; j++ {
+				if _, ok := c.Get(ctx, req); !ok {
+					_ = c.Set(ctx, req, validRes("10001"))
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats := c.Stats()
+	assert.Equal(t, int64(20*50), stats.Hits+stats.Misses)
+}
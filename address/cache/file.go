@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+const EntryExtension = ".json"
+
+// FileBackend keeps one JSON file per entry in a directory so cached results survive process restarts. It holds at
+// most maxEntries files, evicting the least recently used first; recency is carried across restarts by the files'
+// modification times. Entries are written with util.WriteFileAtomic.
+type FileBackend struct {
+	dir string
+	lru *lru
+	mu  sync.Mutex
+}
+
+var _ Backend = (*FileBackend)(nil)
+
+// NewFileBackend opens the cache in dir, creating it if needed. Zero or less maxEntries means unbounded.
+func NewFileBackend(dir string, maxEntries int) (*FileBackend, *util.APIError) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, util.BuildError(500, err.Error())
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, util.BuildError(500, err.Error())
+	}
+
+	type cachedFile struct {
+		modified time.Time
+		name     string
+	}
+
+	var cached []cachedFile
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), EntryExtension) {
+			continue
+		}
+
+		info, infoErr := file.Info()
+		if infoErr != nil {
+			return nil, util.BuildError(500, infoErr.Error())
+		}
+		cached = append(cached, cachedFile{modified: info.ModTime(), name: strings.TrimSuffix(file.Name(), EntryExtension)})
+	}
+
+	sort.SliceStable(cached, func(i, j int) bool {
+		return cached[i].modified.Before(cached[j].modified)
+	})
+
+	fb := &FileBackend{dir: dir, lru: newLRU(maxEntries)}
+	for _, file := range cached {
+		for _, evicted := range fb.lru.touch(file.name) {
+			if removeErr := fb.remove(evicted); removeErr != nil {
+				return nil, removeErr
+			}
+		}
+	}
+
+	return fb, nil
+}
+
+func (fb *FileBackend) Delete(_ context.Context, key string) *util.APIError {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	name := fileName(key)
+	fb.lru.remove(name)
+	return fb.remove(name)
+}
+
+func (fb *FileBackend) Get(_ context.Context, key string) (*Entry, bool, *util.APIError) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	name := fileName(key)
+	contents, err := os.ReadFile(fb.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, util.BuildError(500, err.Error())
+	}
+
+	var entry Entry
+	if jsonErr := json.Unmarshal(contents, &entry); jsonErr != nil {
+		return nil, false, util.BuildError(500, fmt.Sprintf("error unmarshalling cache entry [%s] with err [%+v]", name, jsonErr))
+	}
+
+	// a different key hashing to the same name is treated as a miss
+	if entry.Key != key {
+		return nil, false, nil
+	}
+
+	fb.lru.touch(name)
+	now := time.Now()
+	_ = os.Chtimes(fb.path(name), now, now)
+
+	return &entry, true, nil
+}
+
+// Len returns the number of entries held, including any that have expired but not yet been looked up
+func (fb *FileBackend) Len() int {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	return fb.lru.len()
+}
+
+func (fb *FileBackend) Set(_ context.Context, entry *Entry) *util.APIError {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	name := fileName(entry.Key)
+	if writeErr := fb.write(name, entry); writeErr != nil {
+		return writeErr
+	}
+
+	for _, evicted := range fb.lru.touch(name) {
+		if removeErr := fb.remove(evicted); removeErr != nil {
+			return removeErr
+		}
+	}
+
+	return nil
+}
+
+func (fb *FileBackend) path(name string) string {
+	return filepath.Join(fb.dir, name+EntryExtension)
+}
+
+func (fb *FileBackend) remove(name string) *util.APIError {
+	if err := os.Remove(fb.path(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return util.BuildError(500, err.Error())
+	}
+
+	return nil
+}
+
+func (fb *FileBackend) write(name string, entry *Entry) *util.APIError {
+	contents, err := json.Marshal(entry)
+	if err != nil {
+		return util.BuildError(500, err.Error())
+	}
+
+	_, writeErr := util.WriteFileAtomic(fb.path(name), bytes.NewReader(contents))
+	return writeErr
+}
+
+// fileName hashes key since normalized addresses aren't safe file names
+func fileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,47 @@
+package cache
+
+import "container/list"
+
+// lru tracks keys from most to least recently used
+type lru struct {
+	elements   map[string]*list.Element
+	maxEntries int
+	order      *list.List
+}
+
+func newLRU(maxEntries int) *lru {
+	return &lru{elements: map[string]*list.Element{}, maxEntries: maxEntries, order: list.New()}
+}
+
+// touch marks key as the most recently used and returns the keys evicted to make room for it
+func (l *lru) touch(key string) []string {
+	if element, ok := l.elements[key]; ok {
+		l.order.MoveToFront(element)
+		return nil
+	}
+
+	l.elements[key] = l.order.PushFront(key)
+
+	var evicted []string
+	for l.maxEntries > 0 && l.order.Len() > l.maxEntries {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+
+		oldestKey := oldest.Value.(string)
+		delete(l.elements, oldestKey)
+		evicted = append(evicted, oldestKey)
+	}
+
+	return evicted
+}
+
+func (l *lru) remove(key string) {
+	if element, ok := l.elements[key]; ok {
+		l.order.Remove(element)
+		delete(l.elements, key)
+	}
+}
+
+func (l *lru) len() int {
+	return l.order.Len()
+}
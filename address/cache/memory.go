@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"sync"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// MemoryBackend keeps up to maxEntries entries in memory, evicting the least recently used first
+type MemoryBackend struct {
+	entries map[string]Entry
+	lru     *lru
+	mu      sync.Mutex
+}
+
+var _ Backend = (*MemoryBackend)(nil)
+
+// NewMemoryBackend holds at most maxEntries entries. Zero or less means unbounded.
+func NewMemoryBackend(maxEntries int) *MemoryBackend {
+	return &MemoryBackend{entries: map[string]Entry{}, lru: newLRU(maxEntries)}
+}
+
+func (mb *MemoryBackend) Delete(_ context.Context, key string) *util.APIError {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	delete(mb.entries, key)
+	mb.lru.remove(key)
+	return nil
+}
+
+func (mb *MemoryBackend) Get(_ context.Context, key string) (*Entry, bool, *util.APIError) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	entry, ok := mb.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	mb.lru.touch(key)
+	return &entry, true, nil
+}
+
+// Len returns the number of entries held, including any that have expired but not yet been looked up
+func (mb *MemoryBackend) Len() int {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	return mb.lru.len()
+}
+
+func (mb *MemoryBackend) Set(_ context.Context, entry *Entry) *util.APIError {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	mb.entries[entry.Key] = *entry
+	for _, evicted := range mb.lru.touch(entry.Key) {
+		delete(mb.entries, evicted)
+	}
+
+	return nil
+}
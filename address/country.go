@@ -0,0 +1,376 @@
+package address
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Country is an ISO 3166-1 alpha-2 country code such as "US" or "GB"
+type Country string
+
+const (
+	CountryCA Country = "CA"
+	CountryGB Country = "GB"
+	CountryUS Country = "US"
+)
+
+type countryInfo struct {
+	alpha2 Country
+	alpha3 string
+	name   string
+}
+
+// countries is every officially assigned ISO 3166-1 code
+var countries = []countryInfo{
+	{"AD", "AND", "Andorra"},
+	{"AE", "ARE", "United Arab Emirates"},
+	{"AF", "AFG", "Afghanistan"},
+	{"AG", "ATG", "Antigua and Barbuda"},
+	{"AI", "AIA", "Anguilla"},
+	{"AL", "ALB", "Albania"},
+	{"AM", "ARM", "Armenia"},
+	{"AO", "AGO", "Angola"},
+	{"AQ", "ATA", "Antarctica"},
+	{"AR", "ARG", "Argentina"},
+	{"AS", "ASM", "American Samoa"},
+	{"AT", "AUT", "Austria"},
+	{"AU", "AUS", "Australia"},
+	{"AW", "ABW", "Aruba"},
+	{"AX", "ALA", "Aland Islands"},
+	{"AZ", "AZE", "Azerbaijan"},
+	{"BA", "BIH", "Bosnia and Herzegovina"},
+	{"BB", "BRB", "Barbados"},
+	{"BD", "BGD", "Bangladesh"},
+	{"BE", "BEL", "Belgium"},
+	{"BF", "BFA", "Burkina Faso"},
+	{"BG", "BGR", "Bulgaria"},
+	{"BH", "BHR", "Bahrain"},
+	{"BI", "BDI", "Burundi"},
+	{"BJ", "BEN", "Benin"},
+	{"BL", "BLM", "Saint Barthelemy"},
+	{"BM", "BMU", "Bermuda"},
+	{"BN", "BRN", "Brunei Darussalam"},
+	{"BO", "BOL", "Bolivia"},
+	{"BQ", "BES", "Bonaire, Sint Eustatius and Saba"},
+	{"BR", "BRA", "Brazil"},
+	{"BS", "BHS", "Bahamas"},
+	{"BT", "BTN", "Bhutan"},
+	{"BV", "BVT", "Bouvet Island"},
+	{"BW", "BWA", "Botswana"},
+	{"BY", "BLR", "Belarus"},
+	{"BZ", "BLZ", "Belize"},
+	{"CA", "CAN", "Canada"},
+	{"CC", "CCK", "Cocos (Keeling) Islands"},
+	{"CD", "COD", "Congo, Democratic Republic of the"},
+	{"CF", "CAF", "Central African Republic"},
+	{"CG", "COG", "Congo"},
+	{"CH", "CHE", "Switzerland"},
+	{"CI", "CIV", "Cote d'Ivoire"},
+	{"CK", "COK", "Cook Islands"},
+	{"CL", "CHL", "Chile"},
+	{"CM", "CMR", "Cameroon"},
+	{"CN", "CHN", "China"},
+	{"CO", "COL", "Colombia"},
+	{"CR", "CRI", "Costa Rica"},
+	{"CU", "CUB", "Cuba"},
+	{"CV", "CPV", "Cabo Verde"},
+	{"CW", "CUW", "Curacao"},
+	{"CX", "CXR", "Christmas Island"},
+	{"CY", "CYP", "Cyprus"},
+	{"CZ", "CZE", "Czechia"},
+	{"DE", "DEU", "Germany"},
+	{"DJ", "DJI", "Djibouti"},
+	{"DK", "DNK", "Denmark"},
+	{"DM", "DMA", "Dominica"},
+	{"DO", "DOM", "Dominican Republic"},
+	{"DZ", "DZA", "Algeria"},
+	{"EC", "ECU", "Ecuador"},
+	{"EE", "EST", "Estonia"},
+	{"EG", "EGY", "Egypt"},
+	{"EH", "ESH", "Western Sahara"},
+	{"ER", "ERI", "Eritrea"},
+	{"ES", "ESP", "Spain"},
+	{"ET", "ETH", "Ethiopia"},
+	{"FI", "FIN", "Finland"},
+	{"FJ", "FJI", "Fiji"},
+	{"FK", "FLK", "Falkland Islands (Malvinas)"},
+	{"FM", "FSM", "Micronesia, Federated States of"},
+	{"FO", "FRO", "Faroe Islands"},
+	{"FR", "FRA", "France"},
+	{"GA", "GAB", "Gabon"},
+	{"GB", "GBR", "United Kingdom"},
+	{"GD", "GRD", "Grenada"},
+	{"GE", "GEO", "Georgia"},
+	{"GF", "GUF", "French Guiana"},
+	{"GG", "GGY", "Guernsey"},
+	{"GH", "GHA", "Ghana"},
+	{"GI", "GIB", "Gibraltar"},
+	{"GL", "GRL", "Greenland"},
+	{"GM", "GMB", "Gambia"},
+	{"GN", "GIN", "Guinea"},
+	{"GP", "GLP", "Guadeloupe"},
+	{"GQ", "GNQ", "Equatorial Guinea"},
+	{"GR", "GRC", "Greece"},
+	{"GS", "SGS", "South Georgia and the South Sandwich Islands"},
+	{"GT", "GTM", "Guatemala"},
+	{"GU", "GUM", "Guam"},
+	{"GW", "GNB", "Guinea-Bissau"},
+	{"GY", "GUY", "Guyana"},
+	{"HK", "HKG", "Hong Kong"},
+	{"HM", "HMD", "Heard Island and McDonald Islands"},
+	{"HN", "HND", "Honduras"},
+	{"HR", "HRV", "Croatia"},
+	{"HT", "HTI", "Haiti"},
+	{"HU", "HUN", "Hungary"},
+	{"ID", "IDN", "Indonesia"},
+	{"IE", "IRL", "Ireland"},
+	{"IL", "ISR", "Israel"},
+	{"IM", "IMN", "Isle of Man"},
+	{"IN", "IND", "India"},
+	{"IO", "IOT", "British Indian Ocean Territory"},
+	{"IQ", "IRQ", "Iraq"},
+	{"IR", "IRN", "Iran"},
+	{"IS", "ISL", "Iceland"},
+	{"IT", "ITA", "Italy"},
+	{"JE", "JEY", "Jersey"},
+	{"JM", "JAM", "Jamaica"},
+	{"JO", "JOR", "Jordan"},
+	{"JP", "JPN", "Japan"},
+	{"KE", "KEN", "Kenya"},
+	{"KG", "KGZ", "Kyrgyzstan"},
+	{"KH", "KHM", "Cambodia"},
+	{"KI", "KIR", "Kiribati"},
+	{"KM", "COM", "Comoros"},
+	{"KN", "KNA", "Saint Kitts and Nevis"},
+	{"KP", "PRK", "Korea, Democratic People's Republic of"},
+	{"KR", "KOR", "Korea, Republic of"},
+	{"KW", "KWT", "Kuwait"},
+	{"KY", "CYM", "Cayman Islands"},
+	{"KZ", "KAZ", "Kazakhstan"},
+	{"LA", "LAO", "Lao People's Democratic Republic"},
+	{"LB", "LBN", "Lebanon"},
+	{"LC", "LCA", "Saint Lucia"},
+	{"LI", "LIE", "Liechtenstein"},
+	{"LK", "LKA", "Sri Lanka"},
+	{"LR", "LBR", "Liberia"},
+	{"LS", "LSO", "Lesotho"},
+	{"LT", "LTU", "Lithuania"},
+	{"LU", "LUX", "Luxembourg"},
+	{"LV", "LVA", "Latvia"},
+	{"LY", "LBY", "Libya"},
+	{"MA", "MAR", "Morocco"},
+	{"MC", "MCO", "Monaco"},
+	{"MD", "MDA", "Moldova"},
+	{"ME", "MNE", "Montenegro"},
+	{"MF", "MAF", "Saint Martin (French part)"},
+	{"MG", "MDG", "Madagascar"},
+	{"MH", "MHL", "Marshall Islands"},
+	{"MK", "MKD", "North Macedonia"},
+	{"ML", "MLI", "Mali"},
+	{"MM", "MMR", "Myanmar"},
+	{"MN", "MNG", "Mongolia"},
+	{"MO", "MAC", "Macao"},
+	{"MP", "MNP", "Northern Mariana Islands"},
+	{"MQ", "MTQ", "Martinique"},
+	{"MR", "MRT", "Mauritania"},
+	{"MS", "MSR", "Montserrat"},
+	{"MT", "MLT", "Malta"},
+	{"MU", "MUS", "Mauritius"},
+	{"MV", "MDV", "Maldives"},
+	{"MW", "MWI", "Malawi"},
+	{"MX", "MEX", "Mexico"},
+	{"MY", "MYS", "Malaysia"},
+	{"MZ", "MOZ", "Mozambique"},
+	{"NA", "NAM", "Namibia"},
+	{"NC", "NCL", "New Caledonia"},
+	{"NE", "NER", "Niger"},
+	{"NF", "NFK", "Norfolk Island"},
+	{"NG", "NGA", "Nigeria"},
+	{"NI", "NIC", "Nicaragua"},
+	{"NL", "NLD", "Netherlands"},
+	{"NO", "NOR", "Norway"},
+	{"NP", "NPL", "Nepal"},
+	{"NR", "NRU", "Nauru"},
+	{"NU", "NIU", "Niue"},
+	{"NZ", "NZL", "New Zealand"},
+	{"OM", "OMN", "Oman"},
+	{"PA", "PAN", "Panama"},
+	{"PE", "PER", "Peru"},
+	{"PF", "PYF", "French Polynesia"},
+	{"PG", "PNG", "Papua New Guinea"},
+	{"PH", "PHL", "Philippines"},
+	{"PK", "PAK", "Pakistan"},
+	{"PL", "POL", "Poland"},
+	{"PM", "SPM", "Saint Pierre and Miquelon"},
+	{"PN", "PCN", "Pitcairn"},
+	{"PR", "PRI", "Puerto Rico"},
+	{"PS", "PSE", "Palestine, State of"},
+	{"PT", "PRT", "Portugal"},
+	{"PW", "PLW", "Palau"},
+	{"PY", "PRY", "Paraguay"},
+	{"QA", "QAT", "Qatar"},
+	{"RE", "REU", "Reunion"},
+	{"RO", "ROU", "Romania"},
+	{"RS", "SRB", "Serbia"},
+	{"RU", "RUS", "Russian Federation"},
+	{"RW", "RWA", "Rwanda"},
+	{"SA", "SAU", "Saudi Arabia"},
+	{"SB", "SLB", "Solomon Islands"},
+	{"SC", "SYC", "Seychelles"},
+	{"SD", "SDN", "Sudan"},
+	{"SE", "SWE", "Sweden"},
+	{"SG", "SGP", "Singapore"},
+	{"SH", "SHN", "Saint Helena, Ascension and Tristan da Cunha"},
+	{"SI", "SVN", "Slovenia"},
+	{"SJ", "SJM", "Svalbard and Jan Mayen"},
+	{"SK", "SVK", "Slovakia"},
+	{"SL", "SLE", "Sierra Leone"},
+	{"SM", "SMR", "San Marino"},
+	{"SN", "SEN", "Senegal"},
+	{"SO", "SOM", "Somalia"},
+	{"SR", "SUR", "Suriname"},
+	{"SS", "SSD", "South Sudan"},
+	{"ST", "STP", "Sao Tome and Principe"},
+	{"SV", "SLV", "El Salvador"},
+	{"SX", "SXM", "Sint Maarten (Dutch part)"},
+	{"SY", "SYR", "Syrian Arab Republic"},
+	{"SZ", "SWZ", "Eswatini"},
+	{"TC", "TCA", "Turks and Caicos Islands"},
+	{"TD", "TCD", "Chad"},
+	{"TF", "ATF", "French Southern Territories"},
+	{"TG", "TGO", "Togo"},
+	{"TH", "THA", "Thailand"},
+	{"TJ", "TJK", "Tajikistan"},
+	{"TK", "TKL", "Tokelau"},
+	{"TL", "TLS", "Timor-Leste"},
+	{"TM", "TKM", "Turkmenistan"},
+	{"TN", "TUN", "Tunisia"},
+	{"TO", "TON", "Tonga"},
+	{"TR", "TUR", "Turkiye"},
+	{"TT", "TTO", "Trinidad and Tobago"},
+	{"TV", "TUV", "Tuvalu"},
+	{"TW", "TWN", "Taiwan"},
+	{"TZ", "TZA", "Tanzania"},
+	{"UA", "UKR", "Ukraine"},
+	{"UG", "UGA", "Uganda"},
+	{"UM", "UMI", "United States Minor Outlying Islands"},
+	{"US", "USA", "United States"},
+	{"UY", "URY", "Uruguay"},
+	{"UZ", "UZB", "Uzbekistan"},
+	{"VA", "VAT", "Holy See"},
+	{"VC", "VCT", "Saint Vincent and the Grenadines"},
+	{"VE", "VEN", "Venezuela"},
+	{"VG", "VGB", "Virgin Islands (British)"},
+	{"VI", "VIR", "Virgin Islands (U.S.)"},
+	{"VN", "VNM", "Viet Nam"},
+	{"VU", "VUT", "Vanuatu"},
+	{"WF", "WLF", "Wallis and Futuna"},
+	{"WS", "WSM", "Samoa"},
+	{"YE", "YEM", "Yemen"},
+	{"YT", "MYT", "Mayotte"},
+	{"ZA", "ZAF", "South Africa"},
+	{"ZM", "ZMB", "Zambia"},
+	{"ZW", "ZWE", "Zimbabwe"},
+}
+
+// countryAliases are common names that differ from the ISO short name, including the constituent countries of the UK
+var countryAliases = map[string]Country{
+	"BRITAIN":                  CountryGB,
+	"CZECH REPUBLIC":           "CZ",
+	"ENGLAND":                  CountryGB,
+	"GREAT BRITAIN":            CountryGB,
+	"HOLLAND":                  "NL",
+	"IVORY COAST":              "CI",
+	"NORTH KOREA":              "KP",
+	"NORTHERN IRELAND":         CountryGB,
+	"REPUBLIC OF IRELAND":      "IE",
+	"RUSSIA":                   "RU",
+	"SCOTLAND":                 CountryGB,
+	"SOUTH KOREA":              "KR",
+	"THE NETHERLANDS":          "NL",
+	"TURKEY":                   "TR",
+	"UK":                       CountryGB,
+	"UNITED STATES OF AMERICA": CountryUS,
+	"VATICAN CITY":             "VA",
+	"VIETNAM":                  "VN",
+	"WALES":                    CountryGB,
+}
+
+// euMembers are the member states of the European Union
+var euMembers = map[Country]struct{}{
+	"AT": {}, "BE": {}, "BG": {}, "CY": {}, "CZ": {}, "DE": {}, "DK": {}, "EE": {}, "ES": {},
+	"FI": {}, "FR": {}, "GR": {}, "HR": {}, "HU": {}, "IE": {}, "IT": {}, "LT": {}, "LU": {},
+	"LV": {}, "MT": {}, "NL": {}, "PL": {}, "PT": {}, "RO": {}, "SE": {}, "SI": {}, "SK": {},
+}
+
+var countryLookup = func() map[string]*countryInfo {
+	lookup := make(map[string]*countryInfo, len(countries)*3+len(countryAliases))
+	for i := range countries {
+		info := &countries[i]
+		lookup[string(info.alpha2)] = info
+		lookup[info.alpha3] = info
+		lookup[countryKey(info.name)] = info
+	}
+
+	for alias, code := range countryAliases {
+		lookup[alias] = lookup[string(code)]
+	}
+
+	return lookup
+}()
+
+// ParseCountry recognises an ISO 3166-1 alpha-2 or alpha-3 code or an English country name in any case, returning
+// the alpha-2 code and whether it was recognised
+func ParseCountry(s string) (Country, bool) {
+	info, ok := countryLookup[countryKey(s)]
+	if !ok {
+		return "", false
+	}
+
+	return info.alpha2, true
+}
+
+// Alpha3 returns the ISO 3166-1 alpha-3 code, or "" for an unknown country
+func (c Country) Alpha3() string {
+	if info, ok := countryLookup[string(c)]; ok {
+		return info.alpha3
+	}
+
+	return ""
+}
+
+// IsEU reports whether the country is a member state of the European Union
+func (c Country) IsEU() bool {
+	_, ok := euMembers[c]
+	return ok
+}
+
+// IsValid reports whether c is an assigned ISO 3166-1 alpha-2 code
+func (c Country) IsValid() bool {
+	info, ok := countryLookup[string(c)]
+	return ok && info.alpha2 == c
+}
+
+// Name returns the English short name, or the code itself for an unknown country
+func (c Country) Name() string {
+	if info, ok := countryLookup[string(c)]; ok {
+		return info.name
+	}
+
+	return string(c)
+}
+
+// countryKey uppercases s and drops punctuation so "U.S.A." and "usa" match
+func countryKey(s string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToUpper(r)
+		case unicode.IsSpace(r) || r == '-':
+			return ' '
+		}
+		return -1
+	}, s)
+
+	return strings.Join(strings.Fields(cleaned), " ")
+}
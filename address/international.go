@@ -0,0 +1,331 @@
+package address
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// InvalidErrorType is set on the *util.APIError returned when an Address is missing fields its country requires or
+// has a malformed postal code
+const InvalidErrorType = "address_invalid"
+
+// Address is a postal address in any country. It bridges ValidateReq and letter.RecipientDetails, which name the same
+// fields differently, and knows how each supported country expects an address to be completed and printed.
+type Address struct {
+	City       string  `json:"city"`
+	Company    string  `json:"company,omitempty"`
+	Country    Country `json:"country"`
+	Line1      string  `json:"line1"`
+	Line2      string  `json:"line2,omitempty"`
+	PostalCode string  `json:"postalCode"`
+	Region     string  `json:"region,omitempty"` // state, province or county
+}
+
+// CountryRules describes which fields a country's addresses need and how its postal codes look
+type CountryRules struct {
+	PostalCode         *regexp.Regexp // matched against the postal code with spaces removed
+	PostalCodeLabel    string
+	RegionLabel        string
+	RequiresPostalCode bool
+	RequiresRegion     bool
+	format             func(a *Address) []string
+	formatPostalCode   func(compact string) string
+}
+
+var countryRules = map[Country]CountryRules{
+	CountryCA: {
+		PostalCode:         regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY][0-9][ABCEGHJ-NPRSTV-Z][0-9][ABCEGHJ-NPRSTV-Z][0-9]$`),
+		PostalCodeLabel:    "postal code",
+		RegionLabel:        "province",
+		RequiresPostalCode: true,
+		RequiresRegion:     true,
+		// Canada Post puts two spaces between the province and the postal code
+		format:           func(a *Address) []string { return []string{a.City + " " + a.Region + "  " + a.PostalCode} },
+		formatPostalCode: splitAt(3),
+	},
+	CountryGB: {
+		PostalCode:         regexp.MustCompile(`^(GIR0AA|[A-Z]{1,2}[0-9][A-Z0-9]?[0-9][A-Z]{2})$`),
+		PostalCodeLabel:    "postcode",
+		RegionLabel:        "county",
+		RequiresPostalCode: true,
+		// Royal Mail wants the post town and postcode on their own lines, and no longer needs the county
+		format:           func(a *Address) []string { return []string{a.City, a.PostalCode} },
+		formatPostalCode: splitBeforeLast(3),
+	},
+	CountryUS: {
+		PostalCode:         regexp.MustCompile(`^[0-9]{5}(-?[0-9]{4})?$`),
+		PostalCodeLabel:    "ZIP code",
+		RegionLabel:        "state",
+		RequiresPostalCode: true,
+		RequiresRegion:     true,
+		format:             func(a *Address) []string { return []string{a.City + " " + a.Region + " " + a.PostalCode} },
+		formatPostalCode:   usZipcode,
+	},
+}
+
+// euPostalCodes are the postal code patterns of the EU member states, matched with spaces removed
+var euPostalCodes = map[Country]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^[0-9]{4}$`),
+	"BE": regexp.MustCompile(`^[0-9]{4}$`),
+	"BG": regexp.MustCompile(`^[0-9]{4}$`),
+	"CY": regexp.MustCompile(`^[0-9]{4}$`),
+	"CZ": regexp.MustCompile(`^[0-9]{5}$`),
+	"DE": regexp.MustCompile(`^[0-9]{5}$`),
+	"DK": regexp.MustCompile(`^[0-9]{4}$`),
+	"EE": regexp.MustCompile(`^[0-9]{5}$`),
+	"ES": regexp.MustCompile(`^[0-9]{5}$`),
+	"FI": regexp.MustCompile(`^[0-9]{5}$`),
+	"FR": regexp.MustCompile(`^[0-9]{5}$`),
+	"GR": regexp.MustCompile(`^[0-9]{5}$`),
+	"HR": regexp.MustCompile(`^[0-9]{5}$`),
+	"HU": regexp.MustCompile(`^[0-9]{4}$`),
+	"IE": regexp.MustCompile(`^[AC-FHKNPRTV-Y][0-9]{2}[0-9AC-FHKNPRTV-Y]{4}$`),
+	"IT": regexp.MustCompile(`^[0-9]{5}$`),
+	"LT": regexp.MustCompile(`^(LT-?)?[0-9]{5}$`),
+	"LU": regexp.MustCompile(`^(L-?)?[0-9]{4}$`),
+	"LV": regexp.MustCompile(`^(LV-?)?[0-9]{4}$`),
+	"MT": regexp.MustCompile(`^[A-Z]{3}[0-9]{4}$`),
+	"NL": regexp.MustCompile(`^[1-9][0-9]{3}[A-Z]{2}$`),
+	"PL": regexp.MustCompile(`^[0-9]{2}-?[0-9]{3}$`),
+	"PT": regexp.MustCompile(`^[0-9]{4}-?[0-9]{3}$`),
+	"RO": regexp.MustCompile(`^[0-9]{6}$`),
+	"SE": regexp.MustCompile(`^[0-9]{5}$`),
+	"SI": regexp.MustCompile(`^[0-9]{4}$`),
+	"SK": regexp.MustCompile(`^[0-9]{5}$`),
+}
+
+// euPostalCodeFormats re-spaces postal codes that are written with a space
+var euPostalCodeFormats = map[Country]func(string) string{
+	"CZ": splitAt(3),
+	"GR": splitAt(3),
+	"IE": splitAt(3),
+	"MT": splitAt(3),
+	"NL": splitAt(4),
+	"SE": splitAt(3),
+	"SK": splitAt(3),
+}
+
+// RulesFor returns the addressing rules for country. EU member states share a "postal code city" layout with their
+// own postal code pattern; Ireland prints its Eircode on its own line and doesn't require one. Every other country
+// only requires the first line and the city and is printed "city region postal code".
+func RulesFor(country Country) CountryRules {
+	if rules, ok := countryRules[country]; ok {
+		return rules
+	}
+
+	rules := CountryRules{
+		PostalCodeLabel: "postal code",
+		RegionLabel:     "region",
+		format: func(a *Address) []string {
+			return []string{joinNonEmpty(" ", a.City, a.Region, a.PostalCode)}
+		},
+	}
+
+	if !country.IsEU() {
+		return rules
+	}
+
+	rules.PostalCode = euPostalCodes[country]
+	rules.RequiresPostalCode = true
+	rules.formatPostalCode = euPostalCodeFormats[country]
+	rules.format = func(a *Address) []string {
+		return []string{joinNonEmpty(" ", a.PostalCode, a.City)}
+	}
+
+	if country == "IE" {
+		rules.PostalCodeLabel = "Eircode"
+		rules.RegionLabel = "county"
+		rules.RequiresPostalCode = false
+		rules.format = func(a *Address) []string {
+			return []string{a.City, a.Region, a.PostalCode}
+		}
+	}
+
+	return rules
+}
+
+// FromRecipient builds an Address from a letter recipient, parsing its free text country. A blank country is taken
+// to be defaultCountry.
+func FromRecipient(recipient letter.RecipientDetails, defaultCountry Country) (Address, *util.APIError) {
+	return newAddress(Address{
+		City:       recipient.Town,
+		Line1:      recipient.Address1,
+		Line2:      recipient.Address2,
+		PostalCode: recipient.Zipcode,
+		Region:     recipient.State,
+	}, recipient.Country, defaultCountry)
+}
+
+// FromValidateReq builds an Address from a validation request, parsing its free text country. A blank country is
+// taken to be defaultCountry.
+func FromValidateReq(req ValidateReq, defaultCountry Country) (Address, *util.APIError) {
+	return newAddress(Address{
+		City:       req.City,
+		Company:    req.Company,
+		Line1:      req.Address1,
+		Line2:      req.Address2,
+		PostalCode: req.Zipcode,
+		Region:     req.State,
+	}, req.Country, defaultCountry)
+}
+
+func newAddress(a Address, country string, defaultCountry Country) (Address, *util.APIError) {
+	if strings.TrimSpace(country) == "" {
+		a.Country = defaultCountry
+		return a, nil
+	}
+
+	parsed, ok := ParseCountry(country)
+	if !ok {
+		return a, util.BuildTypedError(400, InvalidErrorType, fmt.Sprintf("unrecognised country [%s]", country))
+	}
+
+	a.Country = parsed
+	return a, nil
+}
+
+// Normalize trims every field and formats the postal code the way the country writes it, for example "sw1a1aa"
+// becomes "SW1A 1AA" and "k1a0b1" becomes "K1A 0B1". Postal codes that don't match the country's pattern are only
+// trimmed and uppercased.
+func (a *Address) Normalize() Address {
+	normalized := *a
+	normalized.normalize()
+	return normalized
+}
+
+func (a *Address) normalize() {
+	a.City = strings.TrimSpace(a.City)
+	a.Company = strings.TrimSpace(a.Company)
+	a.Line1 = strings.TrimSpace(a.Line1)
+	a.Line2 = strings.TrimSpace(a.Line2)
+	a.Region = strings.TrimSpace(a.Region)
+	a.PostalCode = strings.ToUpper(strings.TrimSpace(a.PostalCode))
+
+	rules := RulesFor(a.Country)
+	compact := strings.ReplaceAll(a.PostalCode, " ", "")
+	if rules.PostalCode != nil && rules.PostalCode.MatchString(compact) {
+		a.PostalCode = compact
+		if rules.formatPostalCode != nil {
+			a.PostalCode = rules.formatPostalCode(compact)
+		}
+	}
+}
+
+// Validate checks the address against its country's rules, returning a 400 *util.APIError of InvalidErrorType
+// naming every problem
+func (a *Address) Validate() *util.APIError {
+	if !a.Country.IsValid() {
+		return util.BuildTypedError(400, InvalidErrorType, fmt.Sprintf("invalid country code [%s]", a.Country))
+	}
+
+	rules := RulesFor(a.Country)
+
+	var problems []string
+	if strings.TrimSpace(a.Line1) == "" {
+		problems = append(problems, "address line 1 is required")
+	}
+
+	if strings.TrimSpace(a.City) == "" {
+		problems = append(problems, "city is required")
+	}
+
+	if rules.RequiresRegion && strings.TrimSpace(a.Region) == "" {
+		problems = append(problems, rules.RegionLabel+" is required")
+	}
+
+	compact := strings.ToUpper(strings.ReplaceAll(a.PostalCode, " ", ""))
+	switch {
+	case compact == "" && rules.RequiresPostalCode:
+		problems = append(problems, rules.PostalCodeLabel+" is required")
+	case compact != "" && rules.PostalCode != nil && !rules.PostalCode.MatchString(compact):
+		problems = append(problems, fmt.Sprintf("%s [%s] is not valid for %s", rules.PostalCodeLabel, a.PostalCode, a.Country.Name()))
+	}
+
+	if len(problems) > 0 {
+		return util.BuildTypedError(400, InvalidErrorType, fmt.Sprintf("invalid %s address: %s", a.Country.Name(), strings.Join(problems, ", ")))
+	}
+
+	return nil
+}
+
+// Lines formats the address for printing in the order its country expects. The country name is added as the last
+// line, in upper case, when the letter is posted from another country.
+func (a *Address) Lines(from Country) []string {
+	lines := []string{a.Company, a.Line1, a.Line2}
+	lines = append(lines, RulesFor(a.Country).format(a)...)
+
+	if a.Country != from {
+		lines = append(lines, strings.ToUpper(a.Country.Name()))
+	}
+
+	printed := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			printed = append(printed, line)
+		}
+	}
+
+	return printed
+}
+
+// RecipientDetails applies the address to recipient, keeping the recipient's name and title
+func (a *Address) RecipientDetails(recipient letter.RecipientDetails) letter.RecipientDetails {
+	recipient.Address1 = a.Line1
+	recipient.Address2 = a.Line2
+	recipient.Country = string(a.Country)
+	recipient.State = a.Region
+	recipient.Town = a.City
+	recipient.Zipcode = a.PostalCode
+	return recipient
+}
+
+// ValidateReq converts the address into a validation request
+func (a *Address) ValidateReq() ValidateReq {
+	return ValidateReq{
+		Address1: a.Line1,
+		Address2: a.Line2,
+		City:     a.City,
+		Company:  a.Company,
+		Country:  string(a.Country),
+		State:    a.Region,
+		Zipcode:  a.PostalCode,
+	}
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+
+	return strings.Join(nonEmpty, sep)
+}
+
+// splitAt inserts a space after the first n characters
+func splitAt(n int) func(string) string {
+	return func(compact string) string {
+		return compact[:n] + " " + compact[n:]
+	}
+}
+
+// splitBeforeLast inserts a space before the last n characters
+func splitBeforeLast(n int) func(string) string {
+	return func(compact string) string {
+		return compact[:len(compact)-n] + " " + compact[len(compact)-n:]
+	}
+}
+
+func usZipcode(compact string) string {
+	digits := strings.ReplaceAll(compact, "-", "")
+	if len(digits) == 9 {
+		return digits[:5] + "-" + digits[5:]
+	}
+
+	return digits
+}
@@ -0,0 +1,245 @@
+package address
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestParseCountry(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Country
+		ok       bool
+	}{
+		{input: "US", expected: CountryUS, ok: true},
+		{input: "usa", expected: CountryUS, ok: true},
+		{input: "U.S.A.", expected: CountryUS, ok: true},
+		{input: "United States of America", expected: CountryUS, ok: true},
+		{input: "uk", expected: CountryGB, ok: true},
+		{input: "Scotland", expected: CountryGB, ok: true},
+		{input: "GBR", expected: CountryGB, ok: true},
+		{input: "canada", expected: CountryCA, ok: true},
+		{input: "Deutschland", ok: false},
+		{input: "Germany", expected: "DE", ok: true},
+		{input: "the Netherlands", expected: "NL", ok: true},
+		{input: "Cote d'Ivoire", expected: "CI", ok: true},
+		{input: "Guinea-Bissau", expected: "GW", ok: true},
+		{input: "", ok: false},
+		{input: "XX", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			country, ok := ParseCountry(tt.input)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, country)
+		})
+	}
+}
+
+func TestCountry(t *testing.T) {
+	assert.Equal(t, "DEU", Country("DE").Alpha3())
+	assert.Equal(t, "Germany", Country("DE").Name())
+	assert.True(t, Country("DE").IsEU())
+	assert.False(t, CountryGB.IsEU())
+	assert.True(t, CountryGB.IsValid())
+	assert.False(t, Country("GBR").IsValid())
+	assert.False(t, Country("UK").IsValid())
+	assert.Equal(t, "", Country("XX").Alpha3())
+}
+
+func TestAddress_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  Address
+		problems []string
+	}{
+		{
+			name:    "complete US address",
+			address: Address{City: "Beverly Hills", Country: CountryUS, Line1: "9355 Burton Way", PostalCode: "90210-3669", Region: "CA"},
+		},
+		{
+			name:     "US address without a state or ZIP code",
+			address:  Address{City: "Beverly Hills", Country: CountryUS, Line1: "9355 Burton Way"},
+			problems: []string{"state is required", "ZIP code is required"},
+		},
+		{
+			name:    "UK address without a county",
+			address: Address{City: "London", Country: CountryGB, Line1: "10 Downing Street", PostalCode: "SW1A 2AA"},
+		},
+		{
+			name:     "UK address with a malformed postcode",
+			address:  Address{City: "London", Country: CountryGB, Line1: "10 Downing Street", PostalCode: "12345"},
+			problems: []string{"postcode [12345] is not valid for United Kingdom"},
+		},
+		{
+			name:    "Canadian address",
+			address: Address{City: "Ottawa", Country: CountryCA, Line1: "24 Sussex Drive", PostalCode: "k1m 1m4", Region: "ON"},
+		},
+		{
+			name:     "Canadian address without a province",
+			address:  Address{City: "Ottawa", Country: CountryCA, Line1: "24 Sussex Drive", PostalCode: "K1M 1M4"},
+			problems: []string{"province is required"},
+		},
+		{
+			name:     "German address with a US ZIP code",
+			address:  Address{City: "Berlin", Country: "DE", Line1: "Platz der Republik 1", PostalCode: "90210-3669"},
+			problems: []string{"postal code [90210-3669] is not valid for Germany"},
+		},
+		{
+			name:     "French address without a postal code",
+			address:  Address{City: "Paris", Country: "FR", Line1: "55 Rue du Faubourg Saint-Honore"},
+			problems: []string{"postal code is required"},
+		},
+		{
+			name:    "Irish address without an Eircode",
+			address: Address{City: "Dublin", Country: "IE", Line1: "Aras an Uachtarain", Region: "Co. Dublin"},
+		},
+		{
+			name:    "Japanese address only needs a line and a city",
+			address: Address{City: "Tokyo", Country: "JP", Line1: "1-1 Chiyoda"},
+		},
+		{
+			name:     "missing first line and city",
+			address:  Address{Country: "JP"},
+			problems: []string{"address line 1 is required", "city is required"},
+		},
+		{
+			name:     "unknown country",
+			address:  Address{City: "Atlantis", Country: "XX", Line1: "1 Ocean Floor"},
+			problems: []string{"invalid country code [XX]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validErr := tt.address.Validate()
+			if len(tt.problems) == 0 {
+				assert.True(t, reflect.ValueOf(validErr).IsNil())
+				return
+			}
+
+			assert.False(t, reflect.ValueOf(validErr).IsNil())
+			assert.Equal(t, 400, validErr.Code)
+			assert.Equal(t, InvalidErrorType, validErr.Type)
+			for _, problem := range tt.problems {
+				assert.True(t, strings.Contains(validErr.ErrorMessage, problem), validErr.ErrorMessage)
+			}
+		})
+	}
+}
+
+func TestAddress_Normalize(t *testing.T) {
+	tests := []struct {
+		country    Country
+		postalCode string
+		expected   string
+	}{
+		{country: CountryUS, postalCode: "902103669", expected: "90210-3669"},
+		{country: CountryUS, postalCode: " 90210 ", expected: "90210"},
+		{country: CountryGB, postalCode: "sw1a2aa", expected: "SW1A 2AA"},
+		{country: CountryCA, postalCode: "k1m1m4", expected: "K1M 1M4"},
+		{country: "NL", postalCode: "1012jz", expected: "1012 JZ"},
+		{country: "SE", postalCode: "11455", expected: "114 55"},
+		{country: "DE", postalCode: "10 557", expected: "10557"},
+		{country: "IE", postalCode: "d08 e9p6", expected: "D08 E9P6"},
+		{country: "DE", postalCode: "not a code", expected: "NOT A CODE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.country)+" "+tt.postalCode, func(t *testing.T) {
+			a := Address{Country: tt.country, PostalCode: tt.postalCode}
+			assert.Equal(t, tt.expected, a.Normalize().PostalCode)
+			assert.Equal(t, tt.postalCode, a.PostalCode)
+		})
+	}
+}
+
+func TestAddress_Lines(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  Address
+		from     Country
+		expected []string
+	}{
+		{
+			name:     "domestic US",
+			address:  Address{City: "BEVERLY HILLS", Country: CountryUS, Line1: "9355 BURTON WAY", PostalCode: "90210-3669", Region: "CA"},
+			from:     CountryUS,
+			expected: []string{"9355 BURTON WAY", "BEVERLY HILLS CA 90210-3669"},
+		},
+		{
+			name:     "UK from the US",
+			address:  Address{City: "LONDON", Company: "HM Government", Country: CountryGB, Line1: "10 Downing Street", PostalCode: "SW1A 2AA", Region: "Greater London"},
+			from:     CountryUS,
+			expected: []string{"HM Government", "10 Downing Street", "LONDON", "SW1A 2AA", "UNITED KINGDOM"},
+		},
+		{
+			name:     "Canada from the US",
+			address:  Address{City: "OTTAWA", Country: CountryCA, Line1: "24 SUSSEX DR", PostalCode: "K1M 1M4", Region: "ON"},
+			from:     CountryUS,
+			expected: []string{"24 SUSSEX DR", "OTTAWA ON  K1M 1M4", "CANADA"},
+		},
+		{
+			name:     "Germany from the UK",
+			address:  Address{City: "Berlin", Country: "DE", Line1: "Platz der Republik 1", PostalCode: "11011"},
+			from:     CountryGB,
+			expected: []string{"Platz der Republik 1", "11011 Berlin", "GERMANY"},
+		},
+		{
+			name:     "Ireland",
+			address:  Address{City: "Dublin 8", Country: "IE", Line1: "Phoenix Park", PostalCode: "D08 E1W3", Region: "Co. Dublin"},
+			from:     "IE",
+			expected: []string{"Phoenix Park", "Dublin 8", "Co. Dublin", "D08 E1W3"},
+		},
+		{
+			name:     "elsewhere",
+			address:  Address{City: "Tokyo", Country: "JP", Line1: "1-1 Chiyoda", Line2: "Chiyoda-ku", PostalCode: "100-8111"},
+			from:     CountryUS,
+			expected: []string{"1-1 Chiyoda", "Chiyoda-ku", "Tokyo 100-8111", "JAPAN"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, reflect.DeepEqual(tt.expected, tt.address.Lines(tt.from)), tt.address.Lines(tt.from))
+		})
+	}
+}
+
+func TestAddress_Conversions(t *testing.T) {
+	recipient := letter.RecipientDetails{
+		Address1:  "Platz der Republik 1",
+		Country:   "Germany",
+		Firstname: "Judge",
+		Lastname:  "Judy",
+		Title:     "Mrs.",
+		Town:      "Berlin",
+		Zipcode:   "11011",
+	}
+
+	fromRecipient, parseErr := FromRecipient(recipient, CountryUS)
+	assert.True(t, reflect.ValueOf(parseErr).IsNil())
+	assert.Equal(t, Address{City: "Berlin", Country: "DE", Line1: "Platz der Republik 1", PostalCode: "11011"}, fromRecipient)
+
+	converted := fromRecipient.RecipientDetails(recipient)
+	assert.Equal(t, "DE", converted.Country)
+	assert.Equal(t, "Judge", converted.Firstname)
+	assert.Equal(t, "Mrs.", converted.Title)
+
+	req := ValidateReq{Address1: "9355 Burton Way", City: "Beverly Hills", Company: "Courthouse", State: "CA", Zipcode: "90210"}
+	fromReq, parseErr := FromValidateReq(req, CountryUS)
+	assert.True(t, reflect.ValueOf(parseErr).IsNil())
+	assert.Equal(t, CountryUS, fromReq.Country)
+	assert.Equal(t, "Courthouse", fromReq.Company)
+
+	req.Country = "US"
+	assert.Equal(t, req, fromReq.ValidateReq())
+
+	_, parseErr = FromRecipient(letter.RecipientDetails{Country: "Atlantis"}, CountryUS)
+	assert.Equal(t, InvalidErrorType, parseErr.Type)
+}
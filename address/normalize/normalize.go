@@ -0,0 +1,320 @@
+// Package normalize cleans up addresses locally before they are validated or sent, following the USPS Publication 28
+// conventions for US addresses and Royal Mail formatting for UK postcodes. Every function is pure, so normalizing the
+// same address twice gives the same result, which makes normalized addresses safe to cache and deduplicate on.
+package normalize
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/copilotiq/stannp-client-golang/address"
+	"github.com/copilotiq/stannp-client-golang/letter"
+)
+
+const (
+	CountryGB = "GB"
+	CountryUS = "US"
+)
+
+var ukPostcodeRegex = regexp.MustCompile(`^([A-Z]{1,2}[0-9][A-Z0-9]?)([0-9][A-Z]{2})$`)
+
+// ValidateReq returns req with every field normalized for its country. A blank country is treated as the US.
+func ValidateReq(req address.ValidateReq) address.ValidateReq {
+	country := Country(req.Country)
+
+	return address.ValidateReq{
+		Address1: Line(country, req.Address1),
+		Address2: Line(country, req.Address2),
+		City:     Text(req.City),
+		Company:  Text(req.Company),
+		Country:  country,
+		State:    region(country, req.State),
+		Zipcode:  postalCode(country, req.Zipcode),
+	}
+}
+
+// RecipientDetails returns recipient with its address normalized like ValidateReq. Names and titles only have their
+// whitespace tidied since they are merged into letter templates as written.
+func RecipientDetails(recipient letter.RecipientDetails) letter.RecipientDetails {
+	country := Country(recipient.Country)
+
+	return letter.RecipientDetails{
+		Address1:  Line(country, recipient.Address1),
+		Address2:  Line(country, recipient.Address2),
+		Country:   country,
+		Firstname: Whitespace(recipient.Firstname),
+		Lastname:  Whitespace(recipient.Lastname),
+		State:     region(country, recipient.State),
+		Title:     Whitespace(recipient.Title),
+		Town:      Text(recipient.Town),
+		Zipcode:   postalCode(country, recipient.Zipcode),
+	}
+}
+
+// Key returns a stable string identifying the normalized address, for use as a cache or deduplication key. A blank
+// country gives the same key as the US.
+func Key(req address.ValidateReq) string {
+	normalized := ValidateReq(req)
+	if normalized.Country == "" {
+		normalized.Country = CountryUS
+	}
+
+	return strings.Join([]string{
+		normalized.Company,
+		normalized.Address1,
+		normalized.Address2,
+		normalized.City,
+		normalized.State,
+		normalized.Zipcode,
+		normalized.Country,
+	}, "|")
+}
+
+// Whitespace trims s and collapses every run of whitespace inside it to a single space
+func Whitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Text uppercases s and removes punctuation other than the hyphens, slashes, ampersands and number signs Publication
+// 28 allows
+func Text(s string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case r == '-' || r == '/' || r == '&' || r == '#':
+			return r
+		case r == ',' || r == ';' || r == ':' || unicode.IsSpace(r):
+			return ' '
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, s)
+
+	return Whitespace(cleaned)
+}
+
+// Line normalizes a street address line for country, given as an ISO 3166-1 alpha-2 code with a blank country treated
+// as the US. A US line is uppercased, has its directionals, street suffix and secondary unit designator abbreviated,
+// and has unit numbers written without a number sign when they follow a designator ("Apt. #4" becomes "APT 4"), with
+// post office boxes written as "PO BOX". Publication 28 only covers US addresses, so any other country's line is just
+// tidied like Text.
+func Line(country, s string) string {
+	if !isUS(country) {
+		return Text(s)
+	}
+
+	tokens := strings.Fields(Text(s))
+	tokens = poBox(tokens)
+	tokens = splitNumberSigns(tokens)
+
+	unitIndex := len(tokens)
+	for i := range tokens {
+		if isUnitDesignator(tokens, i) {
+			unitIndex = i
+			break
+		}
+	}
+
+	street := abbreviateStreet(tokens[:unitIndex])
+	unit := abbreviateUnit(tokens[unitIndex:])
+
+	return strings.Join(append(street, unit...), " ")
+}
+
+// State returns the two letter USPS code for a US state, district or territory name, or s uppercased if it isn't one
+func State(s string) string {
+	state := Text(s)
+	if _, ok := stateNames[state]; ok {
+		return state
+	}
+
+	if code, ok := stateCodes[state]; ok {
+		return code
+	}
+
+	return state
+}
+
+// Zipcode formats a US ZIP code as five digits or ZIP+4 ("123456789" becomes "12345-6789"). Anything that isn't a
+// five or nine digit code is returned tidied but otherwise unchanged.
+func Zipcode(s string) string {
+	zip := Text(s)
+
+	digits := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9':
+			return r
+		case r == '-' || r == ' ':
+			return -1
+		}
+		return 'x'
+	}, zip)
+
+	if strings.ContainsRune(digits, 'x') {
+		return zip
+	}
+
+	switch len(digits) {
+	case 5:
+		return digits
+	case 9:
+		return digits[:5] + "-" + digits[5:]
+	}
+
+	return zip
+}
+
+// Postcode formats a UK postcode in upper case with a single space before the inward code ("sw1a1aa" becomes
+// "SW1A 1AA"). Anything that doesn't look like a UK postcode is returned tidied but otherwise unchanged.
+func Postcode(s string) string {
+	postcode := Text(s)
+	compact := strings.ReplaceAll(postcode, " ", "")
+
+	if compact == "GIR0AA" {
+		return "GIR 0AA"
+	}
+
+	if matches := ukPostcodeRegex.FindStringSubmatch(compact); matches != nil {
+		return matches[1] + " " + matches[2]
+	}
+
+	return postcode
+}
+
+// Country returns the ISO 3166-1 alpha-2 code for common spellings of the US and the UK, or s uppercased otherwise
+func Country(s string) string {
+	country := Text(s)
+	if code, ok := countryAliases[country]; ok {
+		return code
+	}
+
+	return country
+}
+
+func isUS(country string) bool {
+	return country == "" || country == CountryUS
+}
+
+func region(country, state string) string {
+	if isUS(country) {
+		return State(state)
+	}
+
+	return Text(state)
+}
+
+func postalCode(country, zipcode string) string {
+	switch {
+	case isUS(country):
+		return Zipcode(zipcode)
+	case country == CountryGB:
+		return Postcode(zipcode)
+	}
+
+	return Text(zipcode)
+}
+
+// poBox rewrites the spellings of a post office box ("P O Box", "Post Office Box", "POB") as "PO BOX"
+func poBox(tokens []string) []string {
+	switch {
+	case len(tokens) >= 3 && tokens[0] == "P" && tokens[1] == "O" && tokens[2] == "BOX":
+		return append([]string{"PO", "BOX"}, tokens[3:]...)
+	case len(tokens) >= 3 && tokens[0] == "POST" && tokens[1] == "OFFICE" && tokens[2] == "BOX":
+		return append([]string{"PO", "BOX"}, tokens[3:]...)
+	case len(tokens) >= 1 && tokens[0] == "POB":
+		return append([]string{"PO", "BOX"}, tokens[1:]...)
+	}
+
+	return tokens
+}
+
+// splitNumberSigns separates a number sign from the identifier it's attached to, so "#4" becomes "#" and "4"
+func splitNumberSigns(tokens []string) []string {
+	split := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if len(token) > 1 && strings.HasPrefix(token, "#") {
+			split = append(split, "#", strings.TrimPrefix(token, "#"))
+			continue
+		}
+		split = append(split, token)
+	}
+
+	return split
+}
+
+// isUnitDesignator reports whether tokens[i] starts the secondary unit. A line that starts with a designator, like
+// "Suite 200" on its own, only counts when it's followed by something that looks like a unit identifier so that a street
+// such as "Office Park Dr" is left alone.
+func isUnitDesignator(tokens []string, i int) bool {
+	if _, ok := unitDesignators[tokens[i]]; !ok && tokens[i] != "#" {
+		return false
+	}
+
+	if i > 0 {
+		return true
+	}
+
+	if len(tokens) < 2 {
+		return false
+	}
+
+	next := tokens[1]
+	return next == "#" || len(next) == 1 || strings.ContainsAny(next, "0123456789")
+}
+
+// abbreviateStreet abbreviates the pre-directional, suffix and post-directional of a street. A word is only treated as
+// one of those when another word is left over as the street name, so "100 North Avenue" keeps NORTH and "100 Avenue
+// North" keeps AVENUE.
+func abbreviateStreet(tokens []string) []string {
+	street := append([]string{}, tokens...)
+	if len(street) > 0 && street[0] == "PO" {
+		return street
+	}
+
+	first := 0
+	if len(street) > 0 && strings.ContainsAny(street[0], "0123456789") {
+		first = 1
+	}
+
+	last := len(street) - 1
+	if last-first >= 1 {
+		if abbreviation, ok := directionals[street[last]]; ok {
+			street[last] = abbreviation
+			last--
+		}
+	}
+
+	if last-first >= 1 {
+		if abbreviation, ok := streetSuffixes[street[last]]; ok {
+			street[last] = abbreviation
+			last--
+		}
+	}
+
+	if last-first >= 1 {
+		if abbreviation, ok := directionals[street[first]]; ok {
+			street[first] = abbreviation
+		}
+	}
+
+	return street
+}
+
+// abbreviateUnit abbreviates the secondary unit designator at the start of tokens and drops a number sign that sits
+// between the designator and its identifier
+func abbreviateUnit(tokens []string) []string {
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	unit := append([]string{}, tokens...)
+	if abbreviation, ok := unitDesignators[unit[0]]; ok {
+		unit[0] = abbreviation
+		if len(unit) > 2 && unit[1] == "#" {
+			unit = append(unit[:1], unit[2:]...)
+		}
+	}
+
+	return unit
+}
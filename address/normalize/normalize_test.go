@@ -0,0 +1,240 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/copilotiq/stannp-client-golang/address"
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		country  string
+		line     string
+		expected string
+	}{
+		{name: "empty", line: "", expected: ""},
+		{name: "suffix", line: "9355 Burton Way", expected: "9355 BURTON WAY"},
+		{name: "spelled out suffix", line: "123 Main Street", expected: "123 MAIN ST"},
+		{name: "suffix variant", line: "1600 Pennsylvania Av.", expected: "1600 PENNSYLVANIA AVE"},
+		{name: "pre-directional", line: "123 north main street", expected: "123 N MAIN ST"},
+		{name: "post-directional", line: "500 Elm Boulevard Southwest", expected: "500 ELM BLVD SW"},
+		{name: "directional as street name", line: "100 North Avenue", expected: "100 NORTH AVE"},
+		{name: "suffix as street name", line: "100 Avenue North", expected: "100 AVENUE N"},
+		{name: "already abbreviated", line: "100 N Main St", expected: "100 N MAIN ST"},
+		{name: "only the last suffix", line: "12 Court Street", expected: "12 COURT ST"},
+		{name: "unit with number sign", line: "123 Main St., Apt. #4", expected: "123 MAIN ST APT 4"},
+		{name: "bare number sign", line: "123 Main St #4B", expected: "123 MAIN ST # 4B"},
+		{name: "spelled out unit", line: "1 Market Street Suite 200", expected: "1 MARKET ST STE 200"},
+		{name: "unit on its own", line: "Apartment 12", expected: "APT 12"},
+		{name: "number sign on its own", line: "#12", expected: "# 12"},
+		{name: "designator word as street name", line: "Office Park Drive", expected: "OFFICE PARK DR"},
+		{name: "whitespace and punctuation", line: "  221b   Baker  St.  ", expected: "221B BAKER ST"},
+		{name: "PO box", line: "P.O. Box 123", expected: "PO BOX 123"},
+		{name: "post office box", line: "Post Office Box 9", expected: "PO BOX 9"},
+		{name: "hyphenated house number", line: "112-10 Queens Blvd", expected: "112-10 QUEENS BLVD"},
+		{name: "US", country: CountryUS, line: "123 north main street", expected: "123 N MAIN ST"},
+		{name: "UK street", country: CountryGB, line: "1 High Street", expected: "1 HIGH STREET"},
+		{name: "UK directional", country: CountryGB, line: "20 North Road", expected: "20 NORTH ROAD"},
+		{name: "UK flat", country: CountryGB, line: "Flat 4, 12 Church Lane", expected: "FLAT 4 12 CHURCH LANE"},
+		{name: "other countries", country: "CA", line: "100 Queen Street West", expected: "100 QUEEN STREET WEST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Line(tt.country, tt.line))
+		})
+	}
+}
+
+func TestState(t *testing.T) {
+	tests := []struct {
+		state    string
+		expected string
+	}{
+		{state: "", expected: ""},
+		{state: "CA", expected: "CA"},
+		{state: "ca", expected: "CA"},
+		{state: "California", expected: "CA"},
+		{state: " new   york ", expected: "NY"},
+		{state: "District of Columbia", expected: "DC"},
+		{state: "Washington, D.C.", expected: "DC"},
+		{state: "Puerto Rico", expected: "PR"},
+		{state: "Ontario", expected: "ONTARIO"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			assert.Equal(t, tt.expected, State(tt.state))
+		})
+	}
+}
+
+func TestZipcode(t *testing.T) {
+	tests := []struct {
+		zipcode  string
+		expected string
+	}{
+		{zipcode: "", expected: ""},
+		{zipcode: "90210", expected: "90210"},
+		{zipcode: " 90210 ", expected: "90210"},
+		{zipcode: "902103669", expected: "90210-3669"},
+		{zipcode: "90210-3669", expected: "90210-3669"},
+		{zipcode: "90210 3669", expected: "90210-3669"},
+		{zipcode: "9021", expected: "9021"},
+		{zipcode: "sw1a 1aa", expected: "SW1A 1AA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.zipcode, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Zipcode(tt.zipcode))
+		})
+	}
+}
+
+func TestPostcode(t *testing.T) {
+	tests := []struct {
+		postcode string
+		expected string
+	}{
+		{postcode: "", expected: ""},
+		{postcode: "sw1a1aa", expected: "SW1A 1AA"},
+		{postcode: "SW1A 1AA", expected: "SW1A 1AA"},
+		{postcode: "  ec1a   1bb ", expected: "EC1A 1BB"},
+		{postcode: "M11AE", expected: "M1 1AE"},
+		{postcode: "b338th", expected: "B33 8TH"},
+		{postcode: "cr26xh", expected: "CR2 6XH"},
+		{postcode: "gir0aa", expected: "GIR 0AA"},
+		{postcode: "not a postcode", expected: "NOT A POSTCODE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.postcode, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Postcode(tt.postcode))
+		})
+	}
+}
+
+func TestCountry(t *testing.T) {
+	tests := []struct {
+		country  string
+		expected string
+	}{
+		{country: "", expected: ""},
+		{country: "us", expected: "US"},
+		{country: "U.S.A.", expected: "US"},
+		{country: "United States of America", expected: "US"},
+		{country: "uk", expected: "GB"},
+		{country: "United Kingdom", expected: "GB"},
+		{country: "Scotland", expected: "GB"},
+		{country: "fr", expected: "FR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.country, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Country(tt.country))
+		})
+	}
+}
+
+func TestValidateReq(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      address.ValidateReq
+		expected address.ValidateReq
+	}{
+		{
+			name: "US address",
+			req: address.ValidateReq{
+				Address1: "9355 Burton Way",
+				Address2: "suite #100",
+				City:     "Beverly  Hills",
+				Company:  "Courthouse, Inc.",
+				Country:  "USA",
+				State:    "California",
+				Zipcode:  "902103669",
+			},
+			expected: address.ValidateReq{
+				Address1: "9355 BURTON WAY",
+				Address2: "STE 100",
+				City:     "BEVERLY HILLS",
+				Company:  "COURTHOUSE INC",
+				Country:  "US",
+				State:    "CA",
+				Zipcode:  "90210-3669",
+			},
+		},
+		{
+			name:     "blank country is treated as the US",
+			req:      address.ValidateReq{State: "texas", Zipcode: "75001 1234"},
+			expected: address.ValidateReq{State: "TX", Zipcode: "75001-1234"},
+		},
+		{
+			name: "UK address",
+			req: address.ValidateReq{
+				Address1: "10 Downing Street",
+				City:     "London",
+				Country:  "United Kingdom",
+				State:    "Greater London",
+				Zipcode:  "sw1a2aa",
+			},
+			expected: address.ValidateReq{
+				Address1: "10 DOWNING STREET",
+				City:     "LONDON",
+				Country:  "GB",
+				State:    "GREATER LONDON",
+				Zipcode:  "SW1A 2AA",
+			},
+		},
+		{
+			name:     "other countries keep their state and postal code",
+			req:      address.ValidateReq{Country: "ca", State: "Ontario", Zipcode: "k1a 0b1"},
+			expected: address.ValidateReq{Country: "CA", State: "ONTARIO", Zipcode: "K1A 0B1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized := ValidateReq(tt.req)
+			assert.Equal(t, tt.expected, normalized)
+			assert.Equal(t, normalized, ValidateReq(normalized))
+		})
+	}
+}
+
+func TestRecipientDetails(t *testing.T) {
+	recipient := letter.RecipientDetails{
+		Address1:  "123 north main street apt. 4",
+		Country:   "us",
+		Firstname: "  Judge ",
+		Lastname:  "Judy  Sheindlin",
+		State:     "new york",
+		Title:     "Mrs.",
+		Town:      "new york",
+		Zipcode:   "10001",
+	}
+
+	assert.Equal(t, letter.RecipientDetails{
+		Address1:  "123 N MAIN ST APT 4",
+		Country:   "US",
+		Firstname: "Judge",
+		Lastname:  "Judy Sheindlin",
+		State:     "NY",
+		Title:     "Mrs.",
+		Town:      "NEW YORK",
+		Zipcode:   "10001",
+	}, RecipientDetails(recipient))
+}
+
+func TestKey(t *testing.T) {
+	first := address.ValidateReq{Address1: "123 Main Street", City: "Springfield", State: "Illinois", Zipcode: "62701"}
+	second := address.ValidateReq{Address1: "123 MAIN ST.", City: " springfield ", Country: "US", State: "IL", Zipcode: "62701"}
+	third := address.ValidateReq{Address1: "124 Main Street", City: "Springfield", State: "IL", Zipcode: "62701"}
+
+	assert.Equal(t, Key(first), Key(second))
+	assert.NotEqual(t, Key(first), Key(third))
+	assert.Equal(t, Key(ValidateReq(first)), Key(first))
+	assert.Equal(t, "|123 MAIN ST||SPRINGFIELD|IL|62701|US", Key(second))
+}
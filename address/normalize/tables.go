@@ -0,0 +1,275 @@
+package normalize
+
+// directionals maps spelled out directions to their Publication 28 abbreviations
+var directionals = withAbbreviations(map[string]string{
+	"EAST":      "E",
+	"NORTH":     "N",
+	"NORTHEAST": "NE",
+	"NORTHWEST": "NW",
+	"SOUTH":     "S",
+	"SOUTHEAST": "SE",
+	"SOUTHWEST": "SW",
+	"WEST":      "W",
+})
+
+// streetSuffixes maps common street suffixes and their variants to the Publication 28 Appendix C1 abbreviations
+var streetSuffixes = withAbbreviations(map[string]string{
+	"ALLEY":      "ALY",
+	"ANNEX":      "ANX",
+	"ARCADE":     "ARC",
+	"AVE":        "AVE",
+	"AV":         "AVE",
+	"AVEN":       "AVE",
+	"AVENUE":     "AVE",
+	"BAYOU":      "BYU",
+	"BEACH":      "BCH",
+	"BEND":       "BND",
+	"BLUFF":      "BLF",
+	"BOULEVARD":  "BLVD",
+	"BOUL":       "BLVD",
+	"BRANCH":     "BR",
+	"BRIDGE":     "BRG",
+	"BROOK":      "BRK",
+	"BYPASS":     "BYP",
+	"CAMP":       "CP",
+	"CANYON":     "CYN",
+	"CAUSEWAY":   "CSWY",
+	"CENTER":     "CTR",
+	"CENTRE":     "CTR",
+	"CIRCLE":     "CIR",
+	"CIRC":       "CIR",
+	"CLIFF":      "CLF",
+	"CLUB":       "CLB",
+	"COMMON":     "CMN",
+	"CORNER":     "COR",
+	"COURSE":     "CRSE",
+	"COURT":      "CT",
+	"COVE":       "CV",
+	"CREEK":      "CRK",
+	"CRESCENT":   "CRES",
+	"CROSSING":   "XING",
+	"DRIVE":      "DR",
+	"DRV":        "DR",
+	"ESTATE":     "EST",
+	"ESTATES":    "ESTS",
+	"EXPRESSWAY": "EXPY",
+	"EXTENSION":  "EXT",
+	"FALLS":      "FLS",
+	"FERRY":      "FRY",
+	"FIELD":      "FLD",
+	"FIELDS":     "FLDS",
+	"FLAT":       "FLT",
+	"FOREST":     "FRST",
+	"FORK":       "FRK",
+	"FORT":       "FT",
+	"FREEWAY":    "FWY",
+	"GARDEN":     "GDN",
+	"GARDENS":    "GDNS",
+	"GATEWAY":    "GTWY",
+	"GLEN":       "GLN",
+	"GREEN":      "GRN",
+	"GROVE":      "GRV",
+	"HARBOR":     "HBR",
+	"HAVEN":      "HVN",
+	"HEIGHTS":    "HTS",
+	"HIGHWAY":    "HWY",
+	"HIGHWY":     "HWY",
+	"HILL":       "HL",
+	"HILLS":      "HLS",
+	"HOLLOW":     "HOLW",
+	"ISLAND":     "IS",
+	"JUNCTION":   "JCT",
+	"KNOLL":      "KNL",
+	"LAKE":       "LK",
+	"LAKES":      "LKS",
+	"LANDING":    "LNDG",
+	"LANE":       "LN",
+	"LOOP":       "LOOP",
+	"MANOR":      "MNR",
+	"MEADOW":     "MDW",
+	"MEADOWS":    "MDWS",
+	"MILL":       "ML",
+	"MISSION":    "MSN",
+	"MOTORWAY":   "MTWY",
+	"MOUNT":      "MT",
+	"MOUNTAIN":   "MTN",
+	"ORCHARD":    "ORCH",
+	"OVAL":       "OVAL",
+	"PARKWAY":    "PKWY",
+	"PARKWY":     "PKWY",
+	"PASSAGE":    "PSGE",
+	"PIKE":       "PIKE",
+	"PINE":       "PNE",
+	"PINES":      "PNES",
+	"PLACE":      "PL",
+	"PLAIN":      "PLN",
+	"PLAINS":     "PLNS",
+	"PLAZA":      "PLZ",
+	"POINT":      "PT",
+	"POINTS":     "PTS",
+	"PORT":       "PRT",
+	"PRAIRIE":    "PR",
+	"RANCH":      "RNCH",
+	"RIDGE":      "RDG",
+	"RIVER":      "RIV",
+	"ROAD":       "RD",
+	"ROUTE":      "RTE",
+	"ROW":        "ROW",
+	"RUN":        "RUN",
+	"SHORE":      "SHR",
+	"SHORES":     "SHRS",
+	"SKYWAY":     "SKWY",
+	"SPRING":     "SPG",
+	"SPRINGS":    "SPGS",
+	"SQUARE":     "SQ",
+	"STATION":    "STA",
+	"STREAM":     "STRM",
+	"STREET":     "ST",
+	"STR":        "ST",
+	"SUMMIT":     "SMT",
+	"TERRACE":    "TER",
+	"TRACE":      "TRCE",
+	"TRACK":      "TRAK",
+	"TRAIL":      "TRL",
+	"TRAILS":     "TRL",
+	"TUNNEL":     "TUNL",
+	"TURNPIKE":   "TPKE",
+	"UNION":      "UN",
+	"VALLEY":     "VLY",
+	"VIADUCT":    "VIA",
+	"VIEW":       "VW",
+	"VILLAGE":    "VLG",
+	"VILLE":      "VL",
+	"VISTA":      "VIS",
+	"WALK":       "WALK",
+	"WAY":        "WAY",
+	"WELLS":      "WLS",
+})
+
+// unitDesignators maps secondary unit designators to the Publication 28 Appendix C2 abbreviations
+var unitDesignators = withAbbreviations(map[string]string{
+	"APARTMENT":  "APT",
+	"BASEMENT":   "BSMT",
+	"BUILDING":   "BLDG",
+	"DEPARTMENT": "DEPT",
+	"FLOOR":      "FL",
+	"FRONT":      "FRNT",
+	"HANGAR":     "HNGR",
+	"KEY":        "KEY",
+	"LOBBY":      "LBBY",
+	"LOT":        "LOT",
+	"LOWER":      "LOWR",
+	"OFFICE":     "OFC",
+	"PENTHOUSE":  "PH",
+	"PIER":       "PIER",
+	"REAR":       "REAR",
+	"ROOM":       "RM",
+	"SIDE":       "SIDE",
+	"SLIP":       "SLIP",
+	"SPACE":      "SPC",
+	"STOP":       "STOP",
+	"SUITE":      "STE",
+	"TRAILER":    "TRLR",
+	"UNIT":       "UNIT",
+	"UPPER":      "UPPR",
+})
+
+// stateCodes maps US state, district and territory names to their USPS codes
+var stateCodes = map[string]string{
+	"ALABAMA":                  "AL",
+	"ALASKA":                   "AK",
+	"AMERICAN SAMOA":           "AS",
+	"ARIZONA":                  "AZ",
+	"ARKANSAS":                 "AR",
+	"CALIFORNIA":               "CA",
+	"COLORADO":                 "CO",
+	"CONNECTICUT":              "CT",
+	"DELAWARE":                 "DE",
+	"DISTRICT OF COLUMBIA":     "DC",
+	"FLORIDA":                  "FL",
+	"GEORGIA":                  "GA",
+	"GUAM":                     "GU",
+	"HAWAII":                   "HI",
+	"IDAHO":                    "ID",
+	"ILLINOIS":                 "IL",
+	"INDIANA":                  "IN",
+	"IOWA":                     "IA",
+	"KANSAS":                   "KS",
+	"KENTUCKY":                 "KY",
+	"LOUISIANA":                "LA",
+	"MAINE":                    "ME",
+	"MARYLAND":                 "MD",
+	"MASSACHUSETTS":            "MA",
+	"MICHIGAN":                 "MI",
+	"MINNESOTA":                "MN",
+	"MISSISSIPPI":              "MS",
+	"MISSOURI":                 "MO",
+	"MONTANA":                  "MT",
+	"NEBRASKA":                 "NE",
+	"NEVADA":                   "NV",
+	"NEW HAMPSHIRE":            "NH",
+	"NEW JERSEY":               "NJ",
+	"NEW MEXICO":               "NM",
+	"NEW YORK":                 "NY",
+	"NORTH CAROLINA":           "NC",
+	"NORTH DAKOTA":             "ND",
+	"NORTHERN MARIANA ISLANDS": "MP",
+	"OHIO":                     "OH",
+	"OKLAHOMA":                 "OK",
+	"OREGON":                   "OR",
+	"PENNSYLVANIA":             "PA",
+	"PUERTO RICO":              "PR",
+	"RHODE ISLAND":             "RI",
+	"SOUTH CAROLINA":           "SC",
+	"SOUTH DAKOTA":             "SD",
+	"TENNESSEE":                "TN",
+	"TEXAS":                    "TX",
+	"UTAH":                     "UT",
+	"VERMONT":                  "VT",
+	"VIRGIN ISLANDS":           "VI",
+	"VIRGINIA":                 "VA",
+	"WASHINGTON":               "WA",
+	"WASHINGTON DC":            "DC",
+	"WEST VIRGINIA":            "WV",
+	"WISCONSIN":                "WI",
+	"WYOMING":                  "WY",
+}
+
+// stateNames is the set of valid USPS state codes
+var stateNames = func() map[string]struct{} {
+	names := make(map[string]struct{}, len(stateCodes))
+	for _, code := range stateCodes {
+		names[code] = struct{}{}
+	}
+	return names
+}()
+
+// countryAliases maps common spellings of the countries Stannp posts to onto their ISO 3166-1 alpha-2 codes
+var countryAliases = map[string]string{
+	"ENGLAND":                  CountryGB,
+	"GREAT BRITAIN":            CountryGB,
+	"NORTHERN IRELAND":         CountryGB,
+	"SCOTLAND":                 CountryGB,
+	"UK":                       CountryGB,
+	"UNITED KINGDOM":           CountryGB,
+	"WALES":                    CountryGB,
+	"UNITED STATES":            CountryUS,
+	"UNITED STATES OF AMERICA": CountryUS,
+	"USA":                      CountryUS,
+}
+
+// withAbbreviations adds each abbreviation in m as a key mapping to itself, so input that is already abbreviated is
+// recognised
+func withAbbreviations(m map[string]string) map[string]string {
+	abbreviations := make([]string, 0, len(m))
+	for _, abbreviation := range m {
+		abbreviations = append(abbreviations, abbreviation)
+	}
+
+	for _, abbreviation := range abbreviations {
+		if _, ok := m[abbreviation]; !ok {
+			m[abbreviation] = abbreviation
+		}
+	}
+	return m
+}
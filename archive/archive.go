@@ -0,0 +1,322 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/storage"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+const DefaultWorkers = 4
+const PDFKeyPrefix = "pdf_"
+const RecipientKeyPrefix = "recipient_"
+const RecordKeyPrefix = "record_"
+const JSONContentType = "application/json"
+
+// PDFFetcher downloads a letter PDF. *stannp.Stannp satisfies it.
+type PDFFetcher interface {
+	GetPDFContents(ctx context.Context, pdfURL string) (*letter.PDFRes, *util.APIError)
+}
+
+// Record is what was sent for a single letter and where its PDF is archived
+type Record struct {
+	Archived        time.Time             `json:"archived"`
+	IdempotenceyKey string                `json:"idempotenceyKey,omitempty"`
+	LetterID        string                `json:"letterId"`
+	MergeVariables  letter.MergeVariables `json:"mergeVariables"`
+	PDFChecksum     string                `json:"pdfChecksum"`
+	PDFKey          string                `json:"pdfKey"`
+	RecipientHash   string                `json:"recipientHash"`
+	Response        letter.SendRes        `json:"response"`
+	Sent            time.Time             `json:"sent"`
+	Template        string                `json:"template"`
+}
+
+type Option func(*Archiver)
+
+// Archiver keeps a content-addressed copy of every letter PDF along with a JSON Record of the request and response.
+// PDFs, records and the per-recipient index all live in a single storage.Store. Test letters are skipped without an
+// error, since Stannp gives them all the ID 0 and they can't be told apart.
+type Archiver struct {
+	mu           sync.Mutex
+	onError      func(letterID string, err *util.APIError)
+	pending      sync.WaitGroup
+	recipientKey []byte
+	store        storage.Store
+	workers      chan struct{}
+}
+
+// WithOnError registers a callback for archiving failures, which never fail the send itself
+func WithOnError(onError func(letterID string, err *util.APIError)) Option {
+	return func(a *Archiver) {
+		a.onError = onError
+	}
+}
+
+// WithWorkers sets how many letters Submit archives at once; the rest wait their turn. Defaults to DefaultWorkers.
+func WithWorkers(workers int) Option {
+	return func(a *Archiver) {
+		if workers > 0 {
+			a.workers = make(chan struct{}, workers)
+		}
+	}
+}
+
+// New archives into store. recipientKey keys the hash recipients are indexed by, so addresses can't be recovered from
+// the index by hashing likely ones; keep it secret, and keep using the same key or earlier records can't be found by
+// recipient.
+func New(store storage.Store, recipientKey []byte, opts ...Option) *Archiver {
+	a := &Archiver{
+		recipientKey: recipientKey,
+		store:        store,
+		workers:      make(chan struct{}, DefaultWorkers),
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// RecipientHash identifies a recipient without storing their address in the index, ignoring case and surrounding
+// whitespace. It is an HMAC-SHA256 keyed with key.
+func RecipientHash(key []byte, recipient letter.RecipientDetails) string {
+	fields := []string{
+		recipient.Title,
+		recipient.Firstname,
+		recipient.Lastname,
+		recipient.Address1,
+		recipient.Address2,
+		recipient.Town,
+		recipient.State,
+		recipient.Zipcode,
+		recipient.Country,
+	}
+
+	for i, field := range fields {
+		fields[i] = strings.ToLower(strings.TrimSpace(field))
+	}
+
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(strings.Join(fields, "\x1f")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RecipientHash is the hash recipient is indexed by in this archive
+func (a *Archiver) RecipientHash(recipient letter.RecipientDetails) string {
+	return RecipientHash(a.recipientKey, recipient)
+}
+
+// Submit archives a letter in the background, so the caller isn't held up downloading its PDF. Failures go to the
+// WithOnError callback. Cancelling ctx doesn't stop the archive, though its values are kept. req and res are copied,
+// so the caller is free to change them. Call Wait before exiting to let submitted letters finish.
+func (a *Archiver) Submit(ctx context.Context, fetcher PDFFetcher, req *letter.SendReq, res *letter.SendRes, sent time.Time) {
+	if isTestLetter(res) {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	reqCopy, resCopy := *req, *res
+
+	a.pending.Add(1)
+	go func() {
+		defer a.pending.Done()
+
+		a.workers <- struct{}{}
+		defer func() { <-a.workers }()
+
+		_, _ = a.Archive(ctx, fetcher, &reqCopy, &resCopy, sent)
+	}()
+}
+
+// Wait blocks until every letter passed to Submit has been archived or has failed
+func (a *Archiver) Wait() {
+	a.pending.Wait()
+}
+
+// Archive downloads the PDF for res and records req alongside it. sent is when SendLetter returned. A test letter
+// isn't archived, and returns a nil Record and no error.
+func (a *Archiver) Archive(ctx context.Context, fetcher PDFFetcher, req *letter.SendReq, res *letter.SendRes, sent time.Time) (*Record, *util.APIError) {
+	if isTestLetter(res) {
+		return nil, nil
+	}
+
+	letterID := res.Data.LetterID().String()
+	record, archiveErr := a.archive(ctx, fetcher, req, res, sent)
+	if archiveErr != nil && a.onError != nil {
+		a.onError(letterID, archiveErr)
+	}
+
+	return record, archiveErr
+}
+
+func (a *Archiver) archive(ctx context.Context, fetcher PDFFetcher, req *letter.SendReq, res *letter.SendRes, sent time.Time) (*Record, *util.APIError) {
+	letterID := res.Data.LetterID().String()
+	if idErr := storage.ValidateLetterID(letterID); idErr != nil {
+		return nil, idErr
+	}
+
+	if res.Data.PDFURL == "" {
+		return nil, util.BuildError(400, fmt.Sprintf("cannot archive letter [%s] without a pdf url", letterID))
+	}
+
+	pdfRes, getErr := fetcher.GetPDFContents(ctx, res.Data.PDFURL)
+	if getErr != nil {
+		return nil, getErr
+	}
+	defer pdfRes.Contents.Close()
+
+	contents, readErr := io.ReadAll(pdfRes.Contents)
+	if readErr != nil {
+		return nil, util.BuildError(500, fmt.Sprintf("error reading pdf for letter [%s] with err [%+v]", letterID, readErr))
+	}
+
+	checksum := sha256.Sum256(contents)
+	pdfKey := PDFKeyPrefix + hex.EncodeToString(checksum[:]) + storage.PDFExtension
+
+	// identical PDFs are stored once
+	pdfMetadata, statErr := a.store.Stat(ctx, pdfKey)
+	if statErr != nil {
+		if statErr.Code != 404 {
+			return nil, statErr
+		}
+
+		var saveErr *util.APIError
+		pdfMetadata, saveErr = a.store.Save(ctx, pdfKey, bytes.NewReader(contents), &storage.Metadata{LetterID: letterID})
+		if saveErr != nil {
+			return nil, saveErr
+		}
+	}
+
+	record := &Record{
+		Archived:        time.Now().UTC(),
+		IdempotenceyKey: req.IdempotenceyKey,
+		LetterID:        letterID,
+		MergeVariables:  req.MergeVariables,
+		PDFChecksum:     pdfMetadata.Checksum,
+		PDFKey:          pdfKey,
+		RecipientHash:   a.RecipientHash(req.Recipient),
+		Response:        *res,
+		Sent:            sent.UTC(),
+		Template:        req.Template,
+	}
+
+	if saveErr := a.saveJSON(ctx, recordKey(letterID), letterID, record); saveErr != nil {
+		return nil, saveErr
+	}
+
+	if indexErr := a.index(ctx, record); indexErr != nil {
+		return nil, indexErr
+	}
+
+	return record, nil
+}
+
+// ByLetterID returns the record archived for letterID
+func (a *Archiver) ByLetterID(ctx context.Context, letterID string) (*Record, *util.APIError) {
+	var record Record
+	if loadErr := a.loadJSON(ctx, recordKey(letterID), &record); loadErr != nil {
+		return nil, loadErr
+	}
+
+	return &record, nil
+}
+
+// ByRecipient returns every record archived for recipient, oldest first
+func (a *Archiver) ByRecipient(ctx context.Context, recipient letter.RecipientDetails) ([]Record, *util.APIError) {
+	return a.ByRecipientHash(ctx, a.RecipientHash(recipient))
+}
+
+func (a *Archiver) ByRecipientHash(ctx context.Context, recipientHash string) ([]Record, *util.APIError) {
+	var letterIDs []string
+	loadErr := a.loadJSON(ctx, RecipientKeyPrefix+recipientHash+storage.MetadataExtension, &letterIDs)
+	if loadErr != nil {
+		if loadErr.Code == 404 {
+			return []Record{}, nil
+		}
+		return nil, loadErr
+	}
+
+	records := make([]Record, 0, len(letterIDs))
+	for _, letterID := range letterIDs {
+		record, recordErr := a.ByLetterID(ctx, letterID)
+		if recordErr != nil {
+			return nil, recordErr
+		}
+		records = append(records, *record)
+	}
+
+	return records, nil
+}
+
+// OpenPDF opens the archived PDF for record
+func (a *Archiver) OpenPDF(ctx context.Context, record *Record) (io.ReadCloser, *util.APIError) {
+	contents, _, openErr := a.store.Open(ctx, record.PDFKey)
+	return contents, openErr
+}
+
+func (a *Archiver) index(ctx context.Context, record *Record) *util.APIError {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := RecipientKeyPrefix + record.RecipientHash + storage.MetadataExtension
+
+	var letterIDs []string
+	loadErr := a.loadJSON(ctx, key, &letterIDs)
+	if loadErr != nil && loadErr.Code != 404 {
+		return loadErr
+	}
+
+	for _, letterID := range letterIDs {
+		if letterID == record.LetterID {
+			return nil
+		}
+	}
+
+	return a.saveJSON(ctx, key, "", append(letterIDs, record.LetterID))
+}
+
+func (a *Archiver) loadJSON(ctx context.Context, key string, value interface{}) *util.APIError {
+	contents, _, openErr := a.store.Open(ctx, key)
+	if openErr != nil {
+		return openErr
+	}
+	defer contents.Close()
+
+	if jsonErr := json.NewDecoder(contents).Decode(value); jsonErr != nil {
+		return util.BuildError(500, fmt.Sprintf("error unmarshalling [%s] with err [%+v]", key, jsonErr))
+	}
+
+	return nil
+}
+
+func (a *Archiver) saveJSON(ctx context.Context, key, letterID string, value interface{}) *util.APIError {
+	contents, err := json.Marshal(value)
+	if err != nil {
+		return util.BuildError(500, err.Error())
+	}
+
+	_, saveErr := a.store.Save(ctx, key, bytes.NewReader(contents), &storage.Metadata{ContentType: JSONContentType, LetterID: letterID})
+	return saveErr
+}
+
+// isTestLetter reports whether res is for a test letter, which Stannp neither sends nor gives an ID of its own
+func isTestLetter(res *letter.SendRes) bool {
+	return res.Data.LetterStatus() == letter.LetterStatusTest || res.Data.LetterID() == "0"
+}
+
+func recordKey(letterID string) string {
+	return RecordKeyPrefix + storage.EscapeKey(letterID) + storage.MetadataExtension
+}
@@ -0,0 +1,141 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/storage"
+	"github.com/copilotiq/stannp-client-golang/util"
+	"github.com/jgroeneveld/trial/assert"
+)
+
+type fakeFetcher map[string]string
+
+func (f fakeFetcher) GetPDFContents(_ context.Context, pdfURL string) (*letter.PDFRes, *util.APIError) {
+	contents, ok := f[pdfURL]
+	if !ok {
+		return nil, util.BuildError(404, "not found")
+	}
+
+	return &letter.PDFRes{Contents: io.NopCloser(bytes.NewBufferString(contents)), Name: pdfURL}, nil
+}
+
+func sendRes(id, pdfURL string) *letter.SendRes {
+	return &letter.SendRes{
+		Data:    letter.Data{ID: json.Number(id), PDFURL: pdfURL, Status: "received"},
+		Success: true,
+	}
+}
+
+func TestArchiver(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	fetcher := fakeFetcher{
+		"https://us.stannp.com/api/v1/storage/get/1.pdf": "%PDF-1.4 same",
+		"https://us.stannp.com/api/v1/storage/get/2.pdf": "%PDF-1.4 same",
+	}
+
+	var failures []string
+	archiver := New(store, []byte("secret"), WithOnError(func(letterID string, _ *util.APIError) {
+		failures = append(failures, letterID)
+	}))
+
+	req := &letter.SendReq{
+		MergeVariables: letter.MergeVariables{"appointment_date": "2023-07-01"},
+		Recipient:      letter.RecipientDetails{Firstname: "Judge", Lastname: "Judy", Address1: "9355 Burton Way", Zipcode: "90210"},
+		Template:       "42",
+	}
+	sent := time.Date(2023, 6, 22, 12, 0, 0, 0, time.UTC)
+
+	first, apiErr := archiver.Archive(ctx, fetcher, req, sendRes("1", "https://us.stannp.com/api/v1/storage/get/1.pdf"), sent)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, "1", first.LetterID)
+	assert.Equal(t, "42", first.Template)
+	assert.Equal(t, sent, first.Sent)
+	assert.Equal(t, RecipientHash([]byte("secret"), req.Recipient), first.RecipientHash)
+	// without the key the hash can't be reproduced from a guessed address
+	assert.NotEqual(t, RecipientHash([]byte("other"), req.Recipient), first.RecipientHash)
+
+	second, apiErr := archiver.Archive(ctx, fetcher, req, sendRes("2", "https://us.stannp.com/api/v1/storage/get/2.pdf"), sent)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	// both letters rendered to the same bytes so only one pdf is stored
+	assert.Equal(t, first.PDFKey, second.PDFKey)
+	assert.Equal(t, 1, countPrefix(store.Keys(), PDFKeyPrefix))
+
+	record, apiErr := archiver.ByLetterID(ctx, "2")
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, "2023-07-01", record.MergeVariables["appointment_date"])
+
+	// lookups ignore case and whitespace differences in the recipient
+	lookup := req.Recipient
+	lookup.Lastname = " JUDY "
+	records, apiErr := archiver.ByRecipient(ctx, lookup)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, "1", records[0].LetterID)
+	assert.Equal(t, "2", records[1].LetterID)
+
+	pdf, apiErr := archiver.OpenPDF(ctx, record)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	contents, err := io.ReadAll(pdf)
+	assert.Nil(t, err)
+	assert.Equal(t, "%PDF-1.4 same", string(contents))
+
+	records, apiErr = archiver.ByRecipient(ctx, letter.RecipientDetails{Firstname: "Nobody"})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, 0, len(records))
+
+	_, apiErr = archiver.Archive(ctx, fetcher, req, sendRes("3", "https://us.stannp.com/api/v1/storage/get/3.pdf"), sent)
+	assert.Equal(t, 404, apiErr.Code)
+	assert.True(t, reflect.DeepEqual([]string{"3"}, failures))
+
+	// every test letter has id 0, so they are skipped rather than archived or reported as failures
+	testRes := sendRes("0", "https://us.stannp.com/api/v1/storage/get/1.pdf")
+	testRes.Data.Status = "test"
+	skipped, apiErr := archiver.Archive(ctx, fetcher, req, testRes, sent)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.True(t, skipped == nil)
+	assert.True(t, reflect.DeepEqual([]string{"3"}, failures))
+
+	// an id that can't be stored is still refused
+	_, apiErr = archiver.Archive(ctx, fetcher, req, sendRes("", "https://us.stannp.com/api/v1/storage/get/1.pdf"), sent)
+	assert.Equal(t, 400, apiErr.Code)
+}
+
+func TestArchiverSubmit(t *testing.T) {
+	store := storage.NewMemoryStore()
+	fetcher := fakeFetcher{"https://us.stannp.com/api/v1/storage/get/1.pdf": "%PDF-1.4 one"}
+	archiver := New(store, []byte("secret"), WithWorkers(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := &letter.SendReq{Template: "42"}
+	res := sendRes("1", "https://us.stannp.com/api/v1/storage/get/1.pdf")
+	archiver.Submit(ctx, fetcher, req, res, time.Now())
+
+	// neither cancelling nor changing the request afterwards affects what is archived
+	cancel()
+	req.Template = "43"
+	archiver.Wait()
+
+	record, apiErr := archiver.ByLetterID(context.Background(), "1")
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, "42", record.Template)
+}
+
+func countPrefix(keys []string, prefix string) int {
+	count := 0
+	for _, key := range keys {
+		if strings.HasPrefix(key, prefix) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,260 @@
+// Package budget stops runaway sends by tracking what letters cost per day and per month, overall and per
+// caller-defined key such as a campaign, and refusing a send that would take any of them over its limit.
+package budget
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// DefaultFallbackEstimate is what a letter is assumed to cost when it can't be priced, in cents or pence
+const DefaultFallbackEstimate = 1000
+
+// ExceededErrorType is set on the *util.APIError returned when a send would exceed a budget
+const ExceededErrorType = "budget_exceeded"
+
+// GlobalKey is the key of the budget every send counts against
+const GlobalKey = ""
+
+type Period string
+
+const (
+	PeriodDay   Period = "day"
+	PeriodMonth Period = "month"
+)
+
+var periods = []Period{PeriodDay, PeriodMonth}
+
+// Limit caps spend for a key over a period. Amounts are in the currency's minor unit, i.e. cents or pence.
+type Limit struct {
+	Amount int64  `json:"amount"`
+	Key    string `json:"key"`
+	Period Period `json:"period"`
+}
+
+// Store persists how much has been spent in each bucket, a key and period such as "campaign-a|month|2024-01". It
+// must be safe for concurrent use.
+type Store interface {
+	// Add increases each bucket in amounts by its amount. Either every bucket is increased or, on error, none is.
+	Add(ctx context.Context, amounts map[string]int64) *util.APIError
+	Get(ctx context.Context, bucket string) (int64, *util.APIError)
+}
+
+type Option func(*Guard)
+
+// Guard checks sends against their budgets. Limits can be changed at any time. Spend is recorded for every key and
+// period whether or not it has a limit, so a limit added mid-month already knows what the month has spent.
+type Guard struct {
+	fallbackEstimate int64
+	limits           map[limitKey]int64
+	location         *time.Location
+	mu               sync.Mutex
+	now              func() time.Time
+	pending          map[string]int64
+	store            Store
+}
+
+type limitKey struct {
+	key    string
+	period Period
+}
+
+// Reservation holds an estimated cost against its budgets while a letter is being sent. Exactly one of Commit or
+// Release should be called once the send has finished.
+type Reservation struct {
+	buckets  []string
+	done     bool
+	Estimate int64
+	guard    *Guard
+}
+
+// WithClock overrides time.Now, which is useful in tests
+func WithClock(now func() time.Time) Option {
+	return func(g *Guard) {
+		g.now = now
+	}
+}
+
+// WithFallbackEstimate sets what a letter that can't be priced is reserved at, so it is still held against its budgets
+// rather than refused. Defaults to DefaultFallbackEstimate.
+func WithFallbackEstimate(estimate int64) Option {
+	return func(g *Guard) {
+		g.fallbackEstimate = estimate
+	}
+}
+
+// WithLimit sets a limit when the Guard is created, the same as calling SetLimit
+func WithLimit(key string, period Period, amount int64) Option {
+	return func(g *Guard) {
+		g.limits[limitKey{key: key, period: period}] = amount
+	}
+}
+
+// WithLocation sets the time zone days and months start in. Defaults to UTC.
+func WithLocation(location *time.Location) Option {
+	return func(g *Guard) {
+		g.location = location
+	}
+}
+
+func New(store Store, opts ...Option) *Guard {
+	g := &Guard{
+		fallbackEstimate: DefaultFallbackEstimate,
+		limits:           map[limitKey]int64{},
+		location:         time.UTC,
+		now:              time.Now,
+		pending:          map[string]int64{},
+		store:            store,
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// FallbackEstimate is what to reserve for a letter that can't be priced
+func (g *Guard) FallbackEstimate() int64 {
+	return g.fallbackEstimate
+}
+
+// SetLimit caps spend for key over period. Use GlobalKey for the budget every send counts against.
+func (g *Guard) SetLimit(key string, period Period, amount int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.limits[limitKey{key: key, period: period}] = amount
+}
+
+// RemoveLimit lifts the cap on key over period
+func (g *Guard) RemoveLimit(key string, period Period) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.limits, limitKey{key: key, period: period})
+}
+
+// Limits returns the current limits sorted by key then period
+func (g *Guard) Limits() []Limit {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	limits := make([]Limit, 0, len(g.limits))
+	for lk, amount := range g.limits {
+		limits = append(limits, Limit{Amount: amount, Key: lk.key, Period: lk.period})
+	}
+
+	sort.Slice(limits, func(i, j int) bool {
+		if limits[i].Key != limits[j].Key {
+			return limits[i].Key < limits[j].Key
+		}
+		return limits[i].Period < limits[j].Period
+	})
+
+	return limits
+}
+
+// Spent returns what has been committed against key in the current period, not counting sends still in flight
+func (g *Guard) Spent(ctx context.Context, key string, period Period) (int64, *util.APIError) {
+	return g.store.Get(ctx, Bucket(key, period, g.now().In(g.location)))
+}
+
+// Reserve holds estimate against the global budget and key's budget, refusing with a 402 *util.APIError of
+// ExceededErrorType when committed spend plus sends in flight plus estimate would go over any of their limits
+func (g *Guard) Reserve(ctx context.Context, key string, estimate int64) (*Reservation, *util.APIError) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now().In(g.location)
+	keys := []string{GlobalKey}
+	if key != GlobalKey {
+		keys = append(keys, key)
+	}
+
+	var buckets []string
+	for _, k := range keys {
+		for _, period := range periods {
+			bucket := Bucket(k, period, now)
+			buckets = append(buckets, bucket)
+
+			limit, ok := g.limits[limitKey{key: k, period: period}]
+			if !ok {
+				continue
+			}
+
+			spent, getErr := g.store.Get(ctx, bucket)
+			if getErr != nil {
+				return nil, getErr
+			}
+
+			committed := spent + g.pending[bucket]
+			if committed+estimate > limit {
+				return nil, util.BuildTypedError(402, ExceededErrorType, fmt.Sprintf(
+					"sending would exceed the %s budget of [%d] for key [%s]: [%d] already spent or in flight, [%d] estimated",
+					period, limit, k, committed, estimate))
+			}
+		}
+	}
+
+	for _, bucket := range buckets {
+		g.pending[bucket] += estimate
+	}
+
+	return &Reservation{buckets: buckets, Estimate: estimate, guard: g}, nil
+}
+
+// Commit records what the letter actually cost in place of the estimate, against all of its budgets or, when the store
+// fails, none of them
+func (r *Reservation) Commit(ctx context.Context, actual int64) *util.APIError {
+	g := r.guard
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if r.done {
+		return nil
+	}
+	r.release()
+
+	amounts := make(map[string]int64, len(r.buckets))
+	for _, bucket := range r.buckets {
+		amounts[bucket] = actual
+	}
+
+	return g.store.Add(ctx, amounts)
+}
+
+// Release gives back the estimate of a letter that wasn't sent
+func (r *Reservation) Release() {
+	r.guard.mu.Lock()
+	defer r.guard.mu.Unlock()
+
+	if !r.done {
+		r.release()
+	}
+}
+
+func (r *Reservation) release() {
+	r.done = true
+	for _, bucket := range r.buckets {
+		r.guard.pending[bucket] -= r.Estimate
+		if r.guard.pending[bucket] == 0 {
+			delete(r.guard.pending, bucket)
+		}
+	}
+}
+
+// Bucket names where spend for key over the period containing t is stored, e.g. "campaign-a|month|2024-01"
+func Bucket(key string, period Period, t time.Time) string {
+	layout := "2006-01-02"
+	if period == PeriodMonth {
+		layout = "2006-01"
+	}
+
+	return fmt.Sprintf("%s|%s|%s", key, period, t.Format(layout))
+}
@@ -0,0 +1,165 @@
+package budget
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestGuard_Reserve(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 31, 23, 0, 0, 0, time.UTC)
+	guard := New(NewMemoryStore(), WithClock(func() time.Time { return now }), WithLimit(GlobalKey, PeriodDay, 1000))
+
+	first, reserveErr := guard.Reserve(ctx, "", 600)
+	assert.True(t, reflect.ValueOf(reserveErr).IsNil())
+
+	// the first letter is still in flight, so its estimate counts
+	_, reserveErr = guard.Reserve(ctx, "", 500)
+	assert.Equal(t, 402, reserveErr.Code)
+	assert.Equal(t, ExceededErrorType, reserveErr.Type)
+
+	assert.True(t, reflect.ValueOf(first.Commit(ctx, 550)).IsNil())
+	spent, _ := guard.Spent(ctx, GlobalKey, PeriodDay)
+	assert.Equal(t, int64(550), spent)
+
+	second, reserveErr := guard.Reserve(ctx, "", 450)
+	assert.True(t, reflect.ValueOf(reserveErr).IsNil())
+	second.Release()
+
+	// committing or releasing twice changes nothing
+	assert.True(t, reflect.ValueOf(first.Commit(ctx, 550)).IsNil())
+	second.Release()
+	spent, _ = guard.Spent(ctx, GlobalKey, PeriodDay)
+	assert.Equal(t, int64(550), spent)
+
+	// a new day starts a new budget
+	now = now.Add(2 * time.Hour)
+	_, reserveErr = guard.Reserve(ctx, "", 1000)
+	assert.True(t, reflect.ValueOf(reserveErr).IsNil())
+}
+
+func TestGuard_KeysAndPeriods(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	guard := New(NewMemoryStore(), WithClock(func() time.Time { return now }))
+	guard.SetLimit("campaign-a", PeriodMonth, 300)
+	guard.SetLimit(GlobalKey, PeriodMonth, 1000)
+
+	for i := 0; i < 3; i++ {
+		reservation, reserveErr := guard.Reserve(ctx, "campaign-a", 100)
+		assert.True(t, reflect.ValueOf(reserveErr).IsNil())
+		assert.True(t, reflect.ValueOf(reservation.Commit(ctx, 100)).IsNil())
+		now = now.Add(24 * time.Hour)
+	}
+
+	_, reserveErr := guard.Reserve(ctx, "campaign-a", 1)
+	assert.Equal(t, ExceededErrorType, reserveErr.Type)
+
+	// other campaigns only count against the global budget, which already includes campaign-a's spend
+	reservation, reserveErr := guard.Reserve(ctx, "campaign-b", 700)
+	assert.True(t, reflect.ValueOf(reserveErr).IsNil())
+	reservation.Release()
+	_, reserveErr = guard.Reserve(ctx, "campaign-b", 701)
+	assert.Equal(t, ExceededErrorType, reserveErr.Type)
+
+	// limits can change at runtime
+	guard.RemoveLimit("campaign-a", PeriodMonth)
+	_, reserveErr = guard.Reserve(ctx, "campaign-a", 1)
+	assert.True(t, reflect.ValueOf(reserveErr).IsNil())
+
+	assert.True(t, reflect.DeepEqual([]Limit{{Amount: 1000, Key: GlobalKey, Period: PeriodMonth}}, guard.Limits()))
+
+	spent, _ := guard.Spent(ctx, "campaign-a", PeriodMonth)
+	assert.Equal(t, int64(300), spent)
+	spent, _ = guard.Spent(ctx, "campaign-a", PeriodDay)
+	assert.Equal(t, int64(0), spent)
+}
+
+func TestGuard_Location(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+
+	// 03:00 UTC on the 1st is still the last day of the previous month in New York
+	now := time.Date(2024, 2, 1, 3, 0, 0, 0, time.UTC)
+	guard := New(NewMemoryStore(), WithClock(func() time.Time { return now }), WithLocation(newYork))
+
+	reservation, _ := guard.Reserve(context.Background(), "", 10)
+	assert.True(t, reflect.DeepEqual([]string{"|day|2024-01-31", "|month|2024-01"}, reservation.buckets))
+}
+
+func TestGuard_ConcurrentReserves(t *testing.T) {
+	ctx := context.Background()
+	guard := New(NewMemoryStore(), WithLimit(GlobalKey, PeriodDay, 500))
+
+	var accepted atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reservation, reserveErr := guard.Reserve(ctx, "", 10)
+			if reserveErr != nil {
+				return
+			}
+			accepted.Add(1)
+			_ = reservation.Commit(ctx, 10)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(50), accepted.Load())
+	spent, _ := guard.Spent(ctx, GlobalKey, PeriodDay)
+	assert.Equal(t, int64(500), spent)
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "budgets", "spend.json")
+
+	store, openErr := NewFileStore(path)
+	assert.True(t, reflect.ValueOf(openErr).IsNil())
+	assert.True(t, reflect.ValueOf(store.Add(ctx, map[string]int64{"|day|2024-01-01": 125, "|month|2024-01": 125})).IsNil())
+	assert.True(t, reflect.ValueOf(store.Add(ctx, map[string]int64{"|day|2024-01-01": 75})).IsNil())
+
+	reopened, openErr := NewFileStore(path)
+	assert.True(t, reflect.ValueOf(openErr).IsNil())
+	spent, _ := reopened.Get(ctx, "|day|2024-01-01")
+	assert.Equal(t, int64(200), spent)
+	spent, _ = reopened.Get(ctx, "|month|2024-01")
+	assert.Equal(t, int64(125), spent)
+	spent, _ = reopened.Get(ctx, "|day|2024-01-02")
+	assert.Equal(t, int64(0), spent)
+}
+
+// failingStore fails every Add without recording anything
+type failingStore struct {
+	*MemoryStore
+}
+
+func (fs failingStore) Add(_ context.Context, _ map[string]int64) *util.APIError {
+	return util.BuildError(500, "disk full")
+}
+
+func TestReservation_CommitIsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	guard := New(failingStore{NewMemoryStore()}, WithLimit("campaign-a", PeriodDay, 1000))
+
+	reservation, reserveErr := guard.Reserve(ctx, "campaign-a", 100)
+	assert.True(t, reflect.ValueOf(reserveErr).IsNil())
+	assert.Equal(t, 500, reservation.Commit(ctx, 100).Code)
+
+	for _, key := range []string{GlobalKey, "campaign-a"} {
+		for _, period := range []Period{PeriodDay, PeriodMonth} {
+			spent, _ := guard.Spent(ctx, key, period)
+			assert.Equal(t, int64(0), spent)
+		}
+	}
+}
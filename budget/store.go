@@ -0,0 +1,113 @@
+package budget
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// MemoryStore keeps spend in memory, so it is forgotten when the process exits
+type MemoryStore struct {
+	mu    sync.Mutex
+	spent map[string]int64
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{spent: map[string]int64{}}
+}
+
+func (ms *MemoryStore) Add(_ context.Context, amounts map[string]int64) *util.APIError {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for bucket, amount := range amounts {
+		ms.spent[bucket] += amount
+	}
+
+	return nil
+}
+
+func (ms *MemoryStore) Get(_ context.Context, bucket string) (int64, *util.APIError) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return ms.spent[bucket], nil
+}
+
+// FileStore keeps spend in a single JSON file so budgets survive process restarts. Every Add is written with
+// util.WriteFileAtomic before it is acknowledged.
+type FileStore struct {
+	memory *MemoryStore
+	mu     sync.Mutex
+	path   string
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore opens the spend recorded at path, creating its directory if needed
+func NewFileStore(path string) (*FileStore, *util.APIError) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, util.BuildError(500, err.Error())
+	}
+
+	memory := NewMemoryStore()
+	contents, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, util.BuildError(500, err.Error())
+	default:
+		if jsonErr := json.Unmarshal(contents, &memory.spent); jsonErr != nil {
+			return nil, util.BuildError(500, fmt.Sprintf("error unmarshalling budget file [%s] with err [%+v]", path, jsonErr))
+		}
+	}
+
+	return &FileStore{memory: memory, path: path}, nil
+}
+
+func (fs *FileStore) Add(ctx context.Context, amounts map[string]int64) *util.APIError {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.memory.mu.Lock()
+	spent := make(map[string]int64, len(fs.memory.spent)+len(amounts))
+	for b, a := range fs.memory.spent {
+		spent[b] = a
+	}
+	fs.memory.mu.Unlock()
+
+	// every bucket is in the one file, so writing it adds to all of them at once
+	for bucket, amount := range amounts {
+		spent[bucket] += amount
+	}
+
+	if writeErr := fs.write(spent); writeErr != nil {
+		return writeErr
+	}
+
+	return fs.memory.Add(ctx, amounts)
+}
+
+func (fs *FileStore) Get(ctx context.Context, bucket string) (int64, *util.APIError) {
+	return fs.memory.Get(ctx, bucket)
+}
+
+func (fs *FileStore) write(spent map[string]int64) *util.APIError {
+	contents, err := json.MarshalIndent(spent, "", "  ")
+	if err != nil {
+		return util.BuildError(500, err.Error())
+	}
+
+	_, writeErr := util.WriteFileAtomic(fs.path, bytes.NewReader(contents))
+	return writeErr
+}
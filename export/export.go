@@ -0,0 +1,275 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// Format is how an Exporter writes rows
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+// Column is one field of an export. PII columns hold recipient details and are masked when masking is on.
+type Column struct {
+	Mask  func(value string) string // how to mask the value, MaskKeepFirst when nil
+	Name  string
+	PII   bool
+	Value func(row Row) string
+}
+
+var (
+	ColumnAddress1 = Column{Name: "address1", PII: true, Value: recipientField(func(r *letter.RecipientDetails) string { return r.Address1 })}
+	ColumnAddress2 = Column{Name: "address2", PII: true, Value: recipientField(func(r *letter.RecipientDetails) string { return r.Address2 })}
+	ColumnCost     = Column{Name: "cost", Value: func(row Row) string { return row.Data.Cost }}
+	ColumnCountry  = Column{Name: "country", Value: recipientField(func(r *letter.RecipientDetails) string { return r.Country })}
+	ColumnCreated  = Column{Name: "created", Value: func(row Row) string {
+		return formatTime(row.Data.Created, row.Data.CreatedAt)
+	}}
+	ColumnDispatched = Column{Name: "dispatched", Value: func(row Row) string {
+		return formatTime(row.Data.Dispatched, row.Data.DispatchedAt)
+	}}
+	ColumnFormat        = Column{Name: "format", Value: func(row Row) string { return row.Data.Format }}
+	ColumnLetterID      = Column{Name: "letter_id", Value: func(row Row) string { return row.Data.LetterID().String() }}
+	ColumnName          = Column{Name: "name", PII: true, Value: recipientField(fullName)}
+	ColumnPDFURL        = Column{Name: "pdf_url", Value: func(row Row) string { return row.Data.PDFURL }}
+	ColumnRecipientHash = Column{Name: "recipient_hash", Value: func(row Row) string { return row.RecipientHash }}
+	ColumnState         = Column{Name: "state", Value: recipientField(func(r *letter.RecipientDetails) string { return r.State })}
+	ColumnStatus        = Column{Name: "status", Value: func(row Row) string { return row.Data.Status }}
+	ColumnTemplate      = Column{Name: "template", Value: func(row Row) string { return row.Template }}
+	ColumnTown          = Column{Name: "town", PII: true, Value: recipientField(func(r *letter.RecipientDetails) string { return r.Town })}
+	ColumnZipcode       = Column{Name: "zipcode", Mask: MaskKeepPrefix(3), PII: true, Value: recipientField(func(r *letter.RecipientDetails) string { return r.Zipcode })}
+)
+
+// Columns lists every column by name
+var Columns = map[string]Column{}
+
+// DefaultColumns are what an Exporter writes unless WithColumns says otherwise
+var DefaultColumns = []Column{ColumnLetterID, ColumnCreated, ColumnDispatched, ColumnStatus, ColumnCost, ColumnTemplate, ColumnRecipientHash}
+
+func init() {
+	for _, column := range []Column{
+		ColumnAddress1, ColumnAddress2, ColumnCost, ColumnCountry, ColumnCreated, ColumnDispatched, ColumnFormat,
+		ColumnLetterID, ColumnName, ColumnPDFURL, ColumnRecipientHash, ColumnState, ColumnStatus, ColumnTemplate,
+		ColumnTown, ColumnZipcode,
+	} {
+		Columns[column.Name] = column
+	}
+}
+
+// ColumnsNamed looks columns up by name, for choosing them from configuration
+func ColumnsNamed(names ...string) ([]Column, *util.APIError) {
+	columns := make([]Column, 0, len(names))
+	for _, name := range names {
+		column, ok := Columns[strings.TrimSpace(name)]
+		if !ok {
+			return nil, util.BuildError(400, fmt.Sprintf("unknown export column [%s]", name))
+		}
+		columns = append(columns, column)
+	}
+
+	return columns, nil
+}
+
+// MaskKeepFirst keeps the first character of each word and replaces the rest of its letters and digits with '*'
+func MaskKeepFirst(value string) string {
+	masked := []rune(value)
+	start := true
+	for i, r := range masked {
+		if r == ' ' {
+			start = true
+			continue
+		}
+
+		if !start && isAlphanumeric(r) {
+			masked[i] = '*'
+		}
+		start = false
+	}
+
+	return string(masked)
+}
+
+// MaskKeepPrefix keeps the first n characters and replaces the rest of the letters and digits with '*', e.g. for
+// keeping a ZIP code's area
+func MaskKeepPrefix(n int) func(value string) string {
+	return func(value string) string {
+		masked := []rune(value)
+		for i := n; i < len(masked); i++ {
+			if isAlphanumeric(masked[i]) {
+				masked[i] = '*'
+			}
+		}
+
+		return string(masked)
+	}
+}
+
+type Option func(*Exporter)
+
+// Exporter writes a Source of letters as CSV or JSON Lines, one row at a time
+type Exporter struct {
+	columns []Column
+	format  Format
+	maskPII bool
+}
+
+// WithColumns sets the columns to write, in order. Defaults to DefaultColumns.
+func WithColumns(columns ...Column) Option {
+	return func(e *Exporter) {
+		e.columns = columns
+	}
+}
+
+// WithMaskPII masks the values of PII columns, such as the recipient's name and address
+func WithMaskPII(maskPII bool) Option {
+	return func(e *Exporter) {
+		e.maskPII = maskPII
+	}
+}
+
+func New(format Format, opts ...Option) *Exporter {
+	e := &Exporter{
+		columns: DefaultColumns,
+		format:  format,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Export writes every row of source to w and returns how many rows it wrote. Rows are written as they are read, so
+// the export never holds more than one in memory. CSV output starts with a header row.
+func (e *Exporter) Export(ctx context.Context, w io.Writer, source Source) (int, *util.APIError) {
+	var write func(values []string) error
+	var flush func() error
+
+	switch e.format {
+	case FormatCSV:
+		csvWriter := csv.NewWriter(w)
+		write = func(values []string) error {
+			for i, value := range values {
+				values[i] = csvSafe(value)
+			}
+			return csvWriter.Write(values)
+		}
+		flush = func() error {
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+
+		header := make([]string, len(e.columns))
+		for i, column := range e.columns {
+			header[i] = column.Name
+		}
+		if err := csvWriter.Write(header); err != nil {
+			return 0, util.BuildError(500, fmt.Sprintf("error writing export header with err [%+v]", err))
+		}
+	case FormatJSONL:
+		write = func(values []string) error {
+			return e.writeJSONLine(w, values)
+		}
+		flush = func() error { return nil }
+	default:
+		return 0, util.BuildError(400, fmt.Sprintf("unknown export format [%s]", e.format))
+	}
+
+	written := 0
+	for source.Next() {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			_ = flush()
+			return written, util.BuildError(500, ctxErr.Error())
+		}
+
+		if err := write(e.values(source.Row())); err != nil {
+			return written, util.BuildError(500, fmt.Sprintf("error writing export row [%d] with err [%+v]", written+1, err))
+		}
+		written++
+	}
+
+	if err := flush(); err != nil {
+		return written, util.BuildError(500, fmt.Sprintf("error writing export with err [%+v]", err))
+	}
+
+	return written, source.Err()
+}
+
+func (e *Exporter) values(row Row) []string {
+	values := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		values[i] = column.Value(row)
+		if e.maskPII && column.PII {
+			mask := column.Mask
+			if mask == nil {
+				mask = MaskKeepFirst
+			}
+			values[i] = mask(values[i])
+		}
+	}
+
+	return values
+}
+
+// writeJSONLine writes values as a single JSON object, keeping the columns in order
+func (e *Exporter) writeJSONLine(w io.Writer, values []string) error {
+	var line strings.Builder
+	line.WriteByte('{')
+	for i, column := range e.columns {
+		if i > 0 {
+			line.WriteByte(',')
+		}
+
+		name, _ := json.Marshal(column.Name)
+		value, _ := json.Marshal(values[i])
+		line.Write(name)
+		line.WriteByte(':')
+		line.Write(value)
+	}
+	line.WriteString("}\n")
+
+	_, err := io.WriteString(w, line.String())
+	return err
+}
+
+// csvSafe stops spreadsheets from treating a value as a formula. Numbers such as a negative cost are left alone, since
+// a spreadsheet reads them as numbers whatever their sign.
+func csvSafe(value string) string {
+	if value == "" || !strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return value
+	}
+
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+
+	return "'" + value
+}
+
+// formatTime writes a time Stannp sent as RFC 3339, or as sent when it can't be parsed
+func formatTime(raw string, parse func() (time.Time, *util.APIError)) string {
+	parsed, _ := parse()
+	if parsed.IsZero() {
+		return raw
+	}
+
+	return parsed.Format(time.RFC3339)
+}
+
+func isAlphanumeric(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 0x7f
+}
@@ -0,0 +1,147 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/archive"
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/outbox"
+	"github.com/copilotiq/stannp-client-golang/util"
+	"github.com/jgroeneveld/trial/assert"
+)
+
+var recipient = letter.RecipientDetails{
+	Address1:  "123 Main St",
+	Country:   "US",
+	Firstname: "Jane",
+	Lastname:  "Doe",
+	State:     "CA",
+	Town:      "Los Angeles",
+	Zipcode:   "90001-1234",
+}
+
+func rows() []Row {
+	return []Row{
+		{
+			Data:      letter.Data{Cost: "0.84", Created: "2024-03-01 09:30:00", ID: "1", Status: "delivered"},
+			Recipient: &recipient,
+			Template:  "42",
+		},
+		{
+			Data:     letter.Data{Cost: "-0.84", Created: "not a date", ID: "2", Status: "cancelled"},
+			Template: "=HYPERLINK(\"x\")",
+		},
+	}
+}
+
+func TestExporter_CSV(t *testing.T) {
+	var out bytes.Buffer
+	columns, columnsErr := ColumnsNamed("letter_id", "created", "cost", "template", "name", "zipcode")
+	assert.True(t, reflect.ValueOf(columnsErr).IsNil())
+
+	written, exportErr := New(FormatCSV, WithColumns(columns...), WithMaskPII(true)).Export(context.Background(), &out, FromRows(rows()))
+	assert.True(t, reflect.ValueOf(exportErr).IsNil())
+	assert.Equal(t, 2, written)
+
+	assert.Equal(t, strings.Join([]string{
+		"letter_id,created,cost,template,name,zipcode",
+		"1,2024-03-01T09:30:00Z,0.84,42,J*** D**,900**-****",
+		`2,not a date,-0.84,"'=HYPERLINK(""x"")",,`,
+		"",
+	}, "\n"), out.String())
+}
+
+func TestCSVSafe(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "", expected: ""},
+		{value: "Jane", expected: "Jane"},
+		{value: "-0.84", expected: "-0.84"},
+		{value: "+1", expected: "+1"},
+		{value: "=1+1", expected: "'=1+1"},
+		{value: "-1+1", expected: "'-1+1"},
+		{value: "@SUM(A1)", expected: "'@SUM(A1)"},
+		{value: "\t=1+1", expected: "'\t=1+1"},
+		{value: "\r=1+1", expected: "'\r=1+1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, csvSafe(tt.value))
+		})
+	}
+}
+
+func TestExporter_JSONL(t *testing.T) {
+	var out bytes.Buffer
+	exporter := New(FormatJSONL, WithColumns(ColumnLetterID, ColumnStatus, ColumnAddress1, ColumnTown))
+
+	written, exportErr := exporter.Export(context.Background(), &out, FromRows(rows()[:1]))
+	assert.True(t, reflect.ValueOf(exportErr).IsNil())
+	assert.Equal(t, 1, written)
+	assert.Equal(t, `{"letter_id":"1","status":"delivered","address1":"123 Main St","town":"Los Angeles"}`+"\n", out.String())
+}
+
+type failingLetters struct {
+	served int
+}
+
+func (f *failingLetters) Err() *util.APIError {
+	return util.BuildError(503, "unavailable")
+}
+
+func (f *failingLetters) Item() letter.Data {
+	return letter.Data{ID: "7", Status: "received"}
+}
+
+func (f *failingLetters) Next() bool {
+	f.served++
+	return f.served == 1
+}
+
+func TestExporter_SourceError(t *testing.T) {
+	var out bytes.Buffer
+	written, exportErr := New(FormatJSONL).Export(context.Background(), &out, FromLetters(&failingLetters{}))
+
+	// rows already read are still written
+	assert.Equal(t, 1, written)
+	assert.Equal(t, 503, exportErr.Code)
+	assert.True(t, strings.HasPrefix(out.String(), `{"letter_id":"7"`))
+
+	_, exportErr = New("xml").Export(context.Background(), &out, FromRows(nil))
+	assert.Equal(t, 400, exportErr.Code)
+
+	_, columnsErr := ColumnsNamed("letter_id", "ssn")
+	assert.Equal(t, 400, columnsErr.Code)
+}
+
+func TestFromLedgers(t *testing.T) {
+	sent := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	source := FromOutbox(SliceIterator([]outbox.Entry{
+		{Request: letter.SendReq{Recipient: recipient}, Status: outbox.StatusPending},
+		{Cost: "0.84", LetterID: "1", Request: letter.SendReq{Recipient: recipient, Template: "42"}, Status: outbox.StatusSent, Updated: sent},
+		{Request: letter.SendReq{Recipient: recipient}, Status: outbox.StatusFailed},
+	}), []byte("secret"))
+
+	var out bytes.Buffer
+	exporter := New(FormatCSV, WithColumns(ColumnLetterID, ColumnCreated, ColumnName, ColumnRecipientHash))
+	written, _ := exporter.Export(context.Background(), &out, source)
+	assert.Equal(t, 1, written)
+	assert.Equal(t, "1,2024-03-01T09:30:00Z,Jane Doe,"+archive.RecipientHash([]byte("secret"), recipient), strings.Split(out.String(), "\n")[1])
+
+	source = FromArchive(SliceIterator([]archive.Record{{
+		RecipientHash: "abc",
+		Response:      letter.SendRes{Data: letter.Data{ID: "9", Status: "received"}},
+		Template:      "42",
+	}}))
+	assert.True(t, source.Next())
+	assert.Equal(t, Row{Data: letter.Data{ID: "9", Status: "received"}, RecipientHash: "abc", Template: "42"}, source.Row())
+	assert.False(t, source.Next())
+}
@@ -0,0 +1,199 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/archive"
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/outbox"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// Row is one letter to export. Stannp's reporting only knows the letter itself; Recipient, RecipientHash and Template
+// are filled in from local ledgers that know them.
+type Row struct {
+	Data          letter.Data
+	Recipient     *letter.RecipientDetails
+	RecipientHash string
+	Template      string
+}
+
+// Source is a stream of rows to export. Next moves to the next row, Row reads it and Err reports why the stream
+// stopped early, if it did.
+type Source interface {
+	Err() *util.APIError
+	Next() bool
+	Row() Row
+}
+
+// Iterator is a stream of items, such as a *stannp.Iterator. Next moves to the next item, Item reads it and Err reports
+// why the stream stopped early, if it did.
+type Iterator[T any] interface {
+	Err() *util.APIError
+	Item() T
+	Next() bool
+}
+
+// LetterIterator is a stream of letters, such as the *stannp.Iterator[letter.Data] from IterateLetters
+type LetterIterator = Iterator[letter.Data]
+
+type sliceIterator[T any] struct {
+	index int
+	items []T
+}
+
+// SliceIterator streams items already in memory
+func SliceIterator[T any](items []T) Iterator[T] {
+	return &sliceIterator[T]{items: items}
+}
+
+func (it *sliceIterator[T]) Err() *util.APIError {
+	return nil
+}
+
+func (it *sliceIterator[T]) Item() T {
+	return it.items[it.index-1]
+}
+
+func (it *sliceIterator[T]) Next() bool {
+	if it.index >= len(it.items) {
+		return false
+	}
+
+	it.index++
+	return true
+}
+
+type letterSource struct {
+	letters LetterIterator
+}
+
+// FromLetters exports letters as they are read from Stannp's reporting
+func FromLetters(letters LetterIterator) Source {
+	return &letterSource{letters: letters}
+}
+
+func (s *letterSource) Err() *util.APIError {
+	return s.letters.Err()
+}
+
+func (s *letterSource) Next() bool {
+	return s.letters.Next()
+}
+
+func (s *letterSource) Row() Row {
+	return Row{Data: s.letters.Item()}
+}
+
+type rowSource struct {
+	rows Iterator[Row]
+}
+
+// FromRows exports rows already in memory
+func FromRows(rows []Row) Source {
+	return &rowSource{rows: SliceIterator(rows)}
+}
+
+func (s *rowSource) Err() *util.APIError {
+	return s.rows.Err()
+}
+
+func (s *rowSource) Next() bool {
+	return s.rows.Next()
+}
+
+func (s *rowSource) Row() Row {
+	return s.rows.Item()
+}
+
+type archiveSource struct {
+	records Iterator[archive.Record]
+}
+
+// FromArchive exports archived records as they are read. Records only keep a hash of the recipient, so the recipient
+// columns are empty.
+func FromArchive(records Iterator[archive.Record]) Source {
+	return &archiveSource{records: records}
+}
+
+func (s *archiveSource) Err() *util.APIError {
+	return s.records.Err()
+}
+
+func (s *archiveSource) Next() bool {
+	return s.records.Next()
+}
+
+func (s *archiveSource) Row() Row {
+	record := s.records.Item()
+	return Row{
+		Data:          record.Response.Data,
+		RecipientHash: record.RecipientHash,
+		Template:      record.Template,
+	}
+}
+
+type outboxSource struct {
+	entries      Iterator[outbox.Entry]
+	recipientKey []byte
+	row          Row
+}
+
+// FromOutbox exports the letters the outbox has sent as entries are read, skipping entries that are still pending or
+// failed. The outbox doesn't track a letter's status, and its created time is when the outbox recorded it as sent.
+// Recipient hashes are keyed with recipientKey, so pass the archive's key for them to match its records, or nil to
+// leave them empty.
+func FromOutbox(entries Iterator[outbox.Entry], recipientKey []byte) Source {
+	return &outboxSource{entries: entries, recipientKey: recipientKey}
+}
+
+func (s *outboxSource) Err() *util.APIError {
+	return s.entries.Err()
+}
+
+func (s *outboxSource) Next() bool {
+	for s.entries.Next() {
+		entry := s.entries.Item()
+		if entry.Status != outbox.StatusSent {
+			continue
+		}
+
+		s.row = Row{
+			Data: letter.Data{
+				Cost:    entry.Cost,
+				Created: entry.Updated.UTC().Format(time.RFC3339),
+				ID:      json.Number(entry.LetterID),
+			},
+			Recipient: &entry.Request.Recipient,
+			Template:  entry.Request.Template,
+		}
+
+		if s.recipientKey != nil {
+			s.row.RecipientHash = archive.RecipientHash(s.recipientKey, entry.Request.Recipient)
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func (s *outboxSource) Row() Row {
+	return s.row
+}
+
+func recipientField(field func(r *letter.RecipientDetails) string) func(row Row) string {
+	return func(row Row) string {
+		if row.Recipient == nil {
+			return ""
+		}
+
+		return field(row.Recipient)
+	}
+}
+
+func fullName(r *letter.RecipientDetails) string {
+	return strings.Join(strings.Fields(strings.Join([]string{r.Title, r.Firstname, r.Lastname}, " ")), " ")
+}
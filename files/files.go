@@ -0,0 +1,132 @@
+package files
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+const URL = "files"
+
+const (
+	CreateFolderURL = "createFolder"
+	DeleteFolderURL = "deleteFolder"
+	DeleteURL       = "delete"
+	FoldersURL      = "folders"
+	UploadURL       = "upload"
+)
+
+// FolderQSP filters a file listing to one folder
+const FolderQSP = "folder_id"
+
+// ContentTypes are the kinds of file Stannp storage accepts, by extension
+var ContentTypes = map[string]string{
+	".jpeg": "image/jpeg",
+	".jpg":  "image/jpeg",
+	".pdf":  "application/pdf",
+	".png":  "image/png",
+}
+
+// File is an asset, such as a letterhead or an insert, kept in Stannp storage
+type File struct {
+	Created  string      `json:"created"`
+	FolderID json.Number `json:"folder_id"`
+	ID       json.Number `json:"id"`
+	Name     string      `json:"name"`
+	Size     json.Number `json:"size"`
+	URL      string      `json:"url"`
+}
+
+type Folder struct {
+	Created string      `json:"created"`
+	ID      json.Number `json:"id"`
+	Name    string      `json:"name"`
+}
+
+type FolderRes struct {
+	Data    Folder `json:"data"`
+	Success bool   `json:"success"`
+}
+
+type FoldersRes struct {
+	Data    []Folder `json:"data"`
+	Success bool     `json:"success"`
+}
+
+type ListRes struct {
+	Data    []File `json:"data"`
+	Success bool   `json:"success"`
+}
+
+type DeleteRes struct {
+	Success bool `json:"success"`
+}
+
+// UploadReq streams Contents into Stannp storage as Name, in FolderID when set. Progress is called as Contents is
+// read with the bytes sent so far and Size, which is 0 when unknown.
+type UploadReq struct {
+	Contents io.Reader
+	FolderID string
+	Name     string
+	Progress func(sent, total int64)
+	Size     int64
+}
+
+type UploadRes struct {
+	Data    File `json:"data"`
+	Success bool `json:"success"`
+}
+
+// ContentType is the content type of the file being uploaded, worked out from its name's extension, or empty when
+// Stannp storage doesn't accept that kind of file
+func (r *UploadReq) ContentType() string {
+	return ContentTypes[strings.ToLower(filepath.Ext(r.Name))]
+}
+
+// Validate checks there is something to upload and that Stannp storage accepts its kind of file
+func (r *UploadReq) Validate() *util.APIError {
+	if r.Contents == nil {
+		return util.BuildError(400, "upload contents must not be nil")
+	}
+
+	if strings.TrimSpace(r.Name) == "" {
+		return util.BuildError(400, "upload name must not be empty")
+	}
+
+	if _, ok := ContentTypes[strings.ToLower(filepath.Ext(r.Name))]; !ok {
+		return util.BuildError(400, fmt.Sprintf("[%s] is not a pdf, jpeg or png file", r.Name))
+	}
+
+	return nil
+}
+
+// Reader wraps Contents so reading it reports Progress
+func (r *UploadReq) Reader() io.Reader {
+	if r.Progress == nil {
+		return r.Contents
+	}
+
+	return &progressReader{progress: r.Progress, reader: r.Contents, total: r.Size}
+}
+
+// progressReader reports how much of an upload has been read
+type progressReader struct {
+	progress func(sent, total int64)
+	reader   io.Reader
+	sent     int64
+	total    int64
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.reader.Read(p)
+	if n > 0 {
+		pr.sent += int64(n)
+		pr.progress(pr.sent, pr.total)
+	}
+
+	return n, err
+}
@@ -0,0 +1,57 @@
+package files
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestUploadReq_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         UploadReq
+		contentType string
+		valid       bool
+	}{
+		{name: "pdf", req: UploadReq{Contents: strings.NewReader("%PDF-"), Name: "letterhead.pdf"}, contentType: "application/pdf", valid: true},
+		{name: "upper case extension", req: UploadReq{Contents: strings.NewReader(""), Name: "logo.PNG"}, contentType: "image/png", valid: true},
+		{name: "jpeg", req: UploadReq{Contents: strings.NewReader(""), Name: "insert.jpeg"}, contentType: "image/jpeg", valid: true},
+		{name: "unsupported", req: UploadReq{Contents: strings.NewReader(""), Name: "notes.txt"}, contentType: ""},
+		{name: "no name", req: UploadReq{Contents: strings.NewReader("")}},
+		{name: "no contents", req: UploadReq{Name: "letterhead.pdf"}, contentType: "application/pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validateErr := tt.req.Validate()
+			assert.Equal(t, tt.valid, reflect.ValueOf(validateErr).IsNil())
+			if !tt.valid {
+				assert.Equal(t, 400, validateErr.Code)
+			}
+			assert.Equal(t, tt.contentType, tt.req.ContentType())
+		})
+	}
+}
+
+func TestUploadReq_Reader(t *testing.T) {
+	var reports [][2]int64
+	req := UploadReq{
+		Contents: strings.NewReader(strings.Repeat("x", 10)),
+		Name:     "letterhead.pdf",
+		Progress: func(sent, total int64) { reports = append(reports, [2]int64{sent, total}) },
+		Size:     10,
+	}
+
+	buf := make([]byte, 4)
+	reader := req.Reader()
+	for {
+		if _, err := reader.Read(buf); err == io.EOF {
+			break
+		}
+	}
+
+	assert.True(t, reflect.DeepEqual([][2]int64{{4, 10}, {8, 10}, {10, 10}}, reports))
+}
@@ -0,0 +1,134 @@
+package letter
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// DefaultSheetBoundaries are the sheet counts past which a letter costs more or needs a bigger envelope: every sheet
+// after the first is charged extra, and more than six sheets no longer fit a standard envelope
+var DefaultSheetBoundaries = []int{1, 6}
+
+// maxObjectStreamSize caps how much a single compressed object stream may inflate to while counting pages
+const maxObjectStreamSize = 16 << 20
+
+var flateFilterPattern = regexp.MustCompile(`/FlateDecode\b`)
+var objectStreamPattern = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+var pageCountPattern = regexp.MustCompile(`/Type\s*/Pages\b[^>]*?/Count\s+(\d+)|/Count\s+(\d+)[^>]*?/Type\s*/Pages\b`)
+var pagePattern = regexp.MustCompile(`/Type\s*/Page\b`)
+var streamStartPattern = regexp.MustCompile(`>>\s*stream\r?\n`)
+
+// Attachment is a PDF appended after the template's pages, given either as a URL Stannp can fetch or as raw bytes
+type Attachment struct {
+	Contents []byte `json:"contents,omitempty"`
+	Name     string `json:"name"`
+	URL      string `json:"url,omitempty"`
+}
+
+func (a *Attachment) Validate() *util.APIError {
+	if (a.URL == "") == (len(a.Contents) == 0) {
+		return util.BuildError(400, fmt.Sprintf("attachment [%s] must have exactly one of a url or contents", a.Name))
+	}
+
+	if len(a.Contents) > 0 && !bytes.HasPrefix(a.Contents, []byte(PDFMagicBytes)) {
+		return util.BuildError(400, fmt.Sprintf("attachment [%s] is not a pdf", a.Name))
+	}
+
+	return nil
+}
+
+// CountPDFPages reads the page count from the /Count of a PDF's page tree root, falling back to counting page objects.
+// Besides uncompressed objects it looks inside the Flate-compressed object streams PDF 1.5 and later keep most objects
+// in. It isn't a full PDF parser: encrypted files, object streams using other filters or predictors, and revisions
+// that remove pages in an incremental update aren't supported, and may be reported as an error or a wrong count.
+func CountPDFPages(contents []byte) (int, *util.APIError) {
+	sources := append([][]byte{contents}, objectStreams(contents)...)
+
+	// the root of the page tree counts every page, so it has the largest /Count
+	count := 0
+	for _, source := range sources {
+		for _, match := range pageCountPattern.FindAllSubmatch(source, -1) {
+			for _, group := range match[1:] {
+				if n, err := strconv.Atoi(string(group)); err == nil && n > count {
+					count = n
+				}
+			}
+		}
+	}
+
+	if count == 0 {
+		for _, source := range sources {
+			count += len(pagePattern.FindAll(source, -1))
+		}
+	}
+
+	if count == 0 {
+		return 0, util.BuildError(422, "unable to determine the pdf page count")
+	}
+
+	return count, nil
+}
+
+// objectStreams inflates the Flate-compressed object streams in a PDF, skipping any that can't be read
+func objectStreams(contents []byte) [][]byte {
+	var streams [][]byte
+	for _, loc := range streamStartPattern.FindAllIndex(contents, -1) {
+		// a stream's dictionary runs from its "N 0 obj" header to the stream keyword
+		dictStart := bytes.LastIndex(contents[:loc[0]], []byte("obj"))
+		if dictStart < 0 {
+			continue
+		}
+
+		dict := contents[dictStart:loc[0]]
+		if !objectStreamPattern.Match(dict) || !flateFilterPattern.Match(dict) {
+			continue
+		}
+
+		data := contents[loc[1]:]
+		if end := bytes.Index(data, []byte("endstream")); end >= 0 {
+			data = data[:end]
+		}
+
+		reader, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+
+		inflated, err := io.ReadAll(io.LimitReader(reader, maxObjectStreamSize))
+		_ = reader.Close()
+		if err != nil {
+			continue
+		}
+
+		streams = append(streams, inflated)
+	}
+
+	return streams
+}
+
+// Sheets is how many sheets of paper pages print on
+func Sheets(pages int, duplex bool) int {
+	if duplex {
+		return (pages + 1) / 2
+	}
+
+	return pages
+}
+
+// SheetBoundaryWarnings describes every boundary crossed going from before to after sheets
+func SheetBoundaryWarnings(before, after int, boundaries []int) []string {
+	var warnings []string
+	for _, boundary := range boundaries {
+		if before <= boundary && after > boundary {
+			warnings = append(warnings, fmt.Sprintf("attachments take the letter from [%d] to [%d] sheets, past the [%d] sheet boundary", before, after, boundary))
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,83 @@
+package letter
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+// testPDF builds a minimal uncompressed PDF with the given number of pages
+func testPDF(pages int) []byte {
+	var b strings.Builder
+	b.WriteString("%PDF-1.4\n1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n")
+	fmt.Fprintf(&b, "2 0 obj << /Type /Pages /Kids [] /Count %d >> endobj\n", pages)
+	for i := 0; i < pages; i++ {
+		fmt.Fprintf(&b, "%d 0 obj << /Type /Page /Parent 2 0 R >> endobj\n", i+3)
+	}
+	b.WriteString("%%EOF")
+	return []byte(b.String())
+}
+
+// testCompressedPDF builds a PDF 1.5 file whose page tree is inside a compressed object stream, as most PDF writers
+// produce
+func testCompressedPDF(pages int) []byte {
+	var objects strings.Builder
+	fmt.Fprintf(&objects, "2 0 3 50 << /Type /Pages /Kids [] /Count %d >> ", pages)
+	for i := 0; i < pages; i++ {
+		objects.WriteString("<< /Type /Page /Parent 2 0 R >> ")
+	}
+
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	_, _ = writer.Write([]byte(objects.String()))
+	_ = writer.Close()
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.5\n1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n")
+	fmt.Fprintf(&b, "5 0 obj << /Type /ObjStm /N %d /First 8 /Filter /FlateDecode /Length %d >> stream\n", pages+1, compressed.Len())
+	b.Write(compressed.Bytes())
+	b.WriteString("\nendstream\nendobj\n%%EOF")
+	return b.Bytes()
+}
+
+func TestCountPDFPages(t *testing.T) {
+	pages, apiErr := CountPDFPages(testPDF(3))
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, 3, pages)
+
+	// without a page tree count the page objects are counted instead
+	pages, apiErr = CountPDFPages([]byte("%PDF-1.4 << /Type/Page >> << /Type /Page >> << /Type /Pages >>"))
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, 2, pages)
+
+	// the page tree of a PDF 1.5 file is usually inside a compressed object stream
+	pages, apiErr = CountPDFPages(testCompressedPDF(4))
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, 4, pages)
+
+	_, apiErr = CountPDFPages([]byte("%PDF-1.5 compressed"))
+	assert.Equal(t, 422, apiErr.Code)
+}
+
+func TestSheetBoundaryWarnings(t *testing.T) {
+	assert.Equal(t, 2, Sheets(3, true))
+	assert.Equal(t, 3, Sheets(3, false))
+
+	assert.Equal(t, 0, len(SheetBoundaryWarnings(1, 1, DefaultSheetBoundaries)))
+	assert.Equal(t, 0, len(SheetBoundaryWarnings(2, 6, DefaultSheetBoundaries)))
+	assert.Equal(t, 1, len(SheetBoundaryWarnings(1, 2, DefaultSheetBoundaries)))
+	assert.Equal(t, 2, len(SheetBoundaryWarnings(1, 7, DefaultSheetBoundaries)))
+}
+
+func TestAttachment_Validate(t *testing.T) {
+	assert.True(t, reflect.ValueOf((&Attachment{URL: "https://example.com/consent.pdf"}).Validate()).IsNil())
+	assert.True(t, reflect.ValueOf((&Attachment{Contents: testPDF(1)}).Validate()).IsNil())
+	assert.Equal(t, 400, (&Attachment{Name: "neither"}).Validate().Code)
+	assert.Equal(t, 400, (&Attachment{Contents: testPDF(1), Name: "both", URL: "https://example.com"}).Validate().Code)
+	assert.Equal(t, 400, (&Attachment{Contents: []byte("<html>"), Name: "html"}).Validate().Code)
+}
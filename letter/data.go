@@ -0,0 +1,236 @@
+package letter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// LetterID identifies a letter. Stannp sends it as a number or a string depending on the endpoint.
+type LetterID string
+
+func (id LetterID) Int64() (int64, error) {
+	return strconv.ParseInt(string(id), 10, 64)
+}
+
+func (id LetterID) String() string {
+	return string(id)
+}
+
+// LetterStatus is where a letter is in Stannp's production and delivery pipeline
+type LetterStatus string
+
+const (
+	LetterStatusCancelled     LetterStatus = "cancelled"
+	LetterStatusDelivered     LetterStatus = "delivered"
+	LetterStatusHandedOver    LetterStatus = "handed_over"
+	LetterStatusLocalDelivery LetterStatus = "local_delivery"
+	LetterStatusProducing     LetterStatus = "producing"
+	LetterStatusReceived      LetterStatus = "received"
+	LetterStatusReturned      LetterStatus = "returned"
+	LetterStatusTest          LetterStatus = "test"
+	LetterStatusUnknown       LetterStatus = "unknown" // a status this client doesn't recognise yet
+)
+
+var letterStatuses = map[LetterStatus]struct{}{
+	LetterStatusCancelled:     {},
+	LetterStatusDelivered:     {},
+	LetterStatusHandedOver:    {},
+	LetterStatusLocalDelivery: {},
+	LetterStatusProducing:     {},
+	LetterStatusReceived:      {},
+	LetterStatusReturned:      {},
+	LetterStatusTest:          {},
+}
+
+// ParseLetterStatus maps Stannp's status text onto a LetterStatus, ignoring case and treating spaces and dashes as
+// underscores. Anything unrecognised is LetterStatusUnknown.
+func ParseLetterStatus(status string) LetterStatus {
+	normalized := strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(status)))
+	if _, ok := letterStatuses[LetterStatus(normalized)]; ok {
+		return LetterStatus(normalized)
+	}
+
+	return LetterStatusUnknown
+}
+
+// Money is an exact amount in a currency's minor unit, i.e. cents or pence
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// ParseMoney parses a decimal amount such as "0.84" into Money without going through a float. Letters never cost a
+// negative amount, so signed amounts such as "-0.50" are refused rather than parsed.
+func ParseMoney(amount, currency string) (Money, *util.APIError) {
+	trimmed := strings.TrimSpace(amount)
+	if strings.HasPrefix(trimmed, "-") {
+		return Money{}, util.BuildError(400, fmt.Sprintf("amount [%s] must not be negative", amount))
+	}
+
+	whole, fraction, _ := strings.Cut(trimmed, ".")
+	if whole == "" {
+		whole = "0"
+	}
+
+	if len(fraction) > 2 {
+		return Money{}, util.BuildError(400, fmt.Sprintf("amount [%s] has more than two decimal places", amount))
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	units, wholeErr := strconv.ParseUint(whole, 10, 63)
+	cents, fractionErr := strconv.ParseUint(fraction, 10, 63)
+	if trimmed == "" || wholeErr != nil || fractionErr != nil {
+		return Money{}, util.BuildError(400, fmt.Sprintf("unable to parse amount [%s]", amount))
+	}
+
+	return Money{Amount: int64(units)*100 + int64(cents), Currency: currency}, nil
+}
+
+// Decimal formats the amount with two decimal places, e.g. "0.84"
+func (m Money) Decimal() string {
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+func (m Money) String() string {
+	return strings.TrimSpace(m.Decimal() + " " + m.Currency)
+}
+
+// Currency is the currency letters sent through the region are charged in
+func (r Region) Currency() string {
+	switch r {
+	case RegionUK:
+		return "GBP"
+	case RegionUS:
+		return "USD"
+	}
+
+	return ""
+}
+
+// timeLayouts are the formats Stannp has been seen to send dates in. Times without a zone are UTC.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// CostAmount parses Cost as Money in currency, which Stannp doesn't send; see Region.Currency
+func (d *Data) CostAmount(currency string) (Money, *util.APIError) {
+	return ParseMoney(d.Cost, currency)
+}
+
+// CreatedAt parses Created. It is the zero time when Stannp didn't send one.
+func (d *Data) CreatedAt() (time.Time, *util.APIError) {
+	return parseTime(d.Created)
+}
+
+// DispatchedAt parses Dispatched. It is the zero time until the letter has been dispatched.
+func (d *Data) DispatchedAt() (time.Time, *util.APIError) {
+	return parseTime(d.Dispatched)
+}
+
+func (d *Data) LetterID() LetterID {
+	return LetterID(d.ID.String())
+}
+
+func (d *Data) LetterStatus() LetterStatus {
+	return ParseLetterStatus(d.Status)
+}
+
+// UnmarshalJSON accepts every field as a string, a number or null, since Stannp isn't consistent about which it sends
+func (d *Data) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Cost       json.RawMessage `json:"cost"`
+		Created    json.RawMessage `json:"created"`
+		Dispatched json.RawMessage `json:"dispatched"`
+		Format     json.RawMessage `json:"format"`
+		ID         json.RawMessage `json:"id"`
+		PDFURL     json.RawMessage `json:"pdf"`
+		Status     json.RawMessage `json:"status"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	fields := []struct {
+		name  string
+		raw   json.RawMessage
+		value *string
+	}{
+		{name: "cost", raw: raw.Cost, value: &d.Cost},
+		{name: "created", raw: raw.Created, value: &d.Created},
+		{name: "dispatched", raw: raw.Dispatched, value: &d.Dispatched},
+		{name: "format", raw: raw.Format, value: &d.Format},
+		{name: "pdf", raw: raw.PDFURL, value: &d.PDFURL},
+		{name: "status", raw: raw.Status, value: &d.Status},
+	}
+
+	for _, field := range fields {
+		value, err := rawString(field.raw)
+		if err != nil {
+			return fmt.Errorf("error decoding letter %s: %w", field.name, err)
+		}
+		*field.value = value
+	}
+
+	id, err := rawString(raw.ID)
+	if err != nil {
+		return fmt.Errorf("error decoding letter id: %w", err)
+	}
+	d.ID = json.Number(id)
+
+	return nil
+}
+
+// rawString returns a JSON string's contents or a JSON number's digits, and "" for null or a missing value
+func rawString(raw json.RawMessage) (string, error) {
+	trimmed := bytes.TrimSpace(raw)
+	switch {
+	case len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")):
+		return "", nil
+	case trimmed[0] == '"':
+		var s string
+		err := json.Unmarshal(trimmed, &s)
+		return s, err
+	case trimmed[0] == '-' || (trimmed[0] >= '0' && trimmed[0] <= '9'):
+		var n json.Number
+		err := json.Unmarshal(trimmed, &n)
+		return n.String(), err
+	}
+
+	return "", fmt.Errorf("expected a string or a number, got [%s]", string(trimmed))
+}
+
+func parseTime(value string) (time.Time, *util.APIError) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" || trimmed == "0000-00-00 00:00:00" || trimmed == "0000-00-00" {
+		return time.Time{}, nil
+	}
+
+	if seconds, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	for _, layout := range timeLayouts {
+		if parsed, err := time.Parse(layout, trimmed); err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+
+	return time.Time{}, util.BuildError(400, fmt.Sprintf("unable to parse time [%s]", value))
+}
@@ -0,0 +1,155 @@
+package letter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestData_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected Data
+	}{
+		{
+			name:     "strings",
+			raw:      `{"id":"12345","cost":"0.84","created":"2024-01-02 03:04:05","status":"received","format":"US-LETTER","pdf":"https://example.com/a.pdf"}`,
+			expected: Data{Cost: "0.84", Created: "2024-01-02 03:04:05", Format: "US-LETTER", ID: "12345", PDFURL: "https://example.com/a.pdf", Status: "received"},
+		},
+		{
+			name:     "numbers",
+			raw:      `{"id":12345,"cost":1.2,"created":1704164645,"dispatched":1704251045}`,
+			expected: Data{Cost: "1.2", Created: "1704164645", Dispatched: "1704251045", ID: "12345"},
+		},
+		{
+			name:     "nulls and missing fields",
+			raw:      `{"id":null,"cost":null,"status":null}`,
+			expected: Data{},
+		},
+		{
+			name:     "empty id",
+			raw:      `{"id":""}`,
+			expected: Data{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data Data
+			assert.Nil(t, json.Unmarshal([]byte(tt.raw), &data))
+			assert.Equal(t, tt.expected, data)
+		})
+	}
+
+	var data Data
+	assert.NotNil(t, json.Unmarshal([]byte(`{"cost":{"amount":1}}`), &data))
+}
+
+func TestData_Accessors(t *testing.T) {
+	data := Data{Cost: "1.20", Created: "2024-01-02 03:04:05", ID: "12345", Status: "Handed Over"}
+
+	assert.Equal(t, LetterID("12345"), data.LetterID())
+	id, err := data.LetterID().Int64()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(12345), id)
+
+	assert.Equal(t, LetterStatusHandedOver, data.LetterStatus())
+
+	cost, costErr := data.CostAmount(RegionUS.Currency())
+	assert.True(t, reflect.ValueOf(costErr).IsNil())
+	assert.Equal(t, Money{Amount: 120, Currency: "USD"}, cost)
+	assert.Equal(t, "1.20 USD", cost.String())
+
+	created, createdErr := data.CreatedAt()
+	assert.True(t, reflect.ValueOf(createdErr).IsNil())
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), created)
+
+	dispatched, dispatchedErr := data.DispatchedAt()
+	assert.True(t, reflect.ValueOf(dispatchedErr).IsNil())
+	assert.True(t, dispatched.IsZero())
+}
+
+func TestParseLetterStatus(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected LetterStatus
+	}{
+		{status: "received", expected: LetterStatusReceived},
+		{status: "PRODUCING", expected: LetterStatusProducing},
+		{status: "handed_over", expected: LetterStatusHandedOver},
+		{status: "local-delivery", expected: LetterStatusLocalDelivery},
+		{status: " delivered ", expected: LetterStatusDelivered},
+		{status: "returned", expected: LetterStatusReturned},
+		{status: "cancelled", expected: LetterStatusCancelled},
+		{status: "test", expected: LetterStatusTest},
+		{status: "teleported", expected: LetterStatusUnknown},
+		{status: "", expected: LetterStatusUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseLetterStatus(tt.status))
+		})
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount   string
+		expected int64
+		ok       bool
+	}{
+		{amount: "0.84", expected: 84, ok: true},
+		{amount: "1.5", expected: 150, ok: true},
+		{amount: "12", expected: 1200, ok: true},
+		{amount: ".99", expected: 99, ok: true},
+		{amount: " 3.00 ", expected: 300, ok: true},
+		{amount: "", ok: false},
+		{amount: "1.234", ok: false},
+		{amount: "-1.00", ok: false},
+		{amount: "-1.50", ok: false},
+		{amount: "-0.50", ok: false},
+		{amount: "+1.50", ok: false},
+		{amount: "1.-5", ok: false},
+		{amount: "free", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			money, parseErr := ParseMoney(tt.amount, "GBP")
+			assert.Equal(t, tt.ok, reflect.ValueOf(parseErr).IsNil())
+			assert.Equal(t, tt.expected, money.Amount)
+		})
+	}
+
+	assert.Equal(t, "-0.05", Money{Amount: -5}.Decimal())
+	assert.Equal(t, "12.30", Money{Amount: 1230}.String())
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Time
+		ok       bool
+	}{
+		{value: "", ok: true},
+		{value: "0000-00-00 00:00:00", ok: true},
+		{value: "2024-01-02", expected: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), ok: true},
+		{value: "2024-01-02T03:04:05Z", expected: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), ok: true},
+		{value: "2024-01-02T03:04:05+01:00", expected: time.Date(2024, 1, 2, 2, 4, 5, 0, time.UTC), ok: true},
+		{value: "1704164645", expected: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), ok: true},
+		{value: "yesterday", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			parsed, parseErr := parseTime(tt.value)
+			assert.Equal(t, tt.ok, reflect.ValueOf(parseErr).IsNil())
+			assert.True(t, tt.expected.Equal(parsed))
+		})
+	}
+}
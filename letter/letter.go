@@ -3,22 +3,53 @@ package letter
 import (
 	"encoding/json"
 	"io"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/util"
 )
 
 const URL = "letters"
 
+// AddressUndeliverableErrorType is set on the *util.APIError returned when a validate-before-send policy refuses a letter
+const AddressUndeliverableErrorType = "address_undeliverable"
+
+// Types set on the *util.APIError returned when a letter PDF cannot be downloaded
+const (
+	PDFContentTypeErrorType = "pdf_content_type"
+	PDFInvalidErrorType     = "pdf_invalid"
+	PDFReadErrorType        = "pdf_read"
+	PDFStatusErrorType      = "pdf_status"
+	PDFTooLargeErrorType    = "pdf_too_large"
+)
+
+const PDFMagicBytes = "%PDF-"
+
+// PostDateFormat is how SendReq.PostDate is sent to Stannp
+const PostDateFormat = "2006-01-02"
+
+// Data is a letter as Stannp describes it. The fields hold Stannp's values as sent; use CostAmount, CreatedAt,
+// DispatchedAt, LetterID and LetterStatus for typed values.
 type Data struct {
-	Cost    string      `json:"cost"`
-	Created string      `json:"created"`
-	Format  string      `json:"format"`
-	ID      json.Number `json:"id"`
-	PDFURL  string      `json:"pdf"`
-	Status  string      `json:"status"`
+	Cost       string      `json:"cost"`
+	Created    string      `json:"created"`
+	Dispatched string      `json:"dispatched,omitempty"`
+	Format     string      `json:"format"`
+	ID         json.Number `json:"id"`
+	PDFURL     string      `json:"pdf"`
+	Status     string      `json:"status"`
+}
+
+// GetRes is Stannp's answer to looking up a single letter
+type GetRes struct {
+	Data    Data `json:"data"`
+	Success bool `json:"success"`
 }
 
 type PDFRes struct {
-	Contents io.ReadCloser
-	Name     string
+	Checksum      string // hex encoded SHA-256 of Contents
+	ContentLength int64
+	Contents      io.ReadCloser
+	Name          string
 }
 
 type RecipientDetails struct {
@@ -35,14 +66,86 @@ type RecipientDetails struct {
 
 type MergeVariables map[string]string
 
+// SendOptions are the print and posting flags applied to a letter
+type SendOptions struct {
+	ClearZone      bool `json:"clearZone"`
+	Duplex         bool `json:"duplex"`
+	PostUnverified bool `json:"postUnverified"`
+	Test           bool `json:"test"`
+}
+
+// SendReq's ClearZone, Duplex, PostUnverified and Test override the client's defaults for this letter when set
 type SendReq struct {
-	IdempotenceyKey string           `json:"idempotenceyKey"`
-	MergeVariables  MergeVariables   `json:"mergeVariables"`
-	Recipient       RecipientDetails `json:"recipient"`
-	Template        string           `json:"template"`
+	Attachments     []Attachment      `json:"attachments,omitempty"`
+	BudgetKey       string            `json:"budgetKey,omitempty"` // spend is also counted against this key's budget, e.g. a campaign
+	ClearZone       *bool             `json:"clearZone,omitempty"`
+	Duplex          *bool             `json:"duplex,omitempty"`
+	IdempotenceyKey string            `json:"idempotenceyKey"`
+	Mail            MailOptions       `json:"mail"`
+	MergeVariables  MergeVariables    `json:"mergeVariables"`
+	PostDate        *time.Time        `json:"postDate,omitempty"` // post on this day rather than at the next dispatch
+	PostUnverified  *bool             `json:"postUnverified,omitempty"`
+	Recipient       RecipientDetails  `json:"recipient"`
+	Sender          *RecipientDetails `json:"sender,omitempty"` // return address, defaulting to the client's then the account's
+	Template        string            `json:"template"`
+	Test            *bool             `json:"test,omitempty"`
+}
+
+// AddressPolicy decides what SendLetter does with the recipient's address validation result before sending
+type AddressPolicy string
+
+const (
+	// AddressPolicyProceed sends to the address as given whatever the result, only reporting it
+	AddressPolicyProceed AddressPolicy = "proceed"
+	// AddressPolicyRefuse refuses to send to an undeliverable address and otherwise sends to the address as given
+	AddressPolicyRefuse AddressPolicy = "refuse"
+	// AddressPolicySubstitute refuses to send to an undeliverable address and otherwise sends to the corrected address
+	AddressPolicySubstitute AddressPolicy = "substitute"
+)
+
+// AddressCheck reports how the recipient's address was validated before sending and which address the letter went to
+type AddressCheck struct {
+	Corrected       bool             `json:"corrected"` // Used is the corrected address rather than the one requested
+	Deliverability  string           `json:"deliverability,omitempty"`
+	Policy          AddressPolicy    `json:"policy"`
+	Used            RecipientDetails `json:"used"`
+	Valid           bool             `json:"valid"`
+	ValidationError *util.APIError   `json:"validationError,omitempty"` // set when validation failed and the policy proceeded anyway
 }
 
 type SendRes struct {
-	Data    Data `json:"data"`
-	Success bool `json:"success"`
+	AddressCheck *AddressCheck  `json:"-"` // set when the client validates addresses before sending
+	BudgetError  *util.APIError `json:"-"` // set when the letter was sent but its cost could not be recorded against its budgets
+	Data         Data           `json:"data"`
+	MailOptions  MailOptions    `json:"-"` // the mail options the letter was sent with
+	PageCount    int            `json:"-"` // template plus attachment pages, when the letter has attachments and every count is known
+	Success      bool           `json:"success"`
+	Warnings     []string       `json:"-"`
+}
+
+// Bool returns a pointer to b, for setting the optional overrides on SendReq
+func Bool(b bool) *bool {
+	return &b
+}
+
+// Options applies the request's overrides on top of defaults
+func (r *SendReq) Options(defaults SendOptions) SendOptions {
+	options := defaults
+	if r.ClearZone != nil {
+		options.ClearZone = *r.ClearZone
+	}
+
+	if r.Duplex != nil {
+		options.Duplex = *r.Duplex
+	}
+
+	if r.PostUnverified != nil {
+		options.PostUnverified = *r.PostUnverified
+	}
+
+	if r.Test != nil {
+		options.Test = *r.Test
+	}
+
+	return options
 }
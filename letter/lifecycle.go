@@ -0,0 +1,100 @@
+package letter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// StatusTransitionErrorType is set on the *util.APIError returned when an observed status can't follow the last known one
+const StatusTransitionErrorType = "letter_status_transition"
+
+// transitions lists the statuses each status can move to. Later stages can be reached directly since a poller or a
+// lost webhook can miss the stages in between. Test letters never leave the test status.
+var transitions = map[LetterStatus][]LetterStatus{
+	LetterStatusReceived:      {LetterStatusProducing, LetterStatusHandedOver, LetterStatusLocalDelivery, LetterStatusDelivered, LetterStatusReturned, LetterStatusCancelled},
+	LetterStatusProducing:     {LetterStatusHandedOver, LetterStatusLocalDelivery, LetterStatusDelivered, LetterStatusReturned, LetterStatusCancelled},
+	LetterStatusHandedOver:    {LetterStatusLocalDelivery, LetterStatusDelivered, LetterStatusReturned},
+	LetterStatusLocalDelivery: {LetterStatusDelivered, LetterStatusReturned},
+	LetterStatusDelivered:     {},
+	LetterStatusReturned:      {},
+	LetterStatusCancelled:     {},
+	LetterStatusTest:          {},
+}
+
+// IsTerminal is true for statuses a letter never leaves: delivered, returned, cancelled and test
+func (s LetterStatus) IsTerminal() bool {
+	next, ok := transitions[s]
+	return ok && len(next) == 0
+}
+
+// CanTransition reports whether a letter can move from one status to another. Staying put is always allowed.
+func CanTransition(from, to LetterStatus) bool {
+	if from == to {
+		return true
+	}
+
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reconcile decides a letter's status given the last known one and a newly observed one. Observations of an unknown
+// status, or when nothing is known yet of any status, are accepted or ignored without error: an unknown observed status
+// keeps the last known one, and anything is accepted when the last known status is empty or unknown. A move the
+// lifecycle doesn't allow, such as delivered back to producing, keeps the last known status and returns a 409
+// *util.APIError of StatusTransitionErrorType.
+func Reconcile(last, observed LetterStatus) (LetterStatus, *util.APIError) {
+	switch {
+	case observed == LetterStatusUnknown || observed == "":
+		return last, nil
+	case last == LetterStatusUnknown || last == "":
+		return observed, nil
+	case !CanTransition(last, observed):
+		return last, util.BuildTypedError(409, StatusTransitionErrorType, fmt.Sprintf("letter status cannot go from [%s] to [%s]", last, observed))
+	}
+
+	return observed, nil
+}
+
+// StatusChange is one accepted move between statuses
+type StatusChange struct {
+	At   time.Time    `json:"at"`
+	From LetterStatus `json:"from"`
+	To   LetterStatus `json:"to"`
+}
+
+// Lifecycle tracks a letter's status and how it got there. Anything that learns a letter's status, such as a poller
+// or a webhook handler, should report it through Observe so every view of the letter agrees.
+type Lifecycle struct {
+	History  []StatusChange `json:"history"`
+	LetterID LetterID       `json:"letterId"`
+	Status   LetterStatus   `json:"status"`
+}
+
+// Observe reconciles status against the current one, recording a StatusChange when it moves. It reports whether the
+// status changed; rejected transitions leave the Lifecycle untouched and return Reconcile's error.
+func (l *Lifecycle) Observe(status LetterStatus, at time.Time) (bool, *util.APIError) {
+	next, reconcileErr := Reconcile(l.Status, status)
+	if reconcileErr != nil {
+		return false, reconcileErr
+	}
+
+	if next == l.Status {
+		return false, nil
+	}
+
+	l.History = append(l.History, StatusChange{At: at, From: l.Status, To: next})
+	l.Status = next
+	return true, nil
+}
+
+// IsTerminal is true once the letter has reached a status it will never leave
+func (l *Lifecycle) IsTerminal() bool {
+	return l.Status.IsTerminal()
+}
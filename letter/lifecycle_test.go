@@ -0,0 +1,83 @@
+package letter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name     string
+		last     LetterStatus
+		observed LetterStatus
+		expected LetterStatus
+		rejected bool
+	}{
+		{name: "first observation", last: "", observed: LetterStatusReceived, expected: LetterStatusReceived},
+		{name: "after an unknown status", last: LetterStatusUnknown, observed: LetterStatusProducing, expected: LetterStatusProducing},
+		{name: "unchanged", last: LetterStatusProducing, observed: LetterStatusProducing, expected: LetterStatusProducing},
+		{name: "next stage", last: LetterStatusReceived, observed: LetterStatusProducing, expected: LetterStatusProducing},
+		{name: "skipped stages", last: LetterStatusReceived, observed: LetterStatusDelivered, expected: LetterStatusDelivered},
+		{name: "returned in transit", last: LetterStatusHandedOver, observed: LetterStatusReturned, expected: LetterStatusReturned},
+		{name: "cancelled before dispatch", last: LetterStatusProducing, observed: LetterStatusCancelled, expected: LetterStatusCancelled},
+		{name: "unknown observation is ignored", last: LetterStatusHandedOver, observed: LetterStatusUnknown, expected: LetterStatusHandedOver},
+		{name: "backwards", last: LetterStatusHandedOver, observed: LetterStatusProducing, expected: LetterStatusHandedOver, rejected: true},
+		{name: "cancelled after dispatch", last: LetterStatusHandedOver, observed: LetterStatusCancelled, expected: LetterStatusHandedOver, rejected: true},
+		{name: "out of a terminal status", last: LetterStatusDelivered, observed: LetterStatusReturned, expected: LetterStatusDelivered, rejected: true},
+		{name: "test letters stay test letters", last: LetterStatusTest, observed: LetterStatusDelivered, expected: LetterStatusTest, rejected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, reconcileErr := Reconcile(tt.last, tt.observed)
+			assert.Equal(t, tt.expected, status)
+			assert.Equal(t, tt.rejected, !reflect.ValueOf(reconcileErr).IsNil())
+			if tt.rejected {
+				assert.Equal(t, 409, reconcileErr.Code)
+				assert.Equal(t, StatusTransitionErrorType, reconcileErr.Type)
+			}
+		})
+	}
+}
+
+func TestLetterStatus_IsTerminal(t *testing.T) {
+	for _, status := range []LetterStatus{LetterStatusCancelled, LetterStatusDelivered, LetterStatusReturned, LetterStatusTest} {
+		assert.True(t, status.IsTerminal())
+	}
+
+	for _, status := range []LetterStatus{LetterStatusReceived, LetterStatusProducing, LetterStatusHandedOver, LetterStatusLocalDelivery, LetterStatusUnknown} {
+		assert.False(t, status.IsTerminal())
+	}
+}
+
+func TestLifecycle_Observe(t *testing.T) {
+	start := time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+	lifecycle := Lifecycle{LetterID: "12345"}
+
+	changed, observeErr := lifecycle.Observe(LetterStatusReceived, start)
+	assert.True(t, reflect.ValueOf(observeErr).IsNil())
+	assert.True(t, changed)
+
+	changed, _ = lifecycle.Observe(LetterStatusReceived, start.Add(time.Hour))
+	assert.False(t, changed)
+
+	changed, _ = lifecycle.Observe(LetterStatusHandedOver, start.Add(24*time.Hour))
+	assert.True(t, changed)
+
+	changed, observeErr = lifecycle.Observe(LetterStatusProducing, start.Add(25*time.Hour))
+	assert.False(t, changed)
+	assert.Equal(t, StatusTransitionErrorType, observeErr.Type)
+
+	changed, _ = lifecycle.Observe(LetterStatusDelivered, start.Add(72*time.Hour))
+	assert.True(t, changed)
+	assert.True(t, lifecycle.IsTerminal())
+
+	assert.True(t, reflect.DeepEqual([]StatusChange{
+		{At: start, From: "", To: LetterStatusReceived},
+		{At: start.Add(24 * time.Hour), From: LetterStatusReceived, To: LetterStatusHandedOver},
+		{At: start.Add(72 * time.Hour), From: LetterStatusHandedOver, To: LetterStatusDelivered},
+	}, lifecycle.History))
+}
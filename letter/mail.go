@@ -0,0 +1,146 @@
+package letter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// Region is the Stannp platform a client sends through, which decides the mail options available
+type Region string
+
+const (
+	RegionUK Region = "UK"
+	RegionUS Region = "US"
+)
+
+type MailClass string
+
+const (
+	MailClassFirst    MailClass = "first"
+	MailClassSecond   MailClass = "second"   // UK only
+	MailClassStandard MailClass = "standard" // US marketing mail
+)
+
+type Colour string
+
+const (
+	ColourBlackAndWhite Colour = "black_and_white"
+	ColourFull          Colour = "colour"
+)
+
+type Envelope string
+
+const (
+	EnvelopeC4       Envelope = "C4"
+	EnvelopeC5       Envelope = "C5"
+	EnvelopeDL       Envelope = "DL"
+	EnvelopeFlat     Envelope = "9x12"
+	EnvelopeNumber10 Envelope = "#10"
+)
+
+type AddOn string
+
+const (
+	AddOnCertified              AddOn = "certified"
+	AddOnCertifiedReturnReceipt AddOn = "certified_return_receipt"
+	AddOnSignedFor              AddOn = "signed_for"
+	AddOnTracked                AddOn = "tracked"
+)
+
+// MailOptions are how a letter is printed and posted. Empty values leave the choice to the Stannp account defaults.
+type MailOptions struct {
+	AddOns   []AddOn   `json:"addOns,omitempty"`
+	Class    MailClass `json:"class,omitempty"`
+	Colour   Colour    `json:"colour,omitempty"`
+	Envelope Envelope  `json:"envelope,omitempty"`
+}
+
+type regionMailOptions struct {
+	addOns    []AddOn
+	classes   []MailClass
+	envelopes []Envelope
+}
+
+var mailOptionsByRegion = map[Region]regionMailOptions{
+	RegionUK: {
+		addOns:    []AddOn{AddOnSignedFor, AddOnTracked},
+		classes:   []MailClass{MailClassFirst, MailClassSecond},
+		envelopes: []Envelope{EnvelopeC4, EnvelopeC5, EnvelopeDL},
+	},
+	RegionUS: {
+		addOns:    []AddOn{AddOnCertified, AddOnCertifiedReturnReceipt},
+		classes:   []MailClass{MailClassFirst, MailClassStandard},
+		envelopes: []Envelope{EnvelopeFlat, EnvelopeNumber10},
+	},
+}
+
+// Validate checks every option is offered in region, and that US certified mail is sent first class
+func (mo *MailOptions) Validate(region Region) *util.APIError {
+	available, ok := mailOptionsByRegion[region]
+	if !ok {
+		return util.BuildError(400, fmt.Sprintf("unknown region [%s]", region))
+	}
+
+	if mo.Class != "" && !contains(available.classes, mo.Class) {
+		return util.BuildError(400, fmt.Sprintf("mail class [%s] is not available in region [%s]", mo.Class, region))
+	}
+
+	if mo.Colour != "" && mo.Colour != ColourBlackAndWhite && mo.Colour != ColourFull {
+		return util.BuildError(400, fmt.Sprintf("unknown colour [%s]", mo.Colour))
+	}
+
+	if mo.Envelope != "" && !contains(available.envelopes, mo.Envelope) {
+		return util.BuildError(400, fmt.Sprintf("envelope [%s] is not available in region [%s]", mo.Envelope, region))
+	}
+
+	for _, addOn := range mo.AddOns {
+		if !contains(available.addOns, addOn) {
+			return util.BuildError(400, fmt.Sprintf("add-on [%s] is not available in region [%s]", addOn, region))
+		}
+
+		certified := addOn == AddOnCertified || addOn == AddOnCertifiedReturnReceipt
+		if certified && mo.Class == MailClassStandard {
+			return util.BuildError(400, fmt.Sprintf("add-on [%s] requires mail class [%s]", addOn, MailClassFirst))
+		}
+	}
+
+	return nil
+}
+
+// FormValues maps the options onto Stannp's class, colour, envelope and addons fields, skipping unset ones
+func (mo *MailOptions) FormValues() map[string]string {
+	values := map[string]string{}
+	if mo.Class != "" {
+		values["class"] = string(mo.Class)
+	}
+
+	if mo.Colour != "" {
+		values["colour"] = string(mo.Colour)
+	}
+
+	if mo.Envelope != "" {
+		values["envelope"] = string(mo.Envelope)
+	}
+
+	if len(mo.AddOns) > 0 {
+		addOns := make([]string, len(mo.AddOns))
+		for i, addOn := range mo.AddOns {
+			addOns[i] = string(addOn)
+		}
+		values["addons"] = strings.Join(addOns, ",")
+	}
+
+	return values
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
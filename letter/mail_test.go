@@ -0,0 +1,89 @@
+package letter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestMailOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       MailOptions
+		region        Region
+		expectedError string
+	}{
+		{
+			name:    "empty options use the account defaults",
+			options: MailOptions{},
+			region:  RegionUS,
+		},
+		{
+			name:    "US first class certified colour",
+			options: MailOptions{AddOns: []AddOn{AddOnCertifiedReturnReceipt}, Class: MailClassFirst, Colour: ColourFull, Envelope: EnvelopeNumber10},
+			region:  RegionUS,
+		},
+		{
+			name:    "UK second class tracked",
+			options: MailOptions{AddOns: []AddOn{AddOnTracked}, Class: MailClassSecond, Colour: ColourBlackAndWhite, Envelope: EnvelopeDL},
+			region:  RegionUK,
+		},
+		{
+			name:          "second class is UK only",
+			options:       MailOptions{Class: MailClassSecond},
+			region:        RegionUS,
+			expectedError: "mail class [second] is not available in region [US]",
+		},
+		{
+			name:          "unknown colour",
+			options:       MailOptions{Colour: "sepia"},
+			region:        RegionUS,
+			expectedError: "unknown colour [sepia]",
+		},
+		{
+			name:          "DL envelopes are UK only",
+			options:       MailOptions{Envelope: EnvelopeDL},
+			region:        RegionUS,
+			expectedError: "envelope [DL] is not available in region [US]",
+		},
+		{
+			name:          "certified is US only",
+			options:       MailOptions{AddOns: []AddOn{AddOnCertified}},
+			region:        RegionUK,
+			expectedError: "add-on [certified] is not available in region [UK]",
+		},
+		{
+			name:          "certified requires first class",
+			options:       MailOptions{AddOns: []AddOn{AddOnCertified}, Class: MailClassStandard},
+			region:        RegionUS,
+			expectedError: "add-on [certified] requires mail class [first]",
+		},
+		{
+			name:          "unknown region",
+			options:       MailOptions{},
+			region:        "FR",
+			expectedError: "unknown region [FR]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := tt.options.Validate(tt.region)
+			if tt.expectedError == "" {
+				assert.True(t, reflect.ValueOf(apiErr).IsNil())
+				return
+			}
+
+			assert.NotNil(t, apiErr)
+			assert.Equal(t, 400, apiErr.Code)
+			assert.Equal(t, tt.expectedError, apiErr.ErrorMessage)
+		})
+	}
+}
+
+func TestMailOptions_FormValues(t *testing.T) {
+	options := MailOptions{AddOns: []AddOn{AddOnCertified, AddOnCertifiedReturnReceipt}, Class: MailClassFirst}
+	assert.True(t, reflect.DeepEqual(map[string]string{"addons": "certified,certified_return_receipt", "class": "first"}, options.FormValues()))
+	assert.Equal(t, 0, len((&MailOptions{}).FormValues()))
+}
@@ -0,0 +1,178 @@
+package letter
+
+import (
+	"fmt"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// QuoteSource says where a Quote's total came from
+type QuoteSource string
+
+const (
+	QuoteSourcePriceTable QuoteSource = "price_table"
+	QuoteSourceStannp     QuoteSource = "stannp"
+)
+
+// QuoteCategory groups the lines of a Quote
+type QuoteCategory string
+
+const (
+	QuoteCategoryAddOn      QuoteCategory = "add_on"
+	QuoteCategoryAdjustment QuoteCategory = "adjustment" // the difference between Stannp's price and the price table's
+	QuoteCategoryPostage    QuoteCategory = "postage"
+	QuoteCategoryPrint      QuoteCategory = "print"
+)
+
+// QuoteLine is one priced part of a letter. Amounts are in the currency's minor unit, i.e. cents or pence.
+type QuoteLine struct {
+	Amount      int64         `json:"amount"`
+	Category    QuoteCategory `json:"category"`
+	Description string        `json:"description"`
+}
+
+// Quote is the expected price of sending a letter, broken down by print, postage and add-ons
+type Quote struct {
+	Currency    string      `json:"currency"`
+	Lines       []QuoteLine `json:"lines"`
+	MailOptions MailOptions `json:"mailOptions"` // the options priced, with the price table's defaults filled in
+	Options     SendOptions `json:"options"`
+	Pages       int         `json:"pages"` // zero when the page count isn't known
+	Sheets      int         `json:"sheets"`
+	Source      QuoteSource `json:"source"`
+	Total       int64       `json:"total"`
+	Warnings    []string    `json:"warnings,omitempty"`
+}
+
+// PriceTable prices letters locally. Amounts are in the currency's minor unit, i.e. cents or pence.
+type PriceTable struct {
+	AddOns        map[AddOn]int64     `json:"addOns"`
+	Currency      string              `json:"currency"`
+	DefaultClass  MailClass           `json:"defaultClass"`  // priced when a letter doesn't choose a class
+	DefaultColour Colour              `json:"defaultColour"` // priced when a letter doesn't choose a colour
+	Envelopes     map[Envelope]int64  `json:"envelopes"`     // surcharge over the standard envelope, missing means none
+	ExtraSheet    map[Colour]int64    `json:"extraSheet"`
+	FirstSheet    map[Colour]int64    `json:"firstSheet"` // printing, the envelope and handling for a one sheet letter
+	International int64               `json:"international"`
+	Postage       map[MailClass]int64 `json:"postage"`
+}
+
+// defaultPriceTables are indicative list prices only. Configure a PriceTable with your account's rates for quotes
+// you can rely on.
+var defaultPriceTables = map[Region]PriceTable{
+	RegionUK: {
+		AddOns:        map[AddOn]int64{AddOnSignedFor: 185, AddOnTracked: 150},
+		Currency:      "GBP",
+		DefaultClass:  MailClassSecond,
+		DefaultColour: ColourFull,
+		Envelopes:     map[Envelope]int64{EnvelopeC4: 60},
+		ExtraSheet:    map[Colour]int64{ColourBlackAndWhite: 8, ColourFull: 12},
+		FirstSheet:    map[Colour]int64{ColourBlackAndWhite: 25, ColourFull: 35},
+		International: 280,
+		Postage:       map[MailClass]int64{MailClassFirst: 110, MailClassSecond: 70},
+	},
+	RegionUS: {
+		AddOns:        map[AddOn]int64{AddOnCertified: 485, AddOnCertifiedReturnReceipt: 870},
+		Currency:      "USD",
+		DefaultClass:  MailClassFirst,
+		DefaultColour: ColourFull,
+		Envelopes:     map[Envelope]int64{EnvelopeFlat: 150},
+		ExtraSheet:    map[Colour]int64{ColourBlackAndWhite: 10, ColourFull: 20},
+		FirstSheet:    map[Colour]int64{ColourBlackAndWhite: 35, ColourFull: 55},
+		International: 165,
+		Postage:       map[MailClass]int64{MailClassFirst: 73, MailClassStandard: 45},
+	},
+}
+
+// DefaultPriceTable returns the indicative price table for region
+func DefaultPriceTable(region Region) (PriceTable, bool) {
+	table, ok := defaultPriceTables[region]
+	return table, ok
+}
+
+// Quote prices a letter of sheets sheets with the given mail options. International letters are charged the
+// international postage rate instead of their class. Options the table has no price for are an error rather than free.
+func (pt *PriceTable) Quote(mail MailOptions, sheets int, international bool) (*Quote, *util.APIError) {
+	if sheets < 1 {
+		return nil, util.BuildError(400, fmt.Sprintf("cannot quote a letter of [%d] sheets", sheets))
+	}
+
+	priced := mail
+	if priced.Class == "" {
+		priced.Class = pt.DefaultClass
+	}
+
+	if priced.Colour == "" {
+		priced.Colour = pt.DefaultColour
+	}
+
+	quote := &Quote{Currency: pt.Currency, MailOptions: priced, Sheets: sheets, Source: QuoteSourcePriceTable}
+
+	firstSheet, ok := pt.FirstSheet[priced.Colour]
+	if !ok {
+		return nil, missingPrice("colour", string(priced.Colour))
+	}
+	quote.add(QuoteCategoryPrint, fmt.Sprintf("%s first sheet", priced.Colour), firstSheet)
+
+	if sheets > 1 {
+		extraSheet, ok := pt.ExtraSheet[priced.Colour]
+		if !ok {
+			return nil, missingPrice("extra sheet colour", string(priced.Colour))
+		}
+		quote.add(QuoteCategoryPrint, fmt.Sprintf("%d extra %s sheets", sheets-1, priced.Colour), extraSheet*int64(sheets-1))
+	}
+
+	if surcharge := pt.Envelopes[priced.Envelope]; surcharge != 0 {
+		quote.add(QuoteCategoryPrint, fmt.Sprintf("%s envelope", priced.Envelope), surcharge)
+	}
+
+	if international {
+		quote.add(QuoteCategoryPostage, "international postage", pt.International)
+	} else {
+		postage, ok := pt.Postage[priced.Class]
+		if !ok {
+			return nil, missingPrice("mail class", string(priced.Class))
+		}
+		quote.add(QuoteCategoryPostage, fmt.Sprintf("%s class postage", priced.Class), postage)
+	}
+
+	for _, addOn := range priced.AddOns {
+		price, ok := pt.AddOns[addOn]
+		if !ok {
+			return nil, missingPrice("add-on", string(addOn))
+		}
+		quote.add(QuoteCategoryAddOn, string(addOn), price)
+	}
+
+	return quote, nil
+}
+
+// Subtotal adds up the lines in category
+func (q *Quote) Subtotal(category QuoteCategory) int64 {
+	var subtotal int64
+	for _, line := range q.Lines {
+		if line.Category == category {
+			subtotal += line.Amount
+		}
+	}
+
+	return subtotal
+}
+
+// Reconcile makes total the quote's total, adding an adjustment line for any difference from the itemised lines
+func (q *Quote) Reconcile(total int64, source QuoteSource) {
+	if difference := total - q.Total; difference != 0 {
+		q.add(QuoteCategoryAdjustment, fmt.Sprintf("difference from %s price", source), difference)
+	}
+
+	q.Source = source
+}
+
+func (q *Quote) add(category QuoteCategory, description string, amount int64) {
+	q.Lines = append(q.Lines, QuoteLine{Amount: amount, Category: category, Description: description})
+	q.Total += amount
+}
+
+func missingPrice(option, value string) *util.APIError {
+	return util.BuildError(400, fmt.Sprintf("price table has no price for %s [%s]", option, value))
+}
@@ -0,0 +1,113 @@
+package letter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestPriceTable_Quote(t *testing.T) {
+	us, _ := DefaultPriceTable(RegionUS)
+	uk, _ := DefaultPriceTable(RegionUK)
+
+	tests := []struct {
+		name            string
+		table           PriceTable
+		mail            MailOptions
+		sheets          int
+		international   bool
+		expectedPrint   int64
+		expectedPostage int64
+		expectedAddOns  int64
+		expectedError   string
+	}{
+		{
+			name:            "US defaults",
+			table:           us,
+			sheets:          1,
+			expectedPrint:   55,
+			expectedPostage: 73,
+		},
+		{
+			name:            "US black and white standard on three sheets",
+			table:           us,
+			mail:            MailOptions{Class: MailClassStandard, Colour: ColourBlackAndWhite},
+			sheets:          3,
+			expectedPrint:   35 + 2*10,
+			expectedPostage: 45,
+		},
+		{
+			name:            "US certified in a flat envelope",
+			table:           us,
+			mail:            MailOptions{AddOns: []AddOn{AddOnCertifiedReturnReceipt}, Envelope: EnvelopeFlat},
+			sheets:          8,
+			expectedPrint:   55 + 7*20 + 150,
+			expectedPostage: 73,
+			expectedAddOns:  870,
+		},
+		{
+			name:            "US international",
+			table:           us,
+			sheets:          2,
+			international:   true,
+			expectedPrint:   55 + 20,
+			expectedPostage: 165,
+		},
+		{
+			name:            "UK tracked first class",
+			table:           uk,
+			mail:            MailOptions{AddOns: []AddOn{AddOnTracked}, Class: MailClassFirst},
+			sheets:          1,
+			expectedPrint:   35,
+			expectedPostage: 110,
+			expectedAddOns:  150,
+		},
+		{
+			name:          "missing price",
+			table:         us,
+			mail:          MailOptions{Class: MailClassSecond},
+			sheets:        1,
+			expectedError: "price table has no price for mail class [second]",
+		},
+		{
+			name:          "no sheets",
+			table:         us,
+			sheets:        0,
+			expectedError: "cannot quote a letter of [0] sheets",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, quoteErr := tt.table.Quote(tt.mail, tt.sheets, tt.international)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, quoteErr.ErrorMessage)
+				return
+			}
+
+			assert.True(t, reflect.ValueOf(quoteErr).IsNil())
+			assert.Equal(t, QuoteSourcePriceTable, quote.Source)
+			assert.Equal(t, tt.table.Currency, quote.Currency)
+			assert.Equal(t, tt.sheets, quote.Sheets)
+			assert.Equal(t, tt.expectedPrint, quote.Subtotal(QuoteCategoryPrint))
+			assert.Equal(t, tt.expectedPostage, quote.Subtotal(QuoteCategoryPostage))
+			assert.Equal(t, tt.expectedAddOns, quote.Subtotal(QuoteCategoryAddOn))
+			assert.Equal(t, tt.expectedPrint+tt.expectedPostage+tt.expectedAddOns, quote.Total)
+		})
+	}
+}
+
+func TestQuote_Reconcile(t *testing.T) {
+	us, _ := DefaultPriceTable(RegionUS)
+	quote, _ := us.Quote(MailOptions{}, 1, false)
+
+	quote.Reconcile(140, QuoteSourceStannp)
+	assert.Equal(t, int64(140), quote.Total)
+	assert.Equal(t, int64(12), quote.Subtotal(QuoteCategoryAdjustment))
+	assert.Equal(t, QuoteSourceStannp, quote.Source)
+
+	lines := len(quote.Lines)
+	quote.Reconcile(140, QuoteSourceStannp)
+	assert.Equal(t, lines, len(quote.Lines))
+}
@@ -0,0 +1,203 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/stannp"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+const DefaultBaseBackoff = 30 * time.Second
+const DefaultLease = 5 * time.Minute
+const DefaultMaxAttempts = 5
+const DefaultMaxBackoff = time.Hour
+const DefaultPollInterval = 10 * time.Second
+const DefaultRetention = 30 * 24 * time.Hour
+
+type DispatcherOption func(*Dispatcher)
+
+// Dispatcher drains a Store through a stannp.Client. Any number of goroutines, or Dispatchers sharing a Store value
+// within one process, may run at once; each entry is leased to a single sender and keeps its idempotency key across
+// retries. Leases only hold within the Store they were claimed from, so separate processes must not each open a
+// FileStore on the same directory.
+type Dispatcher struct {
+	baseBackoff  time.Duration
+	client       stannp.Client
+	lease        time.Duration
+	maxAttempts  int
+	maxBackoff   time.Duration
+	now          func() time.Time
+	pollInterval time.Duration
+	retention    time.Duration
+	store        Store
+}
+
+// WithBackoff sets the delay before the first retry, doubled for each later attempt up to maxBackoff
+func WithBackoff(baseBackoff, maxBackoff time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.baseBackoff = baseBackoff
+		d.maxBackoff = maxBackoff
+	}
+}
+
+// WithLease sets how long an entry stays claimed before another dispatcher may assume its sender died. It must be
+// comfortably longer than a SendLetter call.
+func WithLease(lease time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.lease = lease
+	}
+}
+
+func WithMaxAttempts(maxAttempts int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.maxAttempts = maxAttempts
+	}
+}
+
+func WithPollInterval(pollInterval time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.pollInterval = pollInterval
+	}
+}
+
+// WithRetention sets how long Run keeps entries after they were sent or failed before pruning them from the store.
+// Defaults to DefaultRetention; zero keeps them forever.
+func WithRetention(retention time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.retention = retention
+	}
+}
+
+func NewDispatcher(store Store, client stannp.Client, opts ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{
+		baseBackoff:  DefaultBaseBackoff,
+		client:       client,
+		lease:        DefaultLease,
+		maxAttempts:  DefaultMaxAttempts,
+		maxBackoff:   DefaultMaxBackoff,
+		now:          func() time.Time { return time.Now().UTC() },
+		pollInterval: DefaultPollInterval,
+		retention:    DefaultRetention,
+		store:        store,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Drain sends entries until none are ready, returning how many it settled as sent or failed
+func (d *Dispatcher) Drain(ctx context.Context) (int, *util.APIError) {
+	settled := 0
+	for {
+		if ctx.Err() != nil {
+			return settled, util.BuildError(500, ctx.Err().Error())
+		}
+
+		entry, claimErr := d.store.Claim(ctx, d.now(), d.lease)
+		if claimErr != nil {
+			return settled, claimErr
+		}
+
+		if entry == nil {
+			return settled, nil
+		}
+
+		dispatchErr := d.dispatch(ctx, entry)
+		// a send that outlived its lease is left to whoever claimed the entry next, which reuses the idempotency key
+		if dispatchErr != nil && dispatchErr.Type == LeaseLostErrorType {
+			continue
+		}
+
+		if dispatchErr != nil {
+			return settled, dispatchErr
+		}
+
+		if entry.Status == StatusSent || entry.Status == StatusFailed {
+			settled++
+		}
+	}
+}
+
+// Prune removes entries settled longer ago than the retention, returning how many it removed
+func (d *Dispatcher) Prune(ctx context.Context) (int, *util.APIError) {
+	if d.retention <= 0 {
+		return 0, nil
+	}
+
+	return d.store.Prune(ctx, d.now().Add(-d.retention))
+}
+
+// Run drains and prunes the store every poll interval until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) *util.APIError {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, drainErr := d.Drain(ctx); drainErr != nil && ctx.Err() == nil {
+			return drainErr
+		}
+
+		if _, pruneErr := d.Prune(ctx); pruneErr != nil && ctx.Err() == nil {
+			return pruneErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, entry *Entry) *util.APIError {
+	entry.Attempts++
+	res, sendErr := d.client.SendLetter(ctx, &entry.Request)
+
+	now := d.now()
+	entry.LeaseExpires = time.Time{}
+	entry.Updated = now
+
+	switch {
+	case sendErr == nil && res.Success:
+		entry.Cost = res.Data.Cost
+		entry.LastError = nil
+		entry.LetterID = res.Data.LetterID().String()
+		entry.Status = StatusSent
+	case sendErr == nil:
+		entry.LastError = util.BuildError(502, fmt.Sprintf("stannp did not report success for outbox entry [%s]", entry.ID))
+		entry.Status = StatusFailed
+	case retryable(sendErr) && entry.Attempts < d.maxAttempts:
+		entry.LastError = sendErr
+		entry.NextAttempt = now.Add(d.backoff(entry.Attempts))
+		entry.Status = StatusPending
+	default:
+		entry.LastError = sendErr
+		entry.Status = StatusFailed
+	}
+
+	return d.store.Update(ctx, entry)
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	backoff := d.baseBackoff
+	for i := 1; i < attempts && backoff < d.maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > d.maxBackoff {
+		return d.maxBackoff
+	}
+
+	return backoff
+}
+
+// retryable treats server errors, timeouts and rate limiting as transient and every other client error as permanent
+func retryable(apiErr *util.APIError) bool {
+	return apiErr.Code >= http.StatusInternalServerError || apiErr.Code == http.StatusRequestTimeout || apiErr.Code == http.StatusTooManyRequests
+}
@@ -0,0 +1,184 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+const EntryExtension = ".json"
+
+// FileStore keeps one JSON file per entry in a directory so the outbox survives process restarts. Entries are loaded
+// once on open and every change is written with util.WriteFileAtomic before it is acknowledged. Because nothing is
+// read back after opening, a directory must only be opened by one FileStore at a time: a second FileStore, in this
+// process or another, would claim and send the same entries again.
+type FileStore struct {
+	dir    string
+	memory *MemoryStore
+	mu     sync.Mutex
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore opens the outbox in dir, creating it if needed and loading any entries left by a previous process
+func NewFileStore(dir string) (*FileStore, *util.APIError) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, util.BuildError(500, err.Error())
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, util.BuildError(500, err.Error())
+	}
+
+	var entries []*Entry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), EntryExtension) {
+			continue
+		}
+
+		contents, readErr := os.ReadFile(filepath.Join(dir, file.Name()))
+		if readErr != nil {
+			return nil, util.BuildError(500, readErr.Error())
+		}
+
+		var entry Entry
+		if jsonErr := json.Unmarshal(contents, &entry); jsonErr != nil {
+			return nil, util.BuildError(500, fmt.Sprintf("error unmarshalling outbox entry [%s] with err [%+v]", file.Name(), jsonErr))
+		}
+		entries = append(entries, &entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Created.Before(entries[j].Created)
+	})
+
+	memory := NewMemoryStore()
+	for _, entry := range entries {
+		memory.put(entry)
+	}
+
+	return &FileStore{dir: dir, memory: memory}, nil
+}
+
+func (fs *FileStore) Claim(_ context.Context, now time.Time, lease time.Duration) (*Entry, *util.APIError) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.memory.mu.Lock()
+	defer fs.memory.mu.Unlock()
+
+	next := fs.memory.next(now)
+	if next == nil {
+		return nil, nil
+	}
+
+	// only apply the claim in memory once it is on disk
+	claimed := *next
+	leaseEntry(&claimed, now, lease)
+	if writeErr := fs.write(&claimed); writeErr != nil {
+		return nil, writeErr
+	}
+
+	*next = claimed
+	return &claimed, nil
+}
+
+func (fs *FileStore) Enqueue(_ context.Context, req *letter.SendReq) (*Entry, *util.APIError) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	entry := newEntry(req, time.Now().UTC())
+	if writeErr := fs.write(entry); writeErr != nil {
+		return nil, writeErr
+	}
+
+	fs.memory.mu.Lock()
+	fs.memory.put(entry)
+	fs.memory.mu.Unlock()
+
+	copied := *entry
+	return &copied, nil
+}
+
+func (fs *FileStore) Get(ctx context.Context, id string) (*Entry, *util.APIError) {
+	return fs.memory.Get(ctx, id)
+}
+
+func (fs *FileStore) List(ctx context.Context, status Status) ([]Entry, *util.APIError) {
+	return fs.memory.List(ctx, status)
+}
+
+// Prune deletes the files of entries settled before settledBefore, forgetting each entry once its file is gone
+func (fs *FileStore) Prune(_ context.Context, settledBefore time.Time) (int, *util.APIError) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.memory.mu.Lock()
+	defer fs.memory.mu.Unlock()
+
+	// entries whose files couldn't be deleted are kept so a later Prune can retry them
+	removed := map[string]bool{}
+	var removeErr *util.APIError
+	for _, id := range fs.memory.order {
+		if !prunable(fs.memory.entries[id], settledBefore) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(fs.dir, id+EntryExtension)); err != nil && !os.IsNotExist(err) {
+			removeErr = util.BuildError(500, err.Error())
+			break
+		}
+		removed[id] = true
+	}
+
+	return fs.memory.forget(func(entry *Entry) bool {
+		return removed[entry.ID]
+	}), removeErr
+}
+
+func (fs *FileStore) Update(_ context.Context, entry *Entry) *util.APIError {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.memory.mu.Lock()
+	defer fs.memory.mu.Unlock()
+
+	stored, ok := fs.memory.entries[entry.ID]
+	if !ok {
+		return notFound(entry.ID)
+	}
+
+	// only apply the update in memory once it is on disk
+	settled, leaseErr := settleEntry(stored, entry)
+	if leaseErr != nil {
+		return leaseErr
+	}
+
+	if writeErr := fs.write(settled); writeErr != nil {
+		return writeErr
+	}
+
+	fs.memory.entries[entry.ID] = settled
+	return nil
+}
+
+func (fs *FileStore) write(entry *Entry) *util.APIError {
+	contents, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return util.BuildError(500, err.Error())
+	}
+
+	_, writeErr := util.WriteFileAtomic(filepath.Join(fs.dir, entry.ID+EntryExtension), bytes.NewReader(contents))
+	return writeErr
+}
@@ -0,0 +1,151 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+type MemoryStore struct {
+	entries map[string]*Entry
+	mu      sync.Mutex
+	order   []string
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]*Entry{}}
+}
+
+func (ms *MemoryStore) Claim(_ context.Context, now time.Time, lease time.Duration) (*Entry, *util.APIError) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return ms.claim(now, lease), nil
+}
+
+func (ms *MemoryStore) Enqueue(_ context.Context, req *letter.SendReq) (*Entry, *util.APIError) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	entry := newEntry(req, time.Now().UTC())
+	ms.put(entry)
+
+	copied := *entry
+	return &copied, nil
+}
+
+func (ms *MemoryStore) Get(_ context.Context, id string) (*Entry, *util.APIError) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	entry, ok := ms.entries[id]
+	if !ok {
+		return nil, notFound(id)
+	}
+
+	copied := *entry
+	return &copied, nil
+}
+
+func (ms *MemoryStore) List(_ context.Context, status Status) ([]Entry, *util.APIError) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	entries := []Entry{}
+	for _, id := range ms.order {
+		entry := ms.entries[id]
+		if status == "" || entry.Status == status {
+			entries = append(entries, *entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func (ms *MemoryStore) Prune(_ context.Context, settledBefore time.Time) (int, *util.APIError) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return ms.forget(func(entry *Entry) bool {
+		return prunable(entry, settledBefore)
+	}), nil
+}
+
+func (ms *MemoryStore) Update(_ context.Context, entry *Entry) *util.APIError {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return ms.update(entry)
+}
+
+func (ms *MemoryStore) claim(now time.Time, lease time.Duration) *Entry {
+	entry := ms.next(now)
+	if entry == nil {
+		return nil
+	}
+
+	leaseEntry(entry, now, lease)
+
+	copied := *entry
+	return &copied
+}
+
+// next returns the oldest claimable entry without claiming it
+func (ms *MemoryStore) next(now time.Time) *Entry {
+	for _, id := range ms.order {
+		if entry := ms.entries[id]; claimable(entry, now) {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// forget removes the entries remove reports true for and returns how many there were
+func (ms *MemoryStore) forget(remove func(*Entry) bool) int {
+	order := ms.order[:0]
+	for _, id := range ms.order {
+		if remove(ms.entries[id]) {
+			delete(ms.entries, id)
+			continue
+		}
+		order = append(order, id)
+	}
+
+	forgotten := len(ms.order) - len(order)
+	ms.order = order
+	return forgotten
+}
+
+func (ms *MemoryStore) put(entry *Entry) {
+	if _, ok := ms.entries[entry.ID]; !ok {
+		ms.order = append(ms.order, entry.ID)
+	}
+
+	ms.entries[entry.ID] = entry
+}
+
+func (ms *MemoryStore) update(entry *Entry) *util.APIError {
+	stored, ok := ms.entries[entry.ID]
+	if !ok {
+		return notFound(entry.ID)
+	}
+
+	settled, leaseErr := settleEntry(stored, entry)
+	if leaseErr != nil {
+		return leaseErr
+	}
+
+	ms.entries[entry.ID] = settled
+	return nil
+}
+
+func notFound(id string) *util.APIError {
+	return util.BuildError(404, fmt.Sprintf("outbox entry [%s] not found", id))
+}
@@ -0,0 +1,106 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+const LeaseLostErrorType = "outbox_lease_lost"
+
+type Status string
+
+const (
+	StatusFailed  Status = "failed"
+	StatusPending Status = "pending"
+	StatusSending Status = "sending"
+	StatusSent    Status = "sent"
+)
+
+// Entry is a letter waiting to be, or that has been, sent through the outbox
+type Entry struct {
+	Attempts     int            `json:"attempts"`
+	Cost         string         `json:"cost,omitempty"`
+	Created      time.Time      `json:"created"`
+	ID           string         `json:"id"`
+	LastError    *util.APIError `json:"lastError,omitempty"`
+	LeaseExpires time.Time      `json:"leaseExpires,omitempty"`
+	LeaseToken   string         `json:"leaseToken,omitempty"` // identifies the Claim that leased the entry
+	LetterID     string         `json:"letterId,omitempty"`
+	NextAttempt  time.Time      `json:"nextAttempt"`
+	Request      letter.SendReq `json:"request"`
+	Status       Status         `json:"status"`
+	Updated      time.Time      `json:"updated"`
+}
+
+// Store persists outbox entries. Implementations must be safe for concurrent use and Claim must never hand the same
+// entry to two callers while its lease is live.
+type Store interface {
+	// Claim leases the oldest entry that is pending and due, or whose previous lease expired without being settled,
+	// marking it as sending. It returns nil when nothing is ready.
+	Claim(ctx context.Context, now time.Time, lease time.Duration) (*Entry, *util.APIError)
+	Enqueue(ctx context.Context, req *letter.SendReq) (*Entry, *util.APIError)
+	Get(ctx context.Context, id string) (*Entry, *util.APIError)
+	// List returns entries with the given status, or every entry when status is empty, oldest first
+	List(ctx context.Context, status Status) ([]Entry, *util.APIError)
+	// Prune forgets entries that were sent or failed before settledBefore, returning how many it removed
+	Prune(ctx context.Context, settledBefore time.Time) (int, *util.APIError)
+	// Update saves entry, ending its lease. It fails with LeaseLostErrorType unless entry carries the LeaseToken of the
+	// latest Claim, so a sender whose lease expired can't overwrite the result of the one that claimed it next.
+	Update(ctx context.Context, entry *Entry) *util.APIError
+}
+
+// newEntry gives req a stable idempotency key so a retry after a crash can never mail the letter twice
+func newEntry(req *letter.SendReq, now time.Time) *Entry {
+	entry := &Entry{
+		Created:     now,
+		ID:          util.RandomString(20),
+		NextAttempt: now,
+		Request:     *req,
+		Status:      StatusPending,
+		Updated:     now,
+	}
+
+	if entry.Request.IdempotenceyKey == "" {
+		entry.Request.IdempotenceyKey = entry.ID
+	}
+
+	return entry
+}
+
+func claimable(entry *Entry, now time.Time) bool {
+	switch entry.Status {
+	case StatusPending:
+		return !entry.NextAttempt.After(now)
+	case StatusSending:
+		return entry.LeaseExpires.Before(now)
+	default:
+		return false
+	}
+}
+
+// prunable is true for an entry that was sent or failed before settledBefore
+func prunable(entry *Entry, settledBefore time.Time) bool {
+	return (entry.Status == StatusSent || entry.Status == StatusFailed) && entry.Updated.Before(settledBefore)
+}
+
+func leaseEntry(entry *Entry, now time.Time, lease time.Duration) {
+	entry.LeaseExpires = now.Add(lease)
+	entry.LeaseToken = util.RandomString(20)
+	entry.Status = StatusSending
+	entry.Updated = now
+}
+
+// settleEntry is entry as Update saves it, once its lease has been checked against the stored entry
+func settleEntry(stored, entry *Entry) (*Entry, *util.APIError) {
+	if entry.LeaseToken != stored.LeaseToken {
+		return nil, util.BuildTypedError(409, LeaseLostErrorType, fmt.Sprintf("outbox entry [%s] has been claimed again since it was leased", entry.ID))
+	}
+
+	settled := *entry
+	settled.LeaseToken = ""
+	return &settled, nil
+}
@@ -0,0 +1,243 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/stannp"
+	"github.com/copilotiq/stannp-client-golang/util"
+	"github.com/jgroeneveld/trial/assert"
+)
+
+// recordingClient fails each idempotency key with the queued errors before succeeding
+type recordingClient struct {
+	*stannp.MockClient
+	failures map[string][]*util.APIError
+	mu       sync.Mutex
+	sends    map[string]int
+}
+
+func newRecordingClient() *recordingClient {
+	return &recordingClient{
+		MockClient: stannp.NewMockClient(),
+		failures:   map[string][]*util.APIError{},
+		sends:      map[string]int{},
+	}
+}
+
+func (rc *recordingClient) SendLetter(_ context.Context, req *letter.SendReq) (*letter.SendRes, *util.APIError) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.sends[req.IdempotenceyKey]++
+	if failures := rc.failures[req.IdempotenceyKey]; len(failures) > 0 {
+		rc.failures[req.IdempotenceyKey] = failures[1:]
+		return nil, failures[0]
+	}
+
+	return &letter.SendRes{
+		Data:    letter.Data{Cost: "0.84", ID: json.Number("1" + req.IdempotenceyKey[:3]), Status: "received"},
+		Success: true,
+	}, nil
+}
+
+func TestDispatcherRetriesWithTheSameIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	client := newRecordingClient()
+
+	transient, _ := store.Enqueue(ctx, &letter.SendReq{IdempotenceyKey: "transient", Template: "42"})
+	permanent, _ := store.Enqueue(ctx, &letter.SendReq{IdempotenceyKey: "permanent", Template: "42"})
+	client.failures["transient"] = []*util.APIError{util.BuildError(503, "unavailable"), util.BuildError(429, "slow down")}
+	client.failures["permanent"] = []*util.APIError{util.BuildError(400, "bad template")}
+
+	dispatcher := NewDispatcher(store, client, WithBackoff(time.Millisecond, time.Millisecond))
+
+	for i := 0; i < 10; i++ {
+		_, drainErr := dispatcher.Drain(ctx)
+		assert.True(t, reflect.ValueOf(drainErr).IsNil())
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	sent, _ := store.Get(ctx, transient.ID)
+	assert.Equal(t, StatusSent, sent.Status)
+	assert.Equal(t, 3, sent.Attempts)
+	assert.Equal(t, "0.84", sent.Cost)
+	assert.Equal(t, "1tra", sent.LetterID)
+	assert.True(t, reflect.ValueOf(sent.LastError).IsNil())
+	assert.Equal(t, 3, client.sends["transient"])
+
+	failed, _ := store.Get(ctx, permanent.ID)
+	assert.Equal(t, StatusFailed, failed.Status)
+	assert.Equal(t, 1, failed.Attempts)
+	assert.Equal(t, "bad template", failed.LastError.ErrorMessage)
+}
+
+func TestDispatcherGivesUpAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	client := newRecordingClient()
+
+	entry, _ := store.Enqueue(ctx, &letter.SendReq{Template: "42"})
+	assert.Equal(t, entry.ID, entry.Request.IdempotenceyKey)
+	client.failures[entry.ID] = []*util.APIError{util.BuildError(500, "1"), util.BuildError(500, "2"), util.BuildError(500, "3")}
+
+	dispatcher := NewDispatcher(store, client, WithBackoff(0, 0), WithMaxAttempts(2))
+	settled, drainErr := dispatcher.Drain(ctx)
+	assert.True(t, reflect.ValueOf(drainErr).IsNil())
+	assert.Equal(t, 1, settled)
+
+	failed, _ := store.Get(ctx, entry.ID)
+	assert.Equal(t, StatusFailed, failed.Status)
+	assert.Equal(t, 2, failed.Attempts)
+	assert.Equal(t, "2", failed.LastError.ErrorMessage)
+}
+
+func TestDispatcherConcurrentDrainsSendEachEntryOnce(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	client := newRecordingClient()
+
+	for i := 0; i < 50; i++ {
+		_, enqueueErr := store.Enqueue(ctx, &letter.SendReq{Template: "42"})
+		assert.True(t, reflect.ValueOf(enqueueErr).IsNil())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = NewDispatcher(store, client).Drain(ctx)
+		}()
+	}
+	wg.Wait()
+
+	sent, _ := store.List(ctx, StatusSent)
+	assert.Equal(t, 50, len(sent))
+	assert.Equal(t, 50, len(client.sends))
+	for key, count := range client.sends {
+		assert.Equal(t, 1, count, key)
+	}
+}
+
+func TestFileStoreResumesAfterCrash(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	store, openErr := NewFileStore(dir)
+	assert.True(t, reflect.ValueOf(openErr).IsNil())
+
+	first, _ := store.Enqueue(ctx, &letter.SendReq{Template: "first"})
+	second, _ := store.Enqueue(ctx, &letter.SendReq{Template: "second"})
+
+	// the process claims the first entry and dies before recording the result
+	claimed, claimErr := store.Claim(ctx, time.Now().UTC(), time.Millisecond)
+	assert.True(t, reflect.ValueOf(claimErr).IsNil())
+	assert.Equal(t, first.ID, claimed.ID)
+	time.Sleep(2 * time.Millisecond)
+
+	reopened, openErr := NewFileStore(dir)
+	assert.True(t, reflect.ValueOf(openErr).IsNil())
+
+	pending, _ := reopened.List(ctx, "")
+	assert.Equal(t, 2, len(pending))
+	assert.Equal(t, first.ID, pending[0].ID)
+	assert.Equal(t, StatusSending, pending[0].Status)
+	assert.Equal(t, second.ID, pending[1].ID)
+
+	client := newRecordingClient()
+	settled, drainErr := NewDispatcher(reopened, client).Drain(ctx)
+	assert.True(t, reflect.ValueOf(drainErr).IsNil())
+	assert.Equal(t, 2, settled)
+	assert.Equal(t, 1, client.sends[first.ID])
+
+	// the results survive another restart
+	reopened, _ = NewFileStore(dir)
+	sent, _ := reopened.List(ctx, StatusSent)
+	assert.Equal(t, 2, len(sent))
+}
+
+func TestUpdateRejectsAnExpiredLease(t *testing.T) {
+	fileStore, openErr := NewFileStore(t.TempDir())
+	assert.True(t, reflect.ValueOf(openErr).IsNil())
+
+	for name, store := range map[string]Store{"file": fileStore, "memory": NewMemoryStore()} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			_, _ = store.Enqueue(ctx, &letter.SendReq{Template: "42"})
+
+			now := time.Now().UTC()
+			slow, _ := store.Claim(ctx, now, time.Millisecond)
+			// the first lease runs out and another sender claims the entry
+			fast, _ := store.Claim(ctx, now.Add(time.Second), time.Minute)
+			assert.Equal(t, slow.ID, fast.ID)
+
+			fast.Status = StatusSent
+			assert.True(t, reflect.ValueOf(store.Update(ctx, fast)).IsNil())
+
+			slow.Status = StatusFailed
+			updateErr := store.Update(ctx, slow)
+			assert.Equal(t, 409, updateErr.Code)
+			assert.Equal(t, LeaseLostErrorType, updateErr.Type)
+
+			entry, _ := store.Get(ctx, fast.ID)
+			assert.Equal(t, StatusSent, entry.Status)
+			assert.Equal(t, "", entry.LeaseToken)
+		})
+	}
+}
+
+func TestPruneForgetsSettledEntries(t *testing.T) {
+	dir := t.TempDir()
+	fileStore, openErr := NewFileStore(dir)
+	assert.True(t, reflect.ValueOf(openErr).IsNil())
+
+	for name, store := range map[string]Store{"file": fileStore, "memory": NewMemoryStore()} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			sent, _ := store.Enqueue(ctx, &letter.SendReq{Template: "sent"})
+			failed, _ := store.Enqueue(ctx, &letter.SendReq{Template: "failed"})
+			retrying, _ := store.Enqueue(ctx, &letter.SendReq{Template: "retrying"})
+
+			client := newRecordingClient()
+			client.failures[failed.ID] = []*util.APIError{util.BuildError(400, "bad request")}
+			client.failures[retrying.ID] = []*util.APIError{util.BuildError(503, "unavailable")}
+
+			dispatcher := NewDispatcher(store, client, WithRetention(time.Hour))
+			settled, drainErr := dispatcher.Drain(ctx)
+			assert.True(t, reflect.ValueOf(drainErr).IsNil())
+			assert.Equal(t, 2, settled)
+
+			// nothing has been settled for longer than the retention yet
+			dispatcher.now = func() time.Time { return time.Now().UTC().Add(30 * time.Minute) }
+			pruned, pruneErr := dispatcher.Prune(ctx)
+			assert.True(t, reflect.ValueOf(pruneErr).IsNil())
+			assert.Equal(t, 0, pruned)
+
+			// the entry waiting to retry is kept however old it is
+			dispatcher.now = func() time.Time { return time.Now().UTC().Add(2 * time.Hour) }
+			pruned, pruneErr = dispatcher.Prune(ctx)
+			assert.True(t, reflect.ValueOf(pruneErr).IsNil())
+			assert.Equal(t, 2, pruned)
+
+			remaining, _ := store.List(ctx, "")
+			assert.Equal(t, 1, len(remaining))
+			assert.Equal(t, retrying.ID, remaining[0].ID)
+
+			_, getErr := store.Get(ctx, sent.ID)
+			assert.Equal(t, 404, getErr.Code)
+		})
+	}
+
+	// pruned entries stay gone after a restart
+	reopened, openErr := NewFileStore(dir)
+	assert.True(t, reflect.ValueOf(openErr).IsNil())
+	entries, _ := reopened.List(context.Background(), "")
+	assert.Equal(t, 1, len(entries))
+}
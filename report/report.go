@@ -0,0 +1,109 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+const URL = "reporting"
+const ListURL = "list"
+
+// DateFormat is how ListReq's dates are sent to Stannp
+const DateFormat = "2006-01-02"
+
+// ListReq selects the letters created between Start and End, both whole days and inclusive, optionally only those with
+// Status
+type ListReq struct {
+	End    time.Time           `json:"end"`
+	Start  time.Time           `json:"start"`
+	Status letter.LetterStatus `json:"status,omitempty"`
+}
+
+type ListRes struct {
+	Data    []letter.Data `json:"data"`
+	Success bool          `json:"success"`
+}
+
+// StatusSummary totals the letters with one status
+type StatusSummary struct {
+	Cost  letter.Money `json:"cost"`
+	Count int          `json:"count"`
+}
+
+// Summary totals the letters in a ListReq's range. Letters whose cost Stannp sent in a form that can't be parsed are
+// counted but not costed, and reported in Unpriced.
+type Summary struct {
+	ByStatus map[letter.LetterStatus]StatusSummary `json:"byStatus"`
+	Cost     letter.Money                          `json:"cost"`
+	Count    int                                   `json:"count"`
+	End      time.Time                             `json:"end"`
+	Start    time.Time                             `json:"start"`
+	Unpriced []letter.LetterID                     `json:"unpriced,omitempty"`
+}
+
+// Month is a ListReq for every letter created in a calendar month, e.g. for reconciling a monthly invoice
+func Month(year int, month time.Month) *ListReq {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return &ListReq{
+		End:   start.AddDate(0, 1, -1),
+		Start: start,
+	}
+}
+
+// Validate checks the range is set and doesn't run backwards
+func (r *ListReq) Validate() *util.APIError {
+	if r.Start.IsZero() || r.End.IsZero() {
+		return util.BuildError(400, "report start and end dates must both be set")
+	}
+
+	if r.End.Format(DateFormat) < r.Start.Format(DateFormat) {
+		return util.BuildError(400, fmt.Sprintf("report end [%s] is before its start [%s]", r.End.Format(DateFormat), r.Start.Format(DateFormat)))
+	}
+
+	return nil
+}
+
+// Summarize totals letters by status in currency, which Stannp doesn't send; see letter.Region.Currency
+func (r *ListReq) Summarize(letters []letter.Data, currency string) *Summary {
+	summary := &Summary{
+		ByStatus: map[letter.LetterStatus]StatusSummary{},
+		Cost:     letter.Money{Currency: currency},
+		End:      r.End,
+		Start:    r.Start,
+	}
+
+	for i := range letters {
+		status := letters[i].LetterStatus()
+		statusSummary := summary.ByStatus[status]
+		statusSummary.Cost.Currency = currency
+		statusSummary.Count++
+		summary.Count++
+
+		cost, costErr := letters[i].CostAmount(currency)
+		if costErr != nil {
+			summary.Unpriced = append(summary.Unpriced, letters[i].LetterID())
+		} else {
+			statusSummary.Cost.Amount += cost.Amount
+			summary.Cost.Amount += cost.Amount
+		}
+
+		summary.ByStatus[status] = statusSummary
+	}
+
+	return summary
+}
+
+// Statuses lists the statuses in the summary in name order
+func (s *Summary) Statuses() []letter.LetterStatus {
+	statuses := make([]letter.LetterStatus, 0, len(s.ByStatus))
+	for status := range s.ByStatus {
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i] < statuses[j] })
+	return statuses
+}
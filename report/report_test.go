@@ -0,0 +1,46 @@
+package report
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestMonth(t *testing.T) {
+	req := Month(2024, time.February)
+	assert.Equal(t, "2024-02-01", req.Start.Format(DateFormat))
+	assert.Equal(t, "2024-02-29", req.End.Format(DateFormat))
+	assert.True(t, reflect.ValueOf(req.Validate()).IsNil())
+}
+
+func TestListReq_Validate(t *testing.T) {
+	day := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 400, (&ListReq{Start: day}).Validate().Code)
+	assert.Equal(t, 400, (&ListReq{End: day, Start: day.AddDate(0, 0, 1)}).Validate().Code)
+	// a single day is a valid range whatever the times of day
+	assert.True(t, reflect.ValueOf((&ListReq{End: day.Add(-time.Hour), Start: day}).Validate()).IsNil())
+}
+
+func TestListReq_Summarize(t *testing.T) {
+	var letters []letter.Data
+	assert.Nil(t, json.Unmarshal([]byte(`[
+		{"id": 1, "cost": "0.84", "status": "delivered"},
+		{"id": 2, "cost": 1.2, "status": "delivered"},
+		{"id": 3, "cost": "0.84", "status": "returned"},
+		{"id": 4, "cost": "n/a", "status": "producing"}
+	]`), &letters))
+
+	summary := Month(2024, time.March).Summarize(letters, "USD")
+
+	assert.Equal(t, 4, summary.Count)
+	assert.Equal(t, letter.Money{Amount: 288, Currency: "USD"}, summary.Cost)
+	assert.Equal(t, StatusSummary{Cost: letter.Money{Amount: 204, Currency: "USD"}, Count: 2}, summary.ByStatus[letter.LetterStatusDelivered])
+	assert.Equal(t, 1, summary.ByStatus[letter.LetterStatusProducing].Count)
+	assert.True(t, reflect.DeepEqual([]letter.LetterID{"4"}, summary.Unpriced))
+	assert.True(t, reflect.DeepEqual([]letter.LetterStatus{letter.LetterStatusDelivered, letter.LetterStatusProducing, letter.LetterStatusReturned}, summary.Statuses()))
+}
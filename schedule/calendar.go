@@ -0,0 +1,199 @@
+package schedule
+
+import "time"
+
+// Calendar decides which days letters may be handed over for posting
+type Calendar interface {
+	IsBusinessDay(day time.Time) bool
+}
+
+// CalendarFunc adapts a plain function to a Calendar
+type CalendarFunc func(day time.Time) bool
+
+func (f CalendarFunc) IsBusinessDay(day time.Time) bool {
+	return f(day)
+}
+
+// Weekdays treats every Monday to Friday as a business day
+var Weekdays Calendar = CalendarFunc(isWeekday)
+
+// USFederal skips weekends and US federal holidays, using the observed date when a holiday falls on a weekend
+var USFederal Calendar = CalendarFunc(func(day time.Time) bool {
+	return isWeekday(day) && !IsUSFederalHoliday(day)
+})
+
+// UKBankHolidays skips weekends and England & Wales bank holidays, including substitute days
+var UKBankHolidays Calendar = CalendarFunc(func(day time.Time) bool {
+	return isWeekday(day) && !IsUKBankHoliday(day)
+})
+
+// NextBusinessDay returns t when it falls on a business day, otherwise the same clock time on the next one
+func NextBusinessDay(calendar Calendar, t time.Time) time.Time {
+	for !calendar.IsBusinessDay(t) {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return t
+}
+
+// AddBusinessDays moves t forward by n business days, keeping its clock time
+func AddBusinessDays(calendar Calendar, t time.Time, n int) time.Time {
+	for n > 0 {
+		t = t.AddDate(0, 0, 1)
+		if calendar.IsBusinessDay(t) {
+			n--
+		}
+	}
+
+	return t
+}
+
+// IsUSFederalHoliday reports whether day is a US federal holiday as observed by federal offices
+func IsUSFederalHoliday(day time.Time) bool {
+	year := day.Year()
+
+	// New Year's Day falling on a Saturday is observed on the 31st of December of the year before
+	for _, holiday := range append(usFederalHolidays(year), usFederalHolidays(year+1)...) {
+		if sameDay(holiday, day) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsUKBankHoliday reports whether day is a bank holiday in England & Wales
+func IsUKBankHoliday(day time.Time) bool {
+	for _, holiday := range ukBankHolidays(day.Year()) {
+		if sameDay(holiday, day) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func usFederalHolidays(year int) []time.Time {
+	holidays := []time.Time{
+		usObserved(date(year, time.January, 1)),
+		nthWeekday(year, time.January, time.Monday, 3),  // Martin Luther King Jr. Day
+		nthWeekday(year, time.February, time.Monday, 3), // Washington's Birthday
+		lastWeekday(year, time.May, time.Monday),        // Memorial Day
+		usObserved(date(year, time.July, 4)),
+		nthWeekday(year, time.September, time.Monday, 1), // Labor Day
+		nthWeekday(year, time.October, time.Monday, 2),   // Columbus Day
+		usObserved(date(year, time.November, 11)),
+		nthWeekday(year, time.November, time.Thursday, 4), // Thanksgiving
+		usObserved(date(year, time.December, 25)),
+	}
+
+	if year >= 2021 {
+		holidays = append(holidays, usObserved(date(year, time.June, 19))) // Juneteenth
+	}
+
+	return holidays
+}
+
+func ukBankHolidays(year int) []time.Time {
+	easter := easterSunday(year)
+	earlyMay := nthWeekday(year, time.May, time.Monday, 1)
+	spring := lastWeekday(year, time.May, time.Monday)
+
+	// one-off moves for national celebrations
+	switch year {
+	case 2020:
+		earlyMay = date(2020, time.May, 8)
+	case 2022:
+		spring = date(2022, time.June, 2)
+	}
+
+	holidays := []time.Time{
+		ukSubstitute(date(year, time.January, 1), nil),
+		easter.AddDate(0, 0, -2), // Good Friday
+		easter.AddDate(0, 0, 1),  // Easter Monday
+		earlyMay,
+		spring,
+		lastWeekday(year, time.August, time.Monday), // Summer bank holiday
+	}
+
+	christmas := ukSubstitute(date(year, time.December, 25), nil)
+	boxingDay := ukSubstitute(date(year, time.December, 26), &christmas)
+	holidays = append(holidays, christmas, boxingDay)
+
+	// additional one-off bank holidays
+	switch year {
+	case 2022:
+		holidays = append(holidays, date(2022, time.June, 3), date(2022, time.September, 19))
+	case 2023:
+		holidays = append(holidays, date(2023, time.May, 8))
+	}
+
+	return holidays
+}
+
+// usObserved moves a Saturday holiday to Friday and a Sunday holiday to Monday
+func usObserved(holiday time.Time) time.Time {
+	switch holiday.Weekday() {
+	case time.Saturday:
+		return holiday.AddDate(0, 0, -1)
+	case time.Sunday:
+		return holiday.AddDate(0, 0, 1)
+	default:
+		return holiday
+	}
+}
+
+// ukSubstitute moves a weekend holiday to the next weekday that isn't already taken by taken
+func ukSubstitute(holiday time.Time, taken *time.Time) time.Time {
+	for !isWeekday(holiday) || (taken != nil && sameDay(holiday, *taken)) {
+		holiday = holiday.AddDate(0, 0, 1)
+	}
+
+	return holiday
+}
+
+// easterSunday uses the anonymous Gregorian algorithm
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return date(year, time.Month(month), day)
+}
+
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	first := date(year, month, 1)
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+7*(n-1))
+}
+
+func lastWeekday(year int, month time.Month, weekday time.Weekday) time.Time {
+	last := date(year, month+1, 0)
+	offset := (int(last.Weekday()) - int(weekday) + 7) % 7
+	return last.AddDate(0, 0, -offset)
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func isWeekday(day time.Time) bool {
+	return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+}
+
+// sameDay compares calendar dates, reading day in its own location
+func sameDay(holiday, day time.Time) bool {
+	y1, m1, d1 := holiday.Date()
+	y2, m2, d2 := day.Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
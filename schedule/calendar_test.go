@@ -0,0 +1,82 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestIsUSFederalHoliday(t *testing.T) {
+	tests := []struct {
+		day     string
+		holiday bool
+	}{
+		{"2023-01-02", true},  // New Year's Day observed on Monday
+		{"2023-01-16", true},  // Martin Luther King Jr. Day
+		{"2023-02-20", true},  // Washington's Birthday
+		{"2023-05-29", true},  // Memorial Day
+		{"2023-06-19", true},  // Juneteenth
+		{"2023-07-04", true},  // Independence Day
+		{"2023-09-04", true},  // Labor Day
+		{"2023-10-09", true},  // Columbus Day
+		{"2023-11-10", true},  // Veterans Day observed on Friday
+		{"2023-11-23", true},  // Thanksgiving
+		{"2023-12-25", true},  // Christmas Day
+		{"2021-12-31", true},  // New Year's Day 2022 observed on Friday
+		{"2020-06-19", false}, // Juneteenth before it became a federal holiday
+		{"2023-11-24", false}, // the day after Thanksgiving
+		{"2023-07-05", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.day, func(t *testing.T) {
+			day, err := time.Parse("2006-01-02", tt.day)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.holiday, IsUSFederalHoliday(day))
+		})
+	}
+}
+
+func TestIsUKBankHoliday(t *testing.T) {
+	tests := []struct {
+		day     string
+		holiday bool
+	}{
+		{"2023-01-02", true},  // New Year's Day substitute
+		{"2023-04-07", true},  // Good Friday
+		{"2023-04-10", true},  // Easter Monday
+		{"2023-05-01", true},  // Early May
+		{"2023-05-08", true},  // Coronation
+		{"2023-05-29", true},  // Spring
+		{"2023-08-28", true},  // Summer
+		{"2023-12-25", true},  // Christmas Day
+		{"2023-12-26", true},  // Boxing Day
+		{"2022-06-02", true},  // Spring moved for the Platinum Jubilee
+		{"2022-06-03", true},  // Platinum Jubilee
+		{"2022-05-30", false}, // the usual Spring date in 2022
+		{"2022-12-27", true},  // Christmas Day substitute
+		{"2021-12-28", true},  // Boxing Day substitute after a Saturday Christmas
+		{"2020-05-08", true},  // Early May moved for VE Day
+		{"2024-03-29", true},  // Good Friday
+		{"2024-07-04", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.day, func(t *testing.T) {
+			day, err := time.Parse("2006-01-02", tt.day)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.holiday, IsUKBankHoliday(day))
+		})
+	}
+}
+
+func TestNextBusinessDay(t *testing.T) {
+	// Saturday before a Monday holiday rolls to Tuesday, keeping the clock time
+	saturday := time.Date(2023, time.September, 2, 9, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2023, time.September, 5, 9, 30, 0, 0, time.UTC), NextBusinessDay(USFederal, saturday))
+	assert.Equal(t, time.Date(2023, time.September, 4, 9, 30, 0, 0, time.UTC), NextBusinessDay(Weekdays, saturday))
+
+	thursday := time.Date(2023, time.December, 21, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2023, time.December, 27, 0, 0, 0, 0, time.UTC), AddBusinessDays(UKBankHolidays, thursday, 2))
+}
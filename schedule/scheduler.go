@@ -0,0 +1,246 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/stannp"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+const DefaultRetention = 24 * time.Hour
+
+type Status string
+
+const (
+	StatusCancelled Status = "cancelled"
+	StatusFailed    Status = "failed"
+	StatusPending   Status = "pending"
+	StatusSending   Status = "sending"
+	StatusSent      Status = "sent"
+)
+
+// Scheduled is a letter held by the Scheduler until Due
+type Scheduled struct {
+	Due       time.Time       `json:"due"`
+	Error     *util.APIError  `json:"error,omitempty"`
+	ID        string          `json:"id"`
+	Request   letter.SendReq  `json:"request"`
+	Requested time.Time       `json:"requested"`
+	Response  *letter.SendRes `json:"response,omitempty"`
+	Settled   time.Time       `json:"settled,omitempty"` // when the letter was sent, failed or was cancelled
+	Status    Status          `json:"status"`
+}
+
+type Option func(*Scheduler)
+
+// Scheduler holds letters in memory and sends them through a stannp.Client once they are due, moving any due time
+// that falls outside the calendar's business days to the next business day. Letters that have been sent, have failed
+// or were cancelled are forgotten once the retention set by WithRetention has passed.
+type Scheduler struct {
+	calendar  Calendar
+	client    stannp.Client
+	items     map[string]*Scheduled
+	mu        sync.Mutex
+	now       func() time.Time
+	retention time.Duration
+	wake      chan struct{}
+}
+
+// WithCalendar sets which days letters may be sent on. Defaults to Weekdays.
+func WithCalendar(calendar Calendar) Option {
+	return func(s *Scheduler) {
+		s.calendar = calendar
+	}
+}
+
+// WithRetention sets how long Get can still find a letter after it was sent, failed or was cancelled. Defaults to
+// DefaultRetention.
+func WithRetention(retention time.Duration) Option {
+	return func(s *Scheduler) {
+		s.retention = retention
+	}
+}
+
+func New(client stannp.Client, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		calendar:  Weekdays,
+		client:    client,
+		items:     map[string]*Scheduled{},
+		now:       func() time.Time { return time.Now().UTC() },
+		retention: DefaultRetention,
+		wake:      make(chan struct{}, 1),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Schedule holds req until due, or the next business day after it, and returns the adjusted schedule
+func (s *Scheduler) Schedule(req *letter.SendReq, due time.Time) (*Scheduled, *util.APIError) {
+	if req == nil {
+		return nil, util.BuildError(400, "req must not be nil")
+	}
+
+	item := &Scheduled{
+		Due:       NextBusinessDay(s.calendar, due),
+		ID:        util.RandomString(20),
+		Request:   *req,
+		Requested: due,
+		Status:    StatusPending,
+	}
+
+	s.mu.Lock()
+	s.items[item.ID] = item
+	copied := *item
+	s.mu.Unlock()
+
+	s.signal()
+	return &copied, nil
+}
+
+// Cancel stops a pending letter from being sent
+func (s *Scheduler) Cancel(id string) *util.APIError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[id]
+	if !ok {
+		return util.BuildError(404, fmt.Sprintf("scheduled letter [%s] not found", id))
+	}
+
+	if item.Status != StatusPending {
+		return util.BuildError(409, fmt.Sprintf("scheduled letter [%s] is already [%s]", id, item.Status))
+	}
+
+	item.Settled = s.now()
+	item.Status = StatusCancelled
+	return nil
+}
+
+func (s *Scheduler) Get(id string) (*Scheduled, *util.APIError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[id]
+	if !ok {
+		return nil, util.BuildError(404, fmt.Sprintf("scheduled letter [%s] not found", id))
+	}
+
+	copied := *item
+	return &copied, nil
+}
+
+// Pending lists the letters still waiting to be sent, soonest first
+func (s *Scheduler) Pending() []Scheduled {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := []Scheduled{}
+	for _, item := range s.items {
+		if item.Status == StatusPending {
+			pending = append(pending, *item)
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Due.Before(pending[j].Due)
+	})
+
+	return pending
+}
+
+// SendDue sends every pending letter whose due time has passed and returns how many were attempted
+func (s *Scheduler) SendDue(ctx context.Context) int {
+	due := s.claimDue()
+	for _, item := range due {
+		res, sendErr := s.client.SendLetter(ctx, &item.Request)
+
+		s.mu.Lock()
+		item.Error = sendErr
+		item.Response = res
+		item.Settled = s.now()
+		switch {
+		case sendErr == nil && res.Success:
+			item.Status = StatusSent
+		case sendErr == nil:
+			item.Error = util.BuildError(502, fmt.Sprintf("stannp did not report success for scheduled letter [%s]", item.ID))
+			item.Status = StatusFailed
+		default:
+			item.Status = StatusFailed
+		}
+		s.mu.Unlock()
+	}
+
+	return len(due)
+}
+
+// Run sends letters as they fall due until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		s.SendDue(ctx)
+
+		timer := time.NewTimer(s.untilNext())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// claimDue marks due letters as sending so Cancel can no longer race with the send, and forgets letters settled longer
+// ago than the retention
+func (s *Scheduler) claimDue() []*Scheduled {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var due []*Scheduled
+	for id, item := range s.items {
+		switch {
+		case item.Status == StatusPending && !item.Due.After(now):
+			item.Status = StatusSending
+			due = append(due, item)
+		case !item.Settled.IsZero() && now.Sub(item.Settled) > s.retention:
+			delete(s.items, id)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].Due.Before(due[j].Due)
+	})
+
+	return due
+}
+
+func (s *Scheduler) untilNext() time.Duration {
+	// wake at least hourly so clock changes and suspended machines can't strand a letter
+	wait := time.Hour
+	if pending := s.Pending(); len(pending) > 0 && pending[0].Due.Sub(s.now()) < wait {
+		wait = pending[0].Due.Sub(s.now())
+	}
+
+	if wait < 0 {
+		return 0
+	}
+
+	return wait
+}
+
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,95 @@
+package schedule
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/stannp"
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestScheduler(t *testing.T) {
+	now := time.Date(2023, time.July, 3, 12, 0, 0, 0, time.UTC)
+	scheduler := New(stannp.NewMockClient(), WithCalendar(USFederal))
+	scheduler.now = func() time.Time { return now }
+
+	// due on Independence Day so held until the 5th
+	holiday, apiErr := scheduler.Schedule(&letter.SendReq{Template: "holiday"}, time.Date(2023, time.July, 4, 9, 0, 0, 0, time.UTC))
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, time.Date(2023, time.July, 5, 9, 0, 0, 0, time.UTC), holiday.Due)
+
+	cancelled, _ := scheduler.Schedule(&letter.SendReq{Template: "cancelled"}, now)
+	overdue, _ := scheduler.Schedule(&letter.SendReq{Template: "overdue"}, now.Add(-time.Minute))
+
+	assert.True(t, reflect.ValueOf(scheduler.Cancel(cancelled.ID)).IsNil())
+	assert.Equal(t, 409, scheduler.Cancel(cancelled.ID).Code)
+	assert.Equal(t, 404, scheduler.Cancel("missing").Code)
+
+	pending := scheduler.Pending()
+	assert.Equal(t, 2, len(pending))
+	assert.Equal(t, overdue.ID, pending[0].ID)
+	assert.Equal(t, holiday.ID, pending[1].ID)
+
+	assert.Equal(t, 1, scheduler.SendDue(context.Background()))
+
+	sent, _ := scheduler.Get(overdue.ID)
+	assert.Equal(t, StatusSent, sent.Status)
+	assert.True(t, sent.Response.Success)
+
+	skipped, _ := scheduler.Get(cancelled.ID)
+	assert.Equal(t, StatusCancelled, skipped.Status)
+	assert.Equal(t, now, skipped.Settled)
+
+	now = time.Date(2023, time.July, 5, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, 1, scheduler.SendDue(context.Background()))
+	assert.Equal(t, 0, len(scheduler.Pending()))
+
+	// letters settled more than a day ago are forgotten
+	_, apiErr = scheduler.Get(cancelled.ID)
+	assert.Equal(t, 404, apiErr.Code)
+	_, apiErr = scheduler.Get(overdue.ID)
+	assert.Equal(t, 404, apiErr.Code)
+	sent, apiErr = scheduler.Get(holiday.ID)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, StatusSent, sent.Status)
+}
+
+func TestSchedulerRecordsFailures(t *testing.T) {
+	scheduler := New(stannp.NewMockClient(stannp.WithSendLetterFailNext(true)))
+
+	item, _ := scheduler.Schedule(&letter.SendReq{Template: "42"}, time.Now().Add(-time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if failed, _ := scheduler.Get(item.ID); failed.Status == StatusFailed {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	failed, _ := scheduler.Get(item.ID)
+	assert.Equal(t, StatusFailed, failed.Status)
+	assert.Equal(t, "sendLetterFailNext is true", failed.Error.ErrorMessage)
+}
+
+func TestSchedulerTreatsAnUnsuccessfulResponseAsFailed(t *testing.T) {
+	scheduler := New(stannp.NewMockClient(stannp.WithSendLetterResponseNext(&letter.SendRes{Success: false})))
+
+	item, _ := scheduler.Schedule(&letter.SendReq{Template: "42"}, time.Now().Add(-time.Hour))
+	assert.Equal(t, 1, scheduler.SendDue(context.Background()))
+
+	failed, _ := scheduler.Get(item.ID)
+	assert.Equal(t, StatusFailed, failed.Status)
+	assert.Equal(t, 502, failed.Error.Code)
+}
@@ -0,0 +1,87 @@
+package stannp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/copilotiq/stannp-client-golang/address"
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// checkCountry resolves the recipient's free text country to its ISO 3166-1 code and checks the address has what that
+// country's post needs, so a letter abroad is refused up front instead of failing after it is submitted. Recipients
+// without a country are sent as given. The caller's request is never modified. Only used WithCountryChecks.
+func checkCountry(request *letter.SendReq) (*letter.SendReq, *util.APIError) {
+	if strings.TrimSpace(request.Recipient.Country) == "" {
+		return request, nil
+	}
+
+	recipientAddress, parseErr := address.FromRecipient(request.Recipient, "")
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if validErr := recipientAddress.Validate(); validErr != nil {
+		return nil, validErr
+	}
+
+	if request.Recipient.Country == string(recipientAddress.Country) {
+		return request, nil
+	}
+
+	resolved := *request
+	resolved.Recipient.Country = string(recipientAddress.Country)
+	return &resolved, nil
+}
+
+// checkRecipient validates the recipient's address according to the client's AddressPolicy and returns the request
+// to send, which has the corrected address when the policy substitutes it. The caller's request is never modified.
+func (s *Stannp) checkRecipient(ctx context.Context, request *letter.SendReq) (*letter.SendReq, *letter.AddressCheck, *util.APIError) {
+	check := &letter.AddressCheck{Policy: s.addressPolicy, Used: request.Recipient}
+
+	validateReq := address.ValidateReqFromRecipient(request.Recipient)
+	validateRes, validateErr := s.ValidateAddress(ctx, &validateReq)
+	if validateErr != nil {
+		if s.addressPolicy != letter.AddressPolicyProceed {
+			return nil, nil, validateErr
+		}
+
+		check.ValidationError = validateErr
+		return request, check, nil
+	}
+
+	data := validateRes.Data
+	check.Deliverability = string(data.Deliverability)
+	check.Valid = isSendable(&data)
+
+	if s.addressPolicy == letter.AddressPolicyProceed {
+		return request, check, nil
+	}
+
+	if !check.Valid {
+		return nil, nil, util.BuildTypedError(422, letter.AddressUndeliverableErrorType,
+			fmt.Sprintf("recipient address failed validation with deliverability [%s]", data.Deliverability))
+	}
+
+	if s.addressPolicy != letter.AddressPolicySubstitute {
+		return request, check, nil
+	}
+
+	corrected := data.RecipientDetails(request.Recipient)
+	if corrected == request.Recipient {
+		return request, check, nil
+	}
+
+	substituted := *request
+	substituted.Recipient = corrected
+	check.Corrected = true
+	check.Used = corrected
+	return &substituted, check, nil
+}
+
+// isSendable is true when Stannp considers the address valid and, if it reported deliverability, deliverable
+func isSendable(data *address.Data) bool {
+	return data.IsValid && (data.Deliverability == "" || data.IsDeliverable())
+}
@@ -0,0 +1,140 @@
+package stannp
+
+import (
+	"context"
+	"github.com/copilotiq/stannp-client-golang/address"
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/jgroeneveld/trial/assert"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestSendLetterValidateBeforeSend(t *testing.T) {
+	recipient := letter.RecipientDetails{Address1: "9355 burton wy", Firstname: "Judge", Lastname: "Judy", State: "CA", Town: "Beverly Hils", Zipcode: "90210"}
+	corrected := letter.RecipientDetails{Address1: "9355 BURTON WAY", Firstname: "Judge", Lastname: "Judy", State: "CA", Town: "BEVERLY HILLS", Zipcode: "90210-3669"}
+
+	deliverable := `{"success":true,"data":{"is_valid":true,"deliverability":"deliverable","address1":"9355 BURTON WAY","city":"BEVERLY HILLS","state":"CA","zipcode":"90210-3669"}}`
+	undeliverable := `{"success":true,"data":{"is_valid":false,"deliverability":"undeliverable"}}`
+	failed := `{"success":false,"error":"validation unavailable"}`
+
+	tests := []struct {
+		name              string
+		policy            letter.AddressPolicy
+		validateRes       string
+		expectedErrType   string
+		expectedErrCode   int
+		expectedAddress1  string
+		expectedCorrected bool
+		expectedValid     bool
+		expectedNoCheck   bool
+	}{
+		{name: "off", policy: "", validateRes: undeliverable, expectedAddress1: recipient.Address1, expectedNoCheck: true},
+		{name: "refuse undeliverable", policy: letter.AddressPolicyRefuse, validateRes: undeliverable, expectedErrType: letter.AddressUndeliverableErrorType, expectedErrCode: 422},
+		{name: "refuse deliverable", policy: letter.AddressPolicyRefuse, validateRes: deliverable, expectedAddress1: recipient.Address1, expectedValid: true},
+		{name: "refuse validation failure", policy: letter.AddressPolicyRefuse, validateRes: failed, expectedErrCode: 500},
+		{name: "substitute deliverable", policy: letter.AddressPolicySubstitute, validateRes: deliverable, expectedAddress1: corrected.Address1, expectedCorrected: true, expectedValid: true},
+		{name: "substitute undeliverable", policy: letter.AddressPolicySubstitute, validateRes: undeliverable, expectedErrType: letter.AddressUndeliverableErrorType, expectedErrCode: 422},
+		{name: "proceed undeliverable", policy: letter.AddressPolicyProceed, validateRes: undeliverable, expectedAddress1: recipient.Address1},
+		{name: "proceed validation failure", policy: letter.AddressPolicyProceed, validateRes: failed, expectedAddress1: recipient.Address1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent url.Values
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				assert.Nil(t, r.ParseForm())
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/addresses/validate":
+					if tt.validateRes == failed {
+						w.WriteHeader(http.StatusInternalServerError)
+					}
+					_, _ = w.Write([]byte(tt.validateRes))
+				case "/letters/create":
+					sent = r.PostForm
+					_, _ = w.Write([]byte(`{"success":true,"data":{"id":1,"status":"test"}}`))
+				default:
+					t.Fatalf("unexpected path [%s]", r.URL.Path)
+				}
+			}
+
+			api := newTestAPI(t, handler, WithValidateBeforeSend(tt.policy))
+			request := &letter.SendReq{Recipient: recipient, Template: "42"}
+
+			letterRes, apiErr := api.SendLetter(context.Background(), request)
+			assert.Equal(t, recipient, request.Recipient)
+
+			if tt.expectedErrCode != 0 {
+				assert.False(t, reflect.ValueOf(apiErr).IsNil())
+				assert.Equal(t, tt.expectedErrCode, apiErr.Code)
+				assert.Equal(t, tt.expectedErrType, apiErr.Type)
+				assert.True(t, sent == nil)
+				return
+			}
+
+			assert.True(t, reflect.ValueOf(apiErr).IsNil())
+			assert.Equal(t, tt.expectedAddress1, sent.Get("recipient[address1]"))
+			assert.Equal(t, "Judge", sent.Get("recipient[firstname]"))
+
+			if tt.expectedNoCheck {
+				assert.True(t, letterRes.AddressCheck == nil)
+				return
+			}
+
+			check := letterRes.AddressCheck
+			assert.Equal(t, tt.policy, check.Policy)
+			assert.Equal(t, tt.expectedCorrected, check.Corrected)
+			assert.Equal(t, tt.expectedValid, check.Valid)
+			assert.Equal(t, tt.expectedAddress1, check.Used.Address1)
+			assert.Equal(t, tt.validateRes == failed, check.ValidationError != nil)
+			if tt.expectedCorrected {
+				assert.Equal(t, corrected, check.Used)
+			}
+		})
+	}
+}
+
+func TestSendLetterRecipientCountry(t *testing.T) {
+	var sent []url.Values
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, r.ParseForm())
+		sent = append(sent, r.PostForm)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":1,"status":"test"}}`))
+	}
+
+	berlin := letter.RecipientDetails{Address1: "Platz der Republik 1", Country: "Germany", Firstname: "Judge", Town: "Berlin", Zipcode: "11011"}
+
+	// without country checks the recipient is sent as given
+	request := &letter.SendReq{Recipient: berlin, Template: "42"}
+	_, apiErr := newTestAPI(t, handler).SendLetter(context.Background(), request)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, "Germany", sent[0].Get("recipient[country]"))
+	sent = nil
+
+	api := newTestAPI(t, handler, WithCountryChecks(true))
+	_, apiErr = api.SendLetter(context.Background(), request)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, "DE", sent[0].Get("recipient[country]"))
+	assert.Equal(t, "Germany", request.Recipient.Country)
+
+	// a blank country is sent as given
+	_, apiErr = api.SendLetter(context.Background(), &letter.SendReq{Template: "42"})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, 2, len(sent))
+
+	unknown := berlin
+	unknown.Country = "Atlantis"
+	_, apiErr = api.SendLetter(context.Background(), &letter.SendReq{Recipient: unknown, Template: "42"})
+	assert.Equal(t, address.InvalidErrorType, apiErr.Type)
+
+	missingPostcode := berlin
+	missingPostcode.Zipcode = ""
+	_, apiErr = api.SendLetter(context.Background(), &letter.SendReq{Recipient: missingPostcode, Template: "42"})
+	assert.Equal(t, 400, apiErr.Code)
+	assert.Equal(t, address.InvalidErrorType, apiErr.Type)
+
+	assert.Equal(t, 2, len(sent))
+}
@@ -0,0 +1,203 @@
+package stannp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// pageCount counts a letter's pages at most once, so the steps of a send that need them, such as pricing it for its
+// budget and checking its attachments, share one template lookup and one download of each attachment. A count made
+// without lookups is redone when a later step allows them.
+type pageCount struct {
+	counted  bool
+	err      *util.APIError
+	lookups  bool
+	pages    int
+	warnings []string
+}
+
+func (p *pageCount) count(ctx context.Context, s *Stannp, request *letter.SendReq, duplex, lookups bool) (int, []string, *util.APIError) {
+	if !p.counted || lookups && !p.lookups {
+		p.pages, p.warnings, p.err = s.countPages(ctx, request, duplex, lookups)
+		p.counted, p.lookups = true, lookups
+	}
+
+	return p.pages, append([]string(nil), p.warnings...), p.err
+}
+
+// templatePageCache remembers the page count of each template looked up, so a client only asks Stannp once per template
+type templatePageCache struct {
+	mu    sync.Mutex
+	pages map[string]int
+}
+
+func (c *templatePageCache) get(templateID string) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pages, ok := c.pages[templateID]
+	return pages, ok
+}
+
+func (c *templatePageCache) set(templateID string, pages int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pages[templateID] = pages
+}
+
+// countPages totals the template's and attachments' pages and warns when the attachments push the letter past a sheet
+// boundary. Without lookups, only cached template page counts and attachments given as bytes are counted, so nothing
+// is fetched. Counts that can't be determined are reported as warnings and leave the total at zero rather than failing
+// the send; only invalid attachments are errors.
+func (s *Stannp) countPages(ctx context.Context, request *letter.SendReq, duplex, lookups bool) (int, []string, *util.APIError) {
+	var warnings []string
+	known := true
+
+	templatePages, templateWarning := s.templatePageCount(ctx, request.Template, lookups)
+	if templateWarning != "" {
+		known = false
+		warnings = append(warnings, templateWarning)
+	}
+
+	attachmentPages := 0
+	for i := range request.Attachments {
+		attachment := &request.Attachments[i]
+		if validateErr := attachment.Validate(); validateErr != nil {
+			return 0, nil, validateErr
+		}
+
+		if len(attachment.Contents) == 0 && !lookups {
+			known = false
+			warnings = append(warnings, fmt.Sprintf("attachment [%s] was not downloaded to count its pages", attachment.Name))
+			continue
+		}
+
+		pages, countErr := s.attachmentPages(ctx, attachment)
+		if countErr != nil {
+			known = false
+			warnings = append(warnings, fmt.Sprintf("unable to count pages of attachment [%s] with err [%s]", attachment.Name, countErr.ErrorMessage))
+			continue
+		}
+		attachmentPages += pages
+	}
+
+	if !known {
+		return 0, warnings, nil
+	}
+
+	before := letter.Sheets(templatePages, duplex)
+	after := letter.Sheets(templatePages+attachmentPages, duplex)
+	warnings = append(warnings, letter.SheetBoundaryWarnings(before, after, s.sheetBoundaries)...)
+	return templatePages + attachmentPages, warnings, nil
+}
+
+// templatePageCount is the template's page count from the cache or, with lookups, from Stannp, or a warning saying why
+// it isn't known
+func (s *Stannp) templatePageCount(ctx context.Context, templateID string, lookups bool) (int, string) {
+	if pages, ok := s.templatePages.get(templateID); ok {
+		return pages, ""
+	}
+
+	if !lookups {
+		return 0, fmt.Sprintf("template [%s] page count is unknown without looking it up", templateID)
+	}
+
+	templateRes, templateErr := s.GetTemplate(ctx, templateID)
+	if templateErr != nil {
+		return 0, fmt.Sprintf("unable to look up template [%s] page count with err [%s]", templateID, templateErr.ErrorMessage)
+	}
+
+	pages, err := templateRes.Data.Pages.Int64()
+	if err != nil || pages <= 0 {
+		return 0, fmt.Sprintf("template [%s] did not report a page count", templateID)
+	}
+
+	s.templatePages.set(templateID, int(pages))
+	return int(pages), ""
+}
+
+func (s *Stannp) attachmentPages(ctx context.Context, attachment *letter.Attachment) (int, *util.APIError) {
+	if len(attachment.Contents) > 0 {
+		return letter.CountPDFPages(attachment.Contents)
+	}
+
+	pdfRes, downloadErr := s.downloadPDF(ctx, attachment.URL)
+	if downloadErr != nil {
+		return 0, downloadErr
+	}
+	defer pdfRes.Contents.Close()
+
+	contents, err := io.ReadAll(pdfRes.Contents)
+	if err != nil {
+		return 0, util.BuildError(500, err.Error())
+	}
+
+	return letter.CountPDFPages(contents)
+}
+
+// attachmentBody sends URL attachments as attachments[i] fields, switching to a multipart upload when any attachment
+// is given as bytes
+func attachmentBody(formData url.Values, attachments []letter.Attachment) (io.Reader, string, *util.APIError) {
+	uploads := false
+	for i, attachment := range attachments {
+		if attachment.URL != "" {
+			formData.Set(fmt.Sprintf("attachments[%d]", i), attachment.URL)
+		} else {
+			uploads = true
+		}
+	}
+
+	if !uploads {
+		return bytes.NewBufferString(formData.Encode()), URLEncodedHeaderVal, nil
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	keys := make([]string, 0, len(formData))
+	for key := range formData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := writer.WriteField(key, formData.Get(key)); err != nil {
+			return nil, "", util.BuildError(500, err.Error())
+		}
+	}
+
+	for i, attachment := range attachments {
+		if attachment.URL != "" {
+			continue
+		}
+
+		name := attachment.Name
+		if name == "" {
+			name = fmt.Sprintf("attachment_%d.pdf", i)
+		}
+
+		part, err := writer.CreateFormFile(fmt.Sprintf("attachments[%d]", i), name)
+		if err == nil {
+			_, err = part.Write(attachment.Contents)
+		}
+		if err != nil {
+			return nil, "", util.BuildError(500, err.Error())
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", util.BuildError(500, err.Error())
+	}
+
+	return body, writer.FormDataContentType(), nil
+}
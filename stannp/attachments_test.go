@@ -0,0 +1,58 @@
+package stannp
+
+import (
+	"context"
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/jgroeneveld/trial/assert"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSendLetterAttachments(t *testing.T) {
+	insert := "%PDF-1.4 << /Type /Pages /Count 5 >>"
+	consent := []byte("%PDF-1.4 << /Type /Page >>")
+
+	var form *multipart.Form
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/templates/get/42":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":42,"pages":1}}`))
+		case "/storage/insert.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write([]byte(insert))
+		case "/letters/create":
+			assert.Nil(t, r.ParseMultipartForm(1<<20))
+			form = r.MultipartForm
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":1,"status":"test"}}`))
+		default:
+			t.Fatalf("unexpected path [%s]", r.URL.Path)
+		}
+	}
+
+	api := newTestAPI(t, handler, WithDuplex(false))
+
+	res, apiErr := api.SendLetter(context.Background(), &letter.SendReq{
+		Attachments: []letter.Attachment{
+			{Name: "insert.pdf", URL: api.baseUrl + "/storage/insert.pdf"},
+			{Contents: consent, Name: "consent.pdf"},
+		},
+		Template: "42",
+	})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, 7, res.PageCount)
+	assert.Equal(t, 2, len(res.Warnings))
+
+	assert.Equal(t, "42", form.Value["template"][0])
+	assert.Equal(t, api.baseUrl+"/storage/insert.pdf", form.Value["attachments[0]"][0])
+	assert.Equal(t, "consent.pdf", form.File["attachments[1]"][0].Filename)
+
+	_, apiErr = api.SendLetter(context.Background(), &letter.SendReq{
+		Attachments: []letter.Attachment{{Contents: []byte("not a pdf"), Name: "bad"}},
+		Template:    "42",
+	})
+	assert.Equal(t, 400, apiErr.Code)
+}
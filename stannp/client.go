@@ -3,17 +3,38 @@ package stannp
 import (
 	"context"
 	"github.com/copilotiq/stannp-client-golang/address"
+	"github.com/copilotiq/stannp-client-golang/files"
 	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/report"
+	"github.com/copilotiq/stannp-client-golang/storage"
+	"github.com/copilotiq/stannp-client-golang/template"
 	"github.com/copilotiq/stannp-client-golang/util"
 	"io"
-	"os"
 )
 
 // Client interface is for mocking / testing. Implement it however you wish!
 // A standard set of mocks however is available via MockClient
 type Client interface {
+	CreateFolder(ctx context.Context, name string) (*files.FolderRes, *util.APIError)
+	DeleteFile(ctx context.Context, fileID string) (*files.DeleteRes, *util.APIError)
+	DeleteFolder(ctx context.Context, folderID string) (*files.DeleteRes, *util.APIError)
+	GetLetter(ctx context.Context, letterID string) (*letter.GetRes, *util.APIError)
 	GetPDFContents(ctx context.Context, pdfURL string) (*letter.PDFRes, *util.APIError)
-	SavePDFContents(pdfContents io.Reader) (*os.File, *util.APIError)
+	GetTemplate(ctx context.Context, templateID string) (*template.GetRes, *util.APIError)
+	ListTemplates(ctx context.Context) (*template.ListRes, *util.APIError)
+	IterateFiles(ctx context.Context, folderID string, opts ...IteratorOption) *Iterator[files.File]
+	IterateLetters(ctx context.Context, req *report.ListReq, opts ...IteratorOption) *Iterator[letter.Data]
+	IterateTemplates(ctx context.Context, opts ...IteratorOption) *Iterator[template.Data]
+	ListFiles(ctx context.Context, folderID string) (*files.ListRes, *util.APIError)
+	ListFolders(ctx context.Context) (*files.FoldersRes, *util.APIError)
+	ListLetters(ctx context.Context, req *report.ListReq) (*report.ListRes, *util.APIError)
+	LoadPDFContents(ctx context.Context, letterID string) (*letter.PDFRes, *util.APIError)
+	PreviewLetter(ctx context.Context, req *letter.SendReq) (*letter.PDFRes, *util.APIError)
+	QuoteLetter(ctx context.Context, req *letter.SendReq) (*letter.Quote, *util.APIError)
+	SavePDFContents(ctx context.Context, letterID string, pdfContents io.Reader) (*storage.Metadata, *util.APIError)
 	SendLetter(ctx context.Context, req *letter.SendReq) (*letter.SendRes, *util.APIError)
+	SummarizeLetters(ctx context.Context, req *report.ListReq) (*report.Summary, *util.APIError)
+	UploadFile(ctx context.Context, req *files.UploadReq) (*files.UploadRes, *util.APIError)
 	ValidateAddress(ctx context.Context, req *address.ValidateReq) (*address.ValidateRes, *util.APIError)
+	WatchLetter(ctx context.Context, letterID string, opts WatchOptions) (<-chan WatchEvent, *util.APIError)
 }
@@ -0,0 +1,146 @@
+package stannp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	"github.com/copilotiq/stannp-client-golang/files"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// UploadFile streams a PDF or image into Stannp storage without holding it in memory, reporting progress through
+// request.Progress
+func (s *Stannp) UploadFile(ctx context.Context, request *files.UploadReq) (*files.UploadRes, *util.APIError) {
+	if validateErr := request.Validate(); validateErr != nil {
+		return nil, validateErr
+	}
+
+	body, writer := io.Pipe()
+	multipartWriter := multipart.NewWriter(writer)
+	go func() {
+		_ = writer.CloseWithError(writeUpload(multipartWriter, request))
+	}()
+	defer body.Close()
+
+	res, postErr := s.postWithContentType(ctx, body, strings.Join([]string{s.baseUrl, files.URL, files.UploadURL}, "/"), "", multipartWriter.FormDataContentType())
+	if postErr != nil {
+		return nil, postErr
+	}
+
+	var uploadRes files.UploadRes
+	resErr := util.ResToType(res.StatusCode, res.Body, &uploadRes)
+	return &uploadRes, resErr
+}
+
+func writeUpload(writer *multipart.Writer, request *files.UploadReq) error {
+	if request.FolderID != "" {
+		if err := writer.WriteField("folder", request.FolderID); err != nil {
+			return err
+		}
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, request.Name))
+	header.Set(ContentTypeHeaderKey, request.ContentType())
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(part, request.Reader()); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// IterateFiles reads the files in Stannp storage a page at a time, only those in folderID when it is set. Stannp sends
+// every file in one response, so they are fetched once and served from memory.
+func (s *Stannp) IterateFiles(ctx context.Context, folderID string, opts ...IteratorOption) *Iterator[files.File] {
+	return NewIterator(ctx, listFetcher(func(ctx context.Context) ([]files.File, *util.APIError) {
+		listRes, listErr := s.ListFiles(ctx, folderID)
+		if listErr != nil {
+			return nil, listErr
+		}
+
+		return listRes.Data, nil
+	}), opts...)
+}
+
+// ListFiles returns the files in Stannp storage, only those in folderID when it is set
+func (s *Stannp) ListFiles(ctx context.Context, folderID string) (*files.ListRes, *util.APIError) {
+	inputURL := strings.Join([]string{s.baseUrl, files.URL, ListURL}, "/")
+	if folderID != "" {
+		inputURL += "?" + files.FolderQSP + "=" + url.QueryEscape(folderID)
+	}
+
+	var listRes files.ListRes
+	if listErr := s.listPage(ctx, inputURL, &listRes, &listRes.Success); listErr != nil {
+		return nil, listErr
+	}
+
+	return &listRes, nil
+}
+
+// ListFolders returns every folder in Stannp storage
+func (s *Stannp) ListFolders(ctx context.Context) (*files.FoldersRes, *util.APIError) {
+	var foldersRes files.FoldersRes
+	if listErr := s.listPage(ctx, strings.Join([]string{s.baseUrl, files.URL, files.FoldersURL}, "/"), &foldersRes, &foldersRes.Success); listErr != nil {
+		return nil, listErr
+	}
+
+	return &foldersRes, nil
+}
+
+// CreateFolder creates a folder in Stannp storage to upload files into
+func (s *Stannp) CreateFolder(ctx context.Context, name string) (*files.FolderRes, *util.APIError) {
+	if strings.TrimSpace(name) == "" {
+		return nil, util.BuildError(400, "folder name must not be empty")
+	}
+
+	formData := url.Values{}
+	formData.Set("name", name)
+
+	res, postErr := s.post(ctx, bytes.NewBufferString(formData.Encode()), strings.Join([]string{s.baseUrl, files.URL, files.CreateFolderURL}, "/"), "")
+	if postErr != nil {
+		return nil, postErr
+	}
+
+	var folderRes files.FolderRes
+	resErr := util.ResToType(res.StatusCode, res.Body, &folderRes)
+	return &folderRes, resErr
+}
+
+// DeleteFile deletes a file from Stannp storage
+func (s *Stannp) DeleteFile(ctx context.Context, fileID string) (*files.DeleteRes, *util.APIError) {
+	return s.deleteByID(ctx, files.DeleteURL, fileID)
+}
+
+// DeleteFolder deletes a folder from Stannp storage
+func (s *Stannp) DeleteFolder(ctx context.Context, folderID string) (*files.DeleteRes, *util.APIError) {
+	return s.deleteByID(ctx, files.DeleteFolderURL, folderID)
+}
+
+func (s *Stannp) deleteByID(ctx context.Context, deleteURL, id string) (*files.DeleteRes, *util.APIError) {
+	if id == "" {
+		return nil, util.BuildError(400, "id must not be empty")
+	}
+
+	formData := url.Values{}
+	formData.Set("id", id)
+
+	res, postErr := s.post(ctx, bytes.NewBufferString(formData.Encode()), strings.Join([]string{s.baseUrl, files.URL, deleteURL}, "/"), "")
+	if postErr != nil {
+		return nil, postErr
+	}
+
+	var deleteRes files.DeleteRes
+	resErr := util.ResToType(res.StatusCode, res.Body, &deleteRes)
+	return &deleteRes, resErr
+}
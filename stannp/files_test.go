@@ -0,0 +1,113 @@
+package stannp
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/copilotiq/stannp-client-golang/files"
+	"github.com/jgroeneveld/trial/assert"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUploadFile(t *testing.T) {
+	contents := "%PDF-1.4 " + strings.Repeat("x", 100000)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/files/upload", r.URL.Path)
+
+		reader, err := r.MultipartReader()
+		assert.Nil(t, err)
+
+		folder, err := reader.NextPart()
+		assert.Nil(t, err)
+		assert.Equal(t, "folder", folder.FormName())
+		folderID, _ := io.ReadAll(folder)
+		assert.Equal(t, "7", string(folderID))
+
+		file, err := reader.NextPart()
+		assert.Nil(t, err)
+		assert.Equal(t, "file", file.FormName())
+		assert.Equal(t, "letterhead.pdf", file.FileName())
+		assert.Equal(t, "application/pdf", file.Header.Get(ContentTypeHeaderKey))
+		uploaded, _ := io.ReadAll(file)
+		assert.Equal(t, contents, string(uploaded))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":99,"folder_id":7,"name":"letterhead.pdf"}}`))
+	}
+
+	api := newTestAPI(t, handler)
+
+	var lastSent, lastTotal int64
+	uploadRes, apiErr := api.UploadFile(context.Background(), &files.UploadReq{
+		Contents: strings.NewReader(contents),
+		FolderID: "7",
+		Name:     "letterhead.pdf",
+		Progress: func(sent, total int64) { lastSent, lastTotal = sent, total },
+		Size:     int64(len(contents)),
+	})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, json.Number("99"), uploadRes.Data.ID)
+	assert.Equal(t, int64(len(contents)), lastSent)
+	assert.Equal(t, int64(len(contents)), lastTotal)
+
+	_, apiErr = api.UploadFile(context.Background(), &files.UploadReq{Contents: strings.NewReader(""), Name: "notes.txt"})
+	assert.Equal(t, 400, apiErr.Code)
+}
+
+func TestFilesAndFolders(t *testing.T) {
+	var requests []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+r.Form.Get(files.FolderQSP)+r.PostForm.Get("id")+r.PostForm.Get("name"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/files/list":
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"id":1,"folder_id":7,"name":"letterhead.pdf"}]}`))
+		case "/files/folders":
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"id":7,"name":"inserts"}]}`))
+		case "/files/createFolder":
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":8,"name":"letterheads"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"success":true}`))
+		}
+	}
+
+	api := newTestAPI(t, handler)
+	ctx := context.Background()
+
+	listRes, apiErr := api.ListFiles(ctx, "7")
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, "letterhead.pdf", listRes.Data[0].Name)
+
+	foldersRes, apiErr := api.ListFolders(ctx)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, "inserts", foldersRes.Data[0].Name)
+
+	folderRes, apiErr := api.CreateFolder(ctx, "letterheads")
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, json.Number("8"), folderRes.Data.ID)
+
+	deleteRes, apiErr := api.DeleteFile(ctx, "1")
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.True(t, deleteRes.Success)
+
+	_, apiErr = api.DeleteFolder(ctx, "8")
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	assert.True(t, reflect.DeepEqual([]string{
+		"GET /files/list 7",
+		"GET /files/folders ",
+		"POST /files/createFolder letterheads",
+		"POST /files/delete 1",
+		"POST /files/deleteFolder 8",
+	}, requests))
+
+	_, apiErr = api.DeleteFile(ctx, "")
+	assert.Equal(t, 400, apiErr.Code)
+	_, apiErr = api.CreateFolder(ctx, " ")
+	assert.Equal(t, 400, apiErr.Code)
+}
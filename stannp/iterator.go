@@ -0,0 +1,231 @@
+package stannp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+const DefaultPageSize = 100
+const LimitQSP = "limit"
+const OffsetQSP = "offset"
+
+// PageFetcher fetches up to limit items starting at offset. A page shorter than limit is the last.
+type PageFetcher[T any] func(ctx context.Context, offset, limit int) ([]T, *util.APIError)
+
+type iteratorOptions struct {
+	pageSize int
+	prefetch bool
+}
+
+type IteratorOption func(*iteratorOptions)
+
+// WithPageSize sets how many items an Iterator asks for at a time. Defaults to DefaultPageSize.
+func WithPageSize(pageSize int) IteratorOption {
+	return func(o *iteratorOptions) {
+		o.pageSize = pageSize
+	}
+}
+
+// WithPrefetch makes an Iterator fetch the next page while the current one is being read. Off by default, since
+// stopping early then costs one page that is never read.
+func WithPrefetch(prefetch bool) IteratorOption {
+	return func(o *iteratorOptions) {
+		o.prefetch = prefetch
+	}
+}
+
+type page[T any] struct {
+	err   *util.APIError
+	items []T
+}
+
+// Iterator walks a list endpoint's offset/limit pages lazily, fetching each page only once the previous one has been
+// read. Call Next until it returns false, reading each item with Item, then check Err. Call Close to stop early.
+type Iterator[T any] struct {
+	cancel   context.CancelFunc
+	ctx      context.Context
+	done     bool
+	err      *util.APIError
+	fetch    PageFetcher[T]
+	first    string // the key of the last page's first item, to notice an endpoint sending the same page again
+	index    int
+	item     T
+	items    []T
+	key      func(item T) string
+	next     chan page[T] // the prefetched page, when one is in flight
+	offset   int          // where the next page to fetch starts
+	pageSize int
+	prefetch bool
+}
+
+// NewKeyedIterator pages through fetch like NewIterator, and also stops with a 502 Err when a page starts with the
+// same item as the page before, as told by key, since an endpoint that ignores offset would otherwise send the first
+// page forever. key should identify an item uniquely, or return "" for items it can't identify, which are never taken
+// for a repeat.
+func NewKeyedIterator[T any](ctx context.Context, fetch PageFetcher[T], key func(item T) string, opts ...IteratorOption) *Iterator[T] {
+	it := NewIterator(ctx, fetch, opts...)
+	it.key = key
+	return it
+}
+
+// NewIterator pages through fetch. The iterator stops, with Err set, once ctx is done.
+func NewIterator[T any](ctx context.Context, fetch PageFetcher[T], opts ...IteratorOption) *Iterator[T] {
+	options := iteratorOptions{pageSize: DefaultPageSize}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.pageSize <= 0 {
+		options.pageSize = DefaultPageSize
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	return &Iterator[T]{
+		cancel:   cancel,
+		ctx:      ctx,
+		fetch:    fetch,
+		pageSize: options.pageSize,
+		prefetch: options.prefetch,
+	}
+}
+
+// newFailedIterator is an iterator that reads nothing and reports err, for requests refused before any page is fetched
+func newFailedIterator[T any](err *util.APIError) *Iterator[T] {
+	return &Iterator[T]{cancel: func() {}, ctx: context.Background(), done: true, err: err}
+}
+
+// Next moves to the next item, fetching a page when needed. It returns false once there are no more items, the
+// iterator has been closed or a fetch has failed.
+func (it *Iterator[T]) Next() bool {
+	for it.index >= len(it.items) {
+		if it.done || it.err != nil {
+			return false
+		}
+
+		it.loadPage()
+	}
+
+	it.item = it.items[it.index]
+	it.index++
+	return true
+}
+
+// Item is the item Next moved to
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+// Err is why the iteration stopped early, if it did
+func (it *Iterator[T]) Err() *util.APIError {
+	return it.err
+}
+
+// Close stops the iteration, cancelling any prefetch in flight. It is safe to call more than once.
+func (it *Iterator[T]) Close() {
+	it.done = true
+	it.items, it.index = nil, 0
+	it.cancel()
+}
+
+// Collect reads up to limit items, or every item when limit isn't positive, and closes the iterator
+func (it *Iterator[T]) Collect(limit int) ([]T, *util.APIError) {
+	defer it.Close()
+
+	items := []T{}
+	for (limit <= 0 || len(items) < limit) && it.Next() {
+		items = append(items, it.Item())
+	}
+
+	return items, it.Err()
+}
+
+func (it *Iterator[T]) loadPage() {
+	if ctxErr := it.ctx.Err(); ctxErr != nil {
+		it.err = util.BuildError(500, ctxErr.Error())
+		return
+	}
+
+	var current page[T]
+	if it.next != nil {
+		current = <-it.next
+		it.next = nil
+	} else {
+		current = it.fetchPage(it.offset)
+		it.offset += it.pageSize
+	}
+
+	if current.err != nil {
+		it.err = current.err
+		return
+	}
+
+	// an endpoint that ignores offset sends the first page over and over, which would otherwise never end
+	if it.key != nil && len(current.items) > 0 {
+		first := it.key(current.items[0])
+		if first != "" && first == it.first {
+			it.err = util.BuildError(502, fmt.Sprintf("list endpoint sent the same page again at offset [%d]; it may not support paging", it.offset-it.pageSize))
+			return
+		}
+		it.first = first
+	}
+
+	it.items, it.index = current.items, 0
+
+	// a short page is the last, and a long one means the endpoint ignored the limit and sent everything
+	if len(current.items) != it.pageSize {
+		it.done = true
+		return
+	}
+
+	if it.prefetch {
+		next := make(chan page[T], 1)
+		go func(offset int) {
+			next <- it.fetchPage(offset)
+		}(it.offset)
+		it.next = next
+		it.offset += it.pageSize
+	}
+}
+
+func (it *Iterator[T]) fetchPage(offset int) page[T] {
+	items, fetchErr := it.fetch(it.ctx, offset, it.pageSize)
+	if fetchErr == nil && it.ctx.Err() != nil {
+		fetchErr = util.BuildError(500, it.ctx.Err().Error())
+	}
+
+	return page[T]{err: fetchErr, items: items}
+}
+
+// pageURL adds the offset and limit of a page to a list endpoint's url
+func pageURL(listURL string, offset, limit int) string {
+	return fmt.Sprintf("%s?%s=%s&%s=%s", listURL, OffsetQSP, strconv.Itoa(offset), LimitQSP, strconv.Itoa(limit))
+}
+
+// listFetcher serves an endpoint that sends everything in one response a page at a time, fetching it only once
+func listFetcher[T any](list func(ctx context.Context) ([]T, *util.APIError)) PageFetcher[T] {
+	var items []T
+	fetched := false
+	return func(ctx context.Context, offset, limit int) ([]T, *util.APIError) {
+		if !fetched {
+			var listErr *util.APIError
+			if items, listErr = list(ctx); listErr != nil {
+				return nil, listErr
+			}
+			fetched = true
+		}
+
+		return pageOf(items, offset, limit), nil
+	}
+}
+
+// pageOf is the page of items starting at offset, for serving an in-memory list a page at a time
+func pageOf[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+
+	return items[offset:min(offset+limit, len(items))]
+}
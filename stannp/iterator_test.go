@@ -0,0 +1,127 @@
+package stannp
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/copilotiq/stannp-client-golang/util"
+	"github.com/jgroeneveld/trial/assert"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIterator(t *testing.T) {
+	numbers := make([]int, 250)
+	for i := range numbers {
+		numbers[i] = i
+	}
+
+	var mu sync.Mutex
+	var offsets []int
+	fetch := func(failAt int) PageFetcher[int] {
+		return func(_ context.Context, offset, limit int) ([]int, *util.APIError) {
+			mu.Lock()
+			offsets = append(offsets, offset)
+			mu.Unlock()
+			if offset == failAt {
+				return nil, util.BuildError(503, "unavailable")
+			}
+			return pageOf(numbers, offset, limit), nil
+		}
+	}
+
+	tests := []struct {
+		name            string
+		collect         int
+		opts            []IteratorOption
+		failAt          int
+		expectedCount   int
+		expectedOffsets []int
+		expectedErr     bool
+	}{
+		{name: "everything", failAt: -1, expectedCount: 250, expectedOffsets: []int{0, 100, 200}},
+		{name: "smaller pages", opts: []IteratorOption{WithPageSize(125)}, failAt: -1, expectedCount: 250, expectedOffsets: []int{0, 125, 250}},
+		{name: "stops early", collect: 150, failAt: -1, expectedCount: 150, expectedOffsets: []int{0, 100}},
+		{name: "prefetches the next page", collect: 150, opts: []IteratorOption{WithPrefetch(true)}, failAt: -1, expectedCount: 150, expectedOffsets: []int{0, 100, 200}},
+		{name: "fails part way", failAt: 100, expectedCount: 100, expectedOffsets: []int{0, 100}, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offsets = nil
+			iterator := NewIterator(context.Background(), fetch(tt.failAt), tt.opts...)
+			items, iterErr := iterator.Collect(tt.collect)
+
+			assert.Equal(t, tt.expectedCount, len(items))
+			assert.Equal(t, tt.expectedErr, !reflect.ValueOf(iterErr).IsNil())
+			assert.False(t, iterator.Next())
+
+			// give a prefetch cancelled by Collect a chance to record its offset
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			assert.True(t, reflect.DeepEqual(tt.expectedOffsets, offsets))
+			mu.Unlock()
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	iterator := NewIterator(ctx, fetch(-1))
+	assert.True(t, iterator.Next())
+	cancel()
+	_, iterErr := iterator.Collect(0)
+	assert.Equal(t, 500, iterErr.Code)
+
+	// an endpoint that ignores offset would otherwise be read forever
+	calls := 0
+	ignoresOffset := func(_ context.Context, _, limit int) ([]int, *util.APIError) {
+		calls++
+		return pageOf(numbers, 0, limit), nil
+	}
+	iterator = NewKeyedIterator(context.Background(), ignoresOffset, strconv.Itoa)
+	items, iterErr := iterator.Collect(0)
+	assert.Equal(t, 502, iterErr.Code)
+	assert.Equal(t, 100, len(items))
+	assert.Equal(t, 2, calls)
+
+	// pages that happen to start with equal values aren't mistaken for a repeat when the key can't tell items apart
+	zeros := make([]int, 150)
+	iterator = NewKeyedIterator(context.Background(), func(_ context.Context, offset, limit int) ([]int, *util.APIError) {
+		return pageOf(zeros, offset, limit), nil
+	}, func(int) string { return "" })
+	items, iterErr = iterator.Collect(0)
+	assert.True(t, reflect.ValueOf(iterErr).IsNil())
+	assert.Equal(t, 150, len(items))
+
+	// nor does an iterator without a key look for repeats at all
+	items, iterErr = NewIterator(context.Background(), func(_ context.Context, offset, limit int) ([]int, *util.APIError) {
+		return pageOf(zeros, offset, limit), nil
+	}).Collect(0)
+	assert.True(t, reflect.ValueOf(iterErr).IsNil())
+	assert.Equal(t, 150, len(items))
+}
+
+func TestIterateTemplates(t *testing.T) {
+	var queries []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/templates/list", r.URL.Path)
+		queries = append(queries, r.URL.Query().Get(OffsetQSP)+"/"+r.URL.Query().Get(LimitQSP))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":[{"id":1},{"id":2},{"id":3}]}`))
+	}
+
+	// every template comes in one response, which is fetched once however it is paged
+	api := newTestAPI(t, handler)
+	templates, iterErr := api.IterateTemplates(context.Background(), WithPageSize(2)).Collect(0)
+	assert.True(t, reflect.ValueOf(iterErr).IsNil())
+	assert.Equal(t, 3, len(templates))
+	assert.Equal(t, json.Number("3"), templates[2].ID)
+	assert.True(t, reflect.DeepEqual([]string{"/"}, queries))
+
+	templatesRes, apiErr := api.ListTemplates(context.Background())
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, 3, len(templatesRes.Data))
+	assert.Equal(t, 2, len(queries))
+}
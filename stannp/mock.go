@@ -3,26 +3,75 @@ package stannp
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/copilotiq/stannp-client-golang/address"
+	"github.com/copilotiq/stannp-client-golang/files"
 	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/report"
+	"github.com/copilotiq/stannp-client-golang/storage"
+	"github.com/copilotiq/stannp-client-golang/template"
 	"github.com/copilotiq/stannp-client-golang/util"
-	"os"
 )
 
 type MockOption func(*MockClient)
 
+// SentLetter is a request the MockClient received along with the options it resolved to
+type SentLetter struct {
+	Options letter.SendOptions
+	Request letter.SendReq
+}
+
+type sentLetters struct {
+	letters []SentLetter
+	mu      sync.Mutex
+}
+
+// mockFiles is the MockClient's Stannp storage
+type mockFiles struct {
+	files   []files.File
+	folders []files.Folder
+	mu      sync.Mutex
+	nextID  int
+}
+
 type MockClient struct {
-	addressInvalidNext      bool
-	codeNext                int
-	errorMessageNext        string
-	getPDFContentsFailNext  bool
-	getPDFResponseNext      *letter.PDFRes
-	savePDFContentsFailNext bool
-	sendLetterFailNext      bool
-	sendLetterResponseNext  *letter.SendRes
-	validateAddressFailNext bool
+	addressInvalidNext          bool
+	codeNext                    int
+	countryChecks               bool
+	defaultSendOptions          letter.SendOptions
+	errorMessageNext            string
+	files                       *mockFiles
+	filesFailNext               bool
+	getLetterFailNext           bool
+	getLetterResponseNext       *letter.GetRes
+	getPDFContentsFailNext      bool
+	getPDFResponseNext          *letter.PDFRes
+	getTemplateFailNext         bool
+	getTemplateResponseNext     *template.GetRes
+	listLettersFailNext         bool
+	listLettersResponseNext     *report.ListRes
+	listTemplatesFailNext       bool
+	listTemplatesResponseNext   *template.ListRes
+	loadPDFContentsFailNext     bool
+	previewLetterFailNext       bool
+	quoteLetterFailNext         bool
+	quoteLetterResponseNext     *letter.Quote
+	savePDFContentsFailNext     bool
+	sendLetterFailNext          bool
+	sendLetterResponseNext      *letter.SendRes
+	sent                        *sentLetters
+	store                       *storage.MemoryStore
+	validateAddressFailNext     bool
+	validateAddressResponseNext *address.ValidateRes
+	watchLetterEventsNext       []WatchEvent
+	watchLetterFailNext         bool
 }
 
 var _ Client = (*MockClient)(nil)
@@ -39,12 +88,39 @@ func WithCodeNext(codeNext int) MockOption {
 	}
 }
 
+// WithDefaultSendOptions sets the client defaults SendLetter resolves per-request overrides against. Defaults to the
+// same options as New.
+func WithDefaultSendOptions(options letter.SendOptions) MockOption {
+	return func(c *MockClient) {
+		c.defaultSendOptions = options
+	}
+}
+
 func WithErrorMessageNext(errNext string) MockOption {
 	return func(c *MockClient) {
 		c.errorMessageNext = errNext
 	}
 }
 
+// WithFilesFailNext fails every file and folder method
+func WithFilesFailNext(failNext bool) MockOption {
+	return func(c *MockClient) {
+		c.filesFailNext = failNext
+	}
+}
+
+func WithGetLetterFailNext(failNext bool) MockOption {
+	return func(c *MockClient) {
+		c.getLetterFailNext = failNext
+	}
+}
+
+func WithGetLetterResponseNext(res *letter.GetRes) MockOption {
+	return func(c *MockClient) {
+		c.getLetterResponseNext = res
+	}
+}
+
 func WithGetPDFContentsFailNext(failNext bool) MockOption {
 	return func(c *MockClient) {
 		c.getPDFContentsFailNext = failNext
@@ -57,6 +133,73 @@ func WithGetPDFResponseNext(res *letter.PDFRes) MockOption {
 	}
 }
 
+func WithGetTemplateFailNext(failNext bool) MockOption {
+	return func(c *MockClient) {
+		c.getTemplateFailNext = failNext
+	}
+}
+
+func WithGetTemplateResponseNext(res *template.GetRes) MockOption {
+	return func(c *MockClient) {
+		c.getTemplateResponseNext = res
+	}
+}
+
+func WithListLettersFailNext(failNext bool) MockOption {
+	return func(c *MockClient) {
+		c.listLettersFailNext = failNext
+	}
+}
+
+func WithListLettersResponseNext(res *report.ListRes) MockOption {
+	return func(c *MockClient) {
+		c.listLettersResponseNext = res
+	}
+}
+
+func WithListTemplatesFailNext(failNext bool) MockOption {
+	return func(c *MockClient) {
+		c.listTemplatesFailNext = failNext
+	}
+}
+
+func WithListTemplatesResponseNext(res *template.ListRes) MockOption {
+	return func(c *MockClient) {
+		c.listTemplatesResponseNext = res
+	}
+}
+
+func WithLoadPDFContentsFailNext(failNext bool) MockOption {
+	return func(c *MockClient) {
+		c.loadPDFContentsFailNext = failNext
+	}
+}
+
+// WithMockCountryChecks makes SendLetter check the recipient's country like WithCountryChecks does for a real client
+func WithMockCountryChecks(countryChecks bool) MockOption {
+	return func(c *MockClient) {
+		c.countryChecks = countryChecks
+	}
+}
+
+func WithPreviewLetterFailNext(failNext bool) MockOption {
+	return func(c *MockClient) {
+		c.previewLetterFailNext = failNext
+	}
+}
+
+func WithQuoteLetterFailNext(failNext bool) MockOption {
+	return func(c *MockClient) {
+		c.quoteLetterFailNext = failNext
+	}
+}
+
+func WithQuoteLetterResponseNext(res *letter.Quote) MockOption {
+	return func(c *MockClient) {
+		c.quoteLetterResponseNext = res
+	}
+}
+
 func WithSendLetterFailNext(failNext bool) MockOption {
 	return func(c *MockClient) {
 		c.sendLetterFailNext = failNext
@@ -81,8 +224,32 @@ func WithValidateAddressFailNext(failNext bool) MockOption {
 	}
 }
 
+func WithValidateAddressResponseNext(res *address.ValidateRes) MockOption {
+	return func(c *MockClient) {
+		c.validateAddressResponseNext = res
+	}
+}
+
+// WithWatchLetterEventsNext sets the events WatchLetter sends before closing its channel
+func WithWatchLetterEventsNext(events []WatchEvent) MockOption {
+	return func(c *MockClient) {
+		c.watchLetterEventsNext = events
+	}
+}
+
+func WithWatchLetterFailNext(failNext bool) MockOption {
+	return func(c *MockClient) {
+		c.watchLetterFailNext = failNext
+	}
+}
+
 func NewMockClient(opts ...MockOption) *MockClient {
-	client := &MockClient{}
+	client := &MockClient{
+		defaultSendOptions: New().SendOptions(),
+		files:              &mockFiles{},
+		sent:               &sentLetters{},
+		store:              storage.NewMemoryStore(),
+	}
 
 	for _, opt := range opts {
 		opt(client)
@@ -91,19 +258,102 @@ func NewMockClient(opts ...MockOption) *MockClient {
 	return client
 }
 
-func (mc *MockClient) GetPDFContents(_ context.Context, pdfURL string) (*letter.PDFRes, *util.APIError) {
-	if mc.getPDFContentsFailNext {
-		apiErr := util.BuildError(500, "getPDFContentsFailNext is true")
+// failNextError builds the error returned by a method whose failNext option is set, honouring codeNext and errorMessageNext
+func (mc *MockClient) failNextError(errorMessage string) *util.APIError {
+	apiErr := util.BuildError(500, errorMessage)
+
+	if mc.codeNext != 0 {
+		apiErr.Code = mc.codeNext
+	}
 
-		if mc.codeNext != 0 {
-			apiErr.Code = mc.codeNext
+	if mc.errorMessageNext != "" {
+		apiErr.ErrorMessage = mc.errorMessageNext
+	}
+
+	return apiErr
+}
+
+// Storage exposes the in-memory store behind SavePDFContents
+func (mc *MockClient) Storage() *storage.MemoryStore {
+	return mc.store
+}
+
+// CreateFolder adds a folder to the mock's in-memory storage
+func (mc *MockClient) CreateFolder(_ context.Context, name string) (*files.FolderRes, *util.APIError) {
+	if mc.filesFailNext {
+		return nil, mc.failNextError("filesFailNext is true")
+	}
+
+	mc.files.mu.Lock()
+	defer mc.files.mu.Unlock()
+
+	mc.files.nextID++
+	folder := files.Folder{ID: json.Number(strconv.Itoa(mc.files.nextID)), Name: name}
+	mc.files.folders = append(mc.files.folders, folder)
+	return &files.FolderRes{Data: folder, Success: true}, nil
+}
+
+func (mc *MockClient) DeleteFile(_ context.Context, fileID string) (*files.DeleteRes, *util.APIError) {
+	if mc.filesFailNext {
+		return nil, mc.failNextError("filesFailNext is true")
+	}
+
+	mc.files.mu.Lock()
+	defer mc.files.mu.Unlock()
+
+	for i, file := range mc.files.files {
+		if file.ID.String() == fileID {
+			mc.files.files = append(mc.files.files[:i], mc.files.files[i+1:]...)
+			return &files.DeleteRes{Success: true}, nil
 		}
+	}
 
-		if mc.errorMessageNext != "" {
-			apiErr.ErrorMessage = mc.errorMessageNext
+	return nil, util.BuildError(404, fmt.Sprintf("file [%s] not found", fileID))
+}
+
+func (mc *MockClient) DeleteFolder(_ context.Context, folderID string) (*files.DeleteRes, *util.APIError) {
+	if mc.filesFailNext {
+		return nil, mc.failNextError("filesFailNext is true")
+	}
+
+	mc.files.mu.Lock()
+	defer mc.files.mu.Unlock()
+
+	for i, folder := range mc.files.folders {
+		if folder.ID.String() == folderID {
+			mc.files.folders = append(mc.files.folders[:i], mc.files.folders[i+1:]...)
+			return &files.DeleteRes{Success: true}, nil
 		}
+	}
 
-		return nil, apiErr
+	return nil, util.BuildError(404, fmt.Sprintf("folder [%s] not found", folderID))
+}
+
+func (mc *MockClient) GetLetter(_ context.Context, letterID string) (*letter.GetRes, *util.APIError) {
+	if mc.getLetterFailNext {
+		return nil, mc.failNextError("getLetterFailNext is true")
+	}
+
+	if mc.getLetterResponseNext != nil {
+		return mc.getLetterResponseNext, nil
+	}
+
+	return &letter.GetRes{
+		Data: letter.Data{
+			Cost:    "0.84",
+			Created: time.Now().UTC().Format("2006-01-02 15:04:05"),
+			Format:  util.RandomString(10),
+			ID:      json.Number(letterID),
+			PDFURL:  util.RandomString(10),
+			Status:  string(letter.LetterStatusReceived),
+		},
+		Success: true,
+	}, nil
+}
+
+func (mc *MockClient) GetPDFContents(_ context.Context, pdfURL string) (*letter.PDFRes, *util.APIError) {
+	if mc.getPDFContentsFailNext {
+		return nil, mc.failNextError("getPDFContentsFailNext is true")
 	}
 
 	if mc.getPDFResponseNext != nil {
@@ -116,36 +366,227 @@ func (mc *MockClient) GetPDFContents(_ context.Context, pdfURL string) (*letter.
 	}, nil
 }
 
-func (mc *MockClient) SavePDFContents(_ io.Reader) (*os.File, *util.APIError) {
-	if mc.savePDFContentsFailNext {
-		apiErr := util.BuildError(500, "savePDFContentsFailNext is true")
+func (mc *MockClient) GetTemplate(_ context.Context, templateID string) (*template.GetRes, *util.APIError) {
+	if mc.getTemplateFailNext {
+		return nil, mc.failNextError("getTemplateFailNext is true")
+	}
 
-		if mc.codeNext != 0 {
-			apiErr.Code = mc.codeNext
-		}
+	if mc.getTemplateResponseNext != nil {
+		return mc.getTemplateResponseNext, nil
+	}
 
-		if mc.errorMessageNext != "" {
-			apiErr.ErrorMessage = mc.errorMessageNext
+	return &template.GetRes{
+		Data: template.Data{
+			ID:   json.Number(templateID),
+			Name: util.RandomString(10),
+			Size: "US-LETTER",
+		},
+		Success: true,
+	}, nil
+}
+
+// IterateFiles pages through what ListFiles returns
+func (mc *MockClient) IterateFiles(ctx context.Context, folderID string, opts ...IteratorOption) *Iterator[files.File] {
+	return NewIterator(ctx, listFetcher(func(ctx context.Context) ([]files.File, *util.APIError) {
+		listRes, listErr := mc.ListFiles(ctx, folderID)
+		if listErr != nil {
+			return nil, listErr
 		}
 
-		return nil, apiErr
-	}
-	return &os.File{}, nil
+		return listRes.Data, nil
+	}), opts...)
 }
 
-func (mc *MockClient) SendLetter(_ context.Context, _ *letter.SendReq) (*letter.SendRes, *util.APIError) {
-	if mc.sendLetterFailNext {
-		apiErr := util.BuildError(500, "sendLetterFailNext is true")
+// IterateLetters pages through what ListLetters returns
+func (mc *MockClient) IterateLetters(ctx context.Context, req *report.ListReq, opts ...IteratorOption) *Iterator[letter.Data] {
+	return NewIterator(ctx, listFetcher(func(ctx context.Context) ([]letter.Data, *util.APIError) {
+		listRes, listErr := mc.ListLetters(ctx, req)
+		if listErr != nil {
+			return nil, listErr
+		}
+
+		return listRes.Data, nil
+	}), opts...)
+}
 
-		if mc.codeNext != 0 {
-			apiErr.Code = mc.codeNext
+// IterateTemplates pages through what ListTemplates returns
+func (mc *MockClient) IterateTemplates(ctx context.Context, opts ...IteratorOption) *Iterator[template.Data] {
+	return NewIterator(ctx, listFetcher(func(ctx context.Context) ([]template.Data, *util.APIError) {
+		templatesRes, listErr := mc.ListTemplates(ctx)
+		if listErr != nil {
+			return nil, listErr
 		}
 
-		if mc.errorMessageNext != "" {
-			apiErr.ErrorMessage = mc.errorMessageNext
+		return templatesRes.Data, nil
+	}), opts...)
+}
+
+func (mc *MockClient) ListFiles(_ context.Context, folderID string) (*files.ListRes, *util.APIError) {
+	if mc.filesFailNext {
+		return nil, mc.failNextError("filesFailNext is true")
+	}
+
+	mc.files.mu.Lock()
+	defer mc.files.mu.Unlock()
+
+	storedFiles := []files.File{}
+	for _, file := range mc.files.files {
+		if folderID == "" || file.FolderID.String() == folderID {
+			storedFiles = append(storedFiles, file)
 		}
+	}
+
+	return &files.ListRes{Data: storedFiles, Success: true}, nil
+}
+
+func (mc *MockClient) ListFolders(_ context.Context) (*files.FoldersRes, *util.APIError) {
+	if mc.filesFailNext {
+		return nil, mc.failNextError("filesFailNext is true")
+	}
+
+	mc.files.mu.Lock()
+	defer mc.files.mu.Unlock()
+
+	return &files.FoldersRes{Data: append([]files.Folder{}, mc.files.folders...), Success: true}, nil
+}
+
+func (mc *MockClient) ListLetters(_ context.Context, req *report.ListReq) (*report.ListRes, *util.APIError) {
+	if mc.listLettersFailNext {
+		return nil, mc.failNextError("listLettersFailNext is true")
+	}
 
-		return nil, apiErr
+	if req == nil {
+		return nil, util.BuildError(400, "request must not be nil")
+	}
+
+	if validateErr := req.Validate(); validateErr != nil {
+		return nil, validateErr
+	}
+
+	if mc.listLettersResponseNext != nil {
+		return mc.listLettersResponseNext, nil
+	}
+
+	return &report.ListRes{
+		Data:    []letter.Data{},
+		Success: true,
+	}, nil
+}
+
+func (mc *MockClient) ListTemplates(_ context.Context) (*template.ListRes, *util.APIError) {
+	if mc.listTemplatesFailNext {
+		return nil, mc.failNextError("listTemplatesFailNext is true")
+	}
+
+	if mc.listTemplatesResponseNext != nil {
+		return mc.listTemplatesResponseNext, nil
+	}
+
+	return &template.ListRes{
+		Data:    []template.Data{},
+		Success: true,
+	}, nil
+}
+
+// LoadPDFContents reads back a PDF saved with SavePDFContents
+func (mc *MockClient) LoadPDFContents(ctx context.Context, letterID string) (*letter.PDFRes, *util.APIError) {
+	if mc.loadPDFContentsFailNext {
+		return nil, mc.failNextError("loadPDFContentsFailNext is true")
+	}
+
+	if idErr := storage.ValidateLetterID(letterID); idErr != nil {
+		return nil, idErr
+	}
+
+	key := storage.LetterKey(letterID)
+	contents, metadata, openErr := mc.store.Open(ctx, key)
+	if openErr != nil {
+		return nil, openErr
+	}
+
+	return &letter.PDFRes{
+		Checksum:      metadata.Checksum,
+		ContentLength: metadata.ContentLength,
+		Contents:      contents,
+		Name:          key,
+	}, nil
+}
+
+// PreviewLetter returns getPDFResponseNext when set, otherwise a PDFRes whose contents are the template name
+func (mc *MockClient) PreviewLetter(_ context.Context, req *letter.SendReq) (*letter.PDFRes, *util.APIError) {
+	if mc.previewLetterFailNext {
+		return nil, mc.failNextError("previewLetterFailNext is true")
+	}
+
+	if mc.getPDFResponseNext != nil {
+		return mc.getPDFResponseNext, nil
+	}
+
+	return &letter.PDFRes{
+		Contents: io.NopCloser(bytes.NewBufferString(req.Template)),
+		Name:     req.Template,
+	}, nil
+}
+
+// QuoteLetter prices a single sheet letter from the default US price table unless a response is pre-defined
+func (mc *MockClient) QuoteLetter(_ context.Context, req *letter.SendReq) (*letter.Quote, *util.APIError) {
+	if mc.quoteLetterFailNext {
+		return nil, mc.failNextError("quoteLetterFailNext is true")
+	}
+
+	if mc.quoteLetterResponseNext != nil {
+		return mc.quoteLetterResponseNext, nil
+	}
+
+	table, _ := letter.DefaultPriceTable(letter.RegionUS)
+	quote, quoteErr := table.Quote(req.Mail, 1, false)
+	if quoteErr != nil {
+		return nil, quoteErr
+	}
+
+	quote.Options = req.Options(mc.defaultSendOptions)
+	quote.Pages = 1
+	return quote, nil
+}
+
+// SavePDFContents keeps the PDF in memory so tests can read it back with LoadPDFContents or Storage
+func (mc *MockClient) SavePDFContents(ctx context.Context, letterID string, pdfContents io.Reader) (*storage.Metadata, *util.APIError) {
+	if mc.savePDFContentsFailNext {
+		return nil, mc.failNextError("savePDFContentsFailNext is true")
+	}
+
+	if idErr := storage.ValidateLetterID(letterID); idErr != nil {
+		return nil, idErr
+	}
+
+	if pdfContents == nil {
+		pdfContents = bytes.NewReader(nil)
+	}
+
+	return mc.store.Save(ctx, storage.LetterKey(letterID), pdfContents, &storage.Metadata{LetterID: letterID})
+}
+
+// SentLetters returns every request SendLetter has received, oldest first, including those it was told to fail
+func (mc *MockClient) SentLetters() []SentLetter {
+	mc.sent.mu.Lock()
+	defer mc.sent.mu.Unlock()
+
+	return append([]SentLetter{}, mc.sent.letters...)
+}
+
+func (mc *MockClient) SendLetter(_ context.Context, req *letter.SendReq) (*letter.SendRes, *util.APIError) {
+	mc.sent.mu.Lock()
+	mc.sent.letters = append(mc.sent.letters, SentLetter{Options: req.Options(mc.defaultSendOptions), Request: *req})
+	mc.sent.mu.Unlock()
+
+	if mc.sendLetterFailNext {
+		return nil, mc.failNextError("sendLetterFailNext is true")
+	}
+
+	if mc.countryChecks {
+		if _, countryErr := checkCountry(req); countryErr != nil {
+			return nil, countryErr
+		}
 	}
 
 	if mc.sendLetterResponseNext != nil {
@@ -154,42 +595,121 @@ func (mc *MockClient) SendLetter(_ context.Context, _ *letter.SendReq) (*letter.
 
 	return &letter.SendRes{
 		Data: letter.Data{
-			Cost:    util.RandomString(10),
-			Created: util.RandomString(10),
+			Cost:    "0.84", // parseable by the typed accessors
+			Created: time.Now().UTC().Format("2006-01-02 15:04:05"),
 			Format:  util.RandomString(10),
 			ID:      "0",
 			PDFURL:  util.RandomString(10),
-			Status:  "received",
+			Status:  string(letter.LetterStatusReceived),
 		},
-		Success: true,
+		MailOptions: req.Mail,
+		Success:     true,
 	}, nil
 }
 
-func (mc *MockClient) ValidateAddress(_ context.Context, _ *address.ValidateReq) (*address.ValidateRes, *util.APIError) {
-	if mc.validateAddressFailNext {
-		apiErr := util.BuildError(500, "validateAddressFailNext is true")
+// SummarizeLetters summarizes what ListLetters returns, in the default US region's currency
+func (mc *MockClient) SummarizeLetters(ctx context.Context, req *report.ListReq) (*report.Summary, *util.APIError) {
+	listRes, listErr := mc.ListLetters(ctx, req)
+	if listErr != nil {
+		return nil, listErr
+	}
 
-		if mc.codeNext != 0 {
-			apiErr.Code = mc.codeNext
-		}
+	return req.Summarize(listRes.Data, letter.RegionUS.Currency()), nil
+}
 
-		if mc.errorMessageNext != "" {
-			apiErr.ErrorMessage = mc.errorMessageNext
-		}
+// UploadFile reads req's contents, reporting progress, and keeps the file in the mock's in-memory storage
+func (mc *MockClient) UploadFile(_ context.Context, req *files.UploadReq) (*files.UploadRes, *util.APIError) {
+	if mc.filesFailNext {
+		return nil, mc.failNextError("filesFailNext is true")
+	}
+
+	if validateErr := req.Validate(); validateErr != nil {
+		return nil, validateErr
+	}
 
-		return nil, apiErr
+	size, err := io.Copy(io.Discard, req.Reader())
+	if err != nil {
+		return nil, util.BuildError(500, err.Error())
+	}
+
+	mc.files.mu.Lock()
+	defer mc.files.mu.Unlock()
+
+	mc.files.nextID++
+	id := strconv.Itoa(mc.files.nextID)
+	file := files.File{
+		FolderID: json.Number(req.FolderID),
+		ID:       json.Number(id),
+		Name:     req.Name,
+		Size:     json.Number(strconv.FormatInt(size, 10)),
+		URL:      strings.Join([]string{PDFURLPrefix, id, req.Name}, "/"),
+	}
+	mc.files.files = append(mc.files.files, file)
+	return &files.UploadRes{Data: file, Success: true}, nil
+}
+
+// ValidateAddress echoes req back as the standardized address unless a response is pre-defined
+func (mc *MockClient) ValidateAddress(_ context.Context, req *address.ValidateReq) (*address.ValidateRes, *util.APIError) {
+	if mc.validateAddressFailNext {
+		return nil, mc.failNextError("validateAddressFailNext is true")
+	}
+
+	if mc.validateAddressResponseNext != nil {
+		return mc.validateAddressResponseNext, nil
 	}
 
 	validateRes := &address.ValidateRes{
 		Data: address.Data{
-			IsValid: true,
+			Address1:       req.Address1,
+			Address2:       req.Address2,
+			City:           req.City,
+			Company:        req.Company,
+			Country:        req.Country,
+			Deliverability: address.Deliverable,
+			IsValid:        true,
+			State:          req.State,
+			Zipcode:        req.Zipcode,
 		},
 		Success: true,
 	}
 
 	if mc.addressInvalidNext {
+		validateRes.Data.Deliverability = address.Undeliverable
 		validateRes.Data.IsValid = false
 	}
 
 	return validateRes, nil
 }
+
+// WatchLetter sends watchLetterEventsNext when set, otherwise a single change to GetLetter's status, then closes the
+// channel
+func (mc *MockClient) WatchLetter(ctx context.Context, letterID string, opts WatchOptions) (<-chan WatchEvent, *util.APIError) {
+	if mc.watchLetterFailNext {
+		return nil, mc.failNextError("watchLetterFailNext is true")
+	}
+
+	events := mc.watchLetterEventsNext
+	if events == nil {
+		getRes, getErr := mc.GetLetter(ctx, letterID)
+		event := WatchEvent{Err: getErr, LetterID: letterID}
+		if getErr == nil {
+			event.Change = letter.StatusChange{At: time.Now().UTC(), From: opts.Last, To: getRes.Data.LetterStatus()}
+			event.Data = getRes.Data
+		}
+		events = []WatchEvent{event}
+	}
+
+	eventCh := make(chan WatchEvent)
+	go func() {
+		defer close(eventCh)
+		for _, event := range events {
+			select {
+			case <-ctx.Done():
+				return
+			case eventCh <- event:
+			}
+		}
+	}()
+
+	return eventCh, nil
+}
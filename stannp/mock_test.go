@@ -7,9 +7,13 @@ import (
 	"io"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/copilotiq/stannp-client-golang/address"
+	"github.com/copilotiq/stannp-client-golang/files"
 	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/report"
+	"github.com/copilotiq/stannp-client-golang/template"
 	"github.com/copilotiq/stannp-client-golang/util"
 	"github.com/jgroeneveld/trial/assert"
 )
@@ -81,6 +85,113 @@ func TestMockClient_GetPDFContents(t *testing.T) {
 	}
 }
 
+func TestMockClient_GetTemplate(t *testing.T) {
+	tests := []struct {
+		name              string
+		mockClientOptions []MockOption
+		expectedName      string
+		expectedError     *util.APIError
+	}{
+		{
+			name: "success expected with template res pre-defined",
+			mockClientOptions: []MockOption{WithGetTemplateResponseNext(
+				&template.GetRes{
+					Data: template.Data{
+						ID:          "1",
+						MergeFields: []string{"appointment_date"},
+						Name:        "reminder",
+						Size:        "US-LETTER",
+					},
+					Success: true,
+				},
+			)},
+			expectedName:  "reminder",
+			expectedError: nil,
+		},
+		{
+			name:              "success not expected err expected",
+			mockClientOptions: []MockOption{WithGetTemplateFailNext(true)},
+			expectedError:     util.BuildError(500, "getTemplateFailNext is true"),
+		},
+		{
+			name: "err expected code expected custom err expected",
+			mockClientOptions: []MockOption{
+				WithCodeNext(404),
+				WithErrorMessageNext("custom message"),
+				WithGetTemplateFailNext(true),
+			},
+			expectedError: util.BuildError(404, "custom message"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := NewMockClient(tt.mockClientOptions...)
+			templateRes, apiErr := mockClient.GetTemplate(context.Background(), "1")
+
+			if tt.expectedError != nil {
+				assert.NotNil(t, apiErr)
+				assert.Equal(t, *tt.expectedError, *apiErr)
+				assert.True(t, reflect.ValueOf(templateRes).IsNil())
+			} else {
+				assert.True(t, reflect.ValueOf(apiErr).IsNil())
+				assert.True(t, templateRes.Success)
+				assert.Equal(t, tt.expectedName, templateRes.Data.Name)
+				assert.Equal(t, json.Number("1"), templateRes.Data.ID)
+			}
+		})
+	}
+}
+
+func TestMockClient_ListTemplates(t *testing.T) {
+	tests := []struct {
+		name              string
+		mockClientOptions []MockOption
+		expectedCount     int
+		expectedError     *util.APIError
+	}{
+		{
+			name:              "success expected with default res",
+			mockClientOptions: []MockOption{},
+			expectedCount:     0,
+			expectedError:     nil,
+		},
+		{
+			name: "success expected with templates res pre-defined",
+			mockClientOptions: []MockOption{WithListTemplatesResponseNext(
+				&template.ListRes{
+					Data:    []template.Data{{ID: "1"}, {ID: "2"}},
+					Success: true,
+				},
+			)},
+			expectedCount: 2,
+			expectedError: nil,
+		},
+		{
+			name:              "success not expected err expected",
+			mockClientOptions: []MockOption{WithListTemplatesFailNext(true)},
+			expectedError:     util.BuildError(500, "listTemplatesFailNext is true"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := NewMockClient(tt.mockClientOptions...)
+			templatesRes, apiErr := mockClient.ListTemplates(context.Background())
+
+			if tt.expectedError != nil {
+				assert.NotNil(t, apiErr)
+				assert.Equal(t, *tt.expectedError, *apiErr)
+				assert.True(t, reflect.ValueOf(templatesRes).IsNil())
+			} else {
+				assert.True(t, reflect.ValueOf(apiErr).IsNil())
+				assert.True(t, templatesRes.Success)
+				assert.Equal(t, tt.expectedCount, len(templatesRes.Data))
+			}
+		})
+	}
+}
+
 func TestMockClient_NewMockClient(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -134,6 +245,34 @@ func TestMockClient_NewMockClient(t *testing.T) {
 				Name:     "with getPDFResponseNext",
 			}},
 		},
+		{
+			name: "with getTemplateFailNext",
+			opts: []MockOption{
+				WithGetTemplateFailNext(true),
+			},
+			expect: MockClient{getTemplateFailNext: true},
+		},
+		{
+			name: "with listTemplatesFailNext",
+			opts: []MockOption{
+				WithListTemplatesFailNext(true),
+			},
+			expect: MockClient{listTemplatesFailNext: true},
+		},
+		{
+			name: "with loadPDFContentsFailNext",
+			opts: []MockOption{
+				WithLoadPDFContentsFailNext(true),
+			},
+			expect: MockClient{loadPDFContentsFailNext: true},
+		},
+		{
+			name: "with previewLetterFailNext",
+			opts: []MockOption{
+				WithPreviewLetterFailNext(true),
+			},
+			expect: MockClient{previewLetterFailNext: true},
+		},
 		{
 			name: "with sendLetterFailNext",
 			opts: []MockOption{
@@ -249,6 +388,10 @@ func TestMockClient_NewMockClient(t *testing.T) {
 			assert.Equal(t, tt.expect.codeNext, client.codeNext)
 			assert.Equal(t, tt.expect.errorMessageNext, client.errorMessageNext)
 			assert.Equal(t, tt.expect.getPDFContentsFailNext, client.getPDFContentsFailNext)
+			assert.Equal(t, tt.expect.getTemplateFailNext, client.getTemplateFailNext)
+			assert.Equal(t, tt.expect.listTemplatesFailNext, client.listTemplatesFailNext)
+			assert.Equal(t, tt.expect.loadPDFContentsFailNext, client.loadPDFContentsFailNext)
+			assert.Equal(t, tt.expect.previewLetterFailNext, client.previewLetterFailNext)
 			assert.Equal(t, tt.expect.savePDFContentsFailNext, client.savePDFContentsFailNext)
 			assert.Equal(t, tt.expect.sendLetterFailNext, client.sendLetterFailNext)
 			assert.Equal(t, tt.expect.validateAddressFailNext, client.validateAddressFailNext)
@@ -270,6 +413,112 @@ func TestMockClient_NewMockClient(t *testing.T) {
 	}
 }
 
+func TestMockClient_LoadPDFContents(t *testing.T) {
+	mockClient := NewMockClient()
+
+	_, apiErr := mockClient.LoadPDFContents(context.Background(), "missing")
+	assert.Equal(t, 404, apiErr.Code)
+
+	mockClient = NewMockClient(WithLoadPDFContentsFailNext(true))
+	_, apiErr = mockClient.LoadPDFContents(context.Background(), "missing")
+	assert.Equal(t, *util.BuildError(500, "loadPDFContentsFailNext is true"), *apiErr)
+}
+
+func TestMockClient_PreviewLetter(t *testing.T) {
+	tests := []struct {
+		name              string
+		mockClientOptions []MockOption
+		expectedContents  string
+		expectedError     *util.APIError
+	}{
+		{
+			name:              "success expected with default res",
+			mockClientOptions: []MockOption{},
+			expectedContents:  "307051",
+			expectedError:     nil,
+		},
+		{
+			name: "success expected with pdf res pre-defined",
+			mockClientOptions: []MockOption{WithGetPDFResponseNext(
+				&letter.PDFRes{
+					Contents: io.NopCloser(bytes.NewBufferString("pre-defined")),
+					Name:     "pre-defined",
+				},
+			)},
+			expectedContents: "pre-defined",
+			expectedError:    nil,
+		},
+		{
+			name:              "success not expected err expected",
+			mockClientOptions: []MockOption{WithPreviewLetterFailNext(true)},
+			expectedError:     util.BuildError(500, "previewLetterFailNext is true"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := NewMockClient(tt.mockClientOptions...)
+			pdfRes, apiErr := mockClient.PreviewLetter(context.Background(), &letter.SendReq{Template: "307051"})
+
+			if tt.expectedError != nil {
+				assert.NotNil(t, apiErr)
+				assert.Equal(t, *tt.expectedError, *apiErr)
+				assert.True(t, reflect.ValueOf(pdfRes).IsNil())
+			} else {
+				assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+				contents, err := io.ReadAll(pdfRes.Contents)
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectedContents, string(contents))
+			}
+		})
+	}
+}
+
+func TestMockClient_QuoteLetter(t *testing.T) {
+	tests := []struct {
+		name              string
+		mockClientOptions []MockOption
+		expectedTotal     int64
+		expectedError     *util.APIError
+	}{
+		{
+			name:              "success expected with default res",
+			mockClientOptions: []MockOption{},
+			expectedTotal:     55 + 73,
+			expectedError:     nil,
+		},
+		{
+			name:              "success expected with quote pre-defined",
+			mockClientOptions: []MockOption{WithQuoteLetterResponseNext(&letter.Quote{Currency: "USD", Total: 1000})},
+			expectedTotal:     1000,
+			expectedError:     nil,
+		},
+		{
+			name:              "success not expected err expected",
+			mockClientOptions: []MockOption{WithQuoteLetterFailNext(true), WithCodeNext(402)},
+			expectedError:     util.BuildError(402, "quoteLetterFailNext is true"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := NewMockClient(tt.mockClientOptions...)
+			quote, apiErr := mockClient.QuoteLetter(context.Background(), &letter.SendReq{Template: "307051"})
+
+			if tt.expectedError != nil {
+				assert.NotNil(t, apiErr)
+				assert.Equal(t, *tt.expectedError, *apiErr)
+				assert.True(t, reflect.ValueOf(quote).IsNil())
+			} else {
+				assert.True(t, reflect.ValueOf(apiErr).IsNil())
+				assert.Equal(t, tt.expectedTotal, quote.Total)
+				assert.Equal(t, "USD", quote.Currency)
+			}
+		})
+	}
+}
+
 func TestMockClient_SavePDFContents(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -304,15 +553,23 @@ func TestMockClient_SavePDFContents(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := NewMockClient(tt.mockClientOptions...)
-			localFile, apiErr := mockClient.SavePDFContents(nil)
+			metadata, apiErr := mockClient.SavePDFContents(context.Background(), "12345", bytes.NewBufferString("%PDF-1.4"))
 
 			if tt.expectedError != nil {
 				assert.NotNil(t, apiErr)
 				assert.Equal(t, *tt.expectedError, *apiErr)
-				assert.True(t, reflect.ValueOf(localFile).IsNil())
+				assert.True(t, reflect.ValueOf(metadata).IsNil())
 			} else {
 				assert.True(t, reflect.ValueOf(apiErr).IsNil())
-				assert.NotNil(t, localFile)
+				assert.Equal(t, "stannp_letter_12345.pdf", metadata.Key)
+				assert.Equal(t, int64(8), metadata.ContentLength)
+
+				pdfRes, loadErr := mockClient.LoadPDFContents(context.Background(), "12345")
+				assert.True(t, reflect.ValueOf(loadErr).IsNil())
+
+				contents, readErr := io.ReadAll(pdfRes.Contents)
+				assert.Nil(t, readErr)
+				assert.Equal(t, "%PDF-1.4", string(contents))
 			}
 		})
 	}
@@ -387,6 +644,41 @@ func TestMockClient_SendLetter(t *testing.T) {
 	}
 }
 
+func TestMockClient_SendLetterCountryChecks(t *testing.T) {
+	atlantis := &letter.SendReq{Recipient: letter.RecipientDetails{Address1: "1 Main St", Country: "Atlantis", Zipcode: "1"}}
+
+	_, apiErr := NewMockClient().SendLetter(context.Background(), atlantis)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	_, apiErr = NewMockClient(WithMockCountryChecks(true)).SendLetter(context.Background(), atlantis)
+	assert.Equal(t, 400, apiErr.Code)
+	assert.Equal(t, address.InvalidErrorType, apiErr.Type)
+}
+
+func TestMockClient_SentLetters(t *testing.T) {
+	mockClient := NewMockClient(WithDefaultSendOptions(letter.SendOptions{ClearZone: true, Duplex: true, Test: false}))
+
+	_, _ = mockClient.SendLetter(context.Background(), &letter.SendReq{Template: "defaults"})
+	_, _ = mockClient.SendLetter(context.Background(), &letter.SendReq{
+		Duplex:         letter.Bool(false),
+		PostUnverified: letter.Bool(true),
+		Template:       "overrides",
+		Test:           letter.Bool(true),
+	})
+
+	sent := mockClient.SentLetters()
+	assert.Equal(t, 2, len(sent))
+	assert.Equal(t, "defaults", sent[0].Request.Template)
+	assert.Equal(t, letter.SendOptions{ClearZone: true, Duplex: true, PostUnverified: false, Test: false}, sent[0].Options)
+	assert.Equal(t, "overrides", sent[1].Request.Template)
+	assert.Equal(t, letter.SendOptions{ClearZone: true, Duplex: false, PostUnverified: true, Test: true}, sent[1].Options)
+
+	// failed sends are still recorded
+	mockClient = NewMockClient(WithSendLetterFailNext(true))
+	_, _ = mockClient.SendLetter(context.Background(), &letter.SendReq{Template: "failed"})
+	assert.Equal(t, New().SendOptions(), mockClient.SentLetters()[0].Options)
+}
+
 func TestMockClient_ValidateAddress(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -412,6 +704,15 @@ func TestMockClient_ValidateAddress(t *testing.T) {
 			isValidExpected:   false,
 			errExpected:       util.BuildError(500, "validateAddressFailNext is true"),
 		},
+		{
+			name: "valid expected with validate res pre-defined",
+			mockClientOptions: []MockOption{WithValidateAddressResponseNext(&address.ValidateRes{
+				Data:    address.Data{Deliverability: address.DeliverableMissingUnit, IsValid: true},
+				Success: true,
+			})},
+			isValidExpected: true,
+			errExpected:     nil,
+		},
 		{
 			name: "fail next code next err next",
 			mockClientOptions: []MockOption{
@@ -445,6 +746,90 @@ func TestMockClient_ValidateAddress(t *testing.T) {
 	}
 }
 
+func TestMockClient_SummarizeLetters(t *testing.T) {
+	mockClient := NewMockClient(WithListLettersResponseNext(&report.ListRes{
+		Data:    []letter.Data{{Cost: "0.84", ID: "1", Status: "delivered"}, {Cost: "0.84", ID: "2", Status: "returned"}},
+		Success: true,
+	}))
+
+	summary, apiErr := mockClient.SummarizeLetters(context.Background(), report.Month(2024, time.May))
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, 2, summary.Count)
+	assert.Equal(t, letter.Money{Amount: 168, Currency: "USD"}, summary.Cost)
+	assert.Equal(t, 1, summary.ByStatus[letter.LetterStatusReturned].Count)
+
+	mockClient = NewMockClient(WithListLettersFailNext(true))
+	_, apiErr = mockClient.ListLetters(context.Background(), report.Month(2024, time.May))
+	assert.Equal(t, 500, apiErr.Code)
+}
+
+func TestMockClient_WatchLetter(t *testing.T) {
+	mockClient := NewMockClient()
+	events, apiErr := mockClient.WatchLetter(context.Background(), "7", WatchOptions{})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	var received []WatchEvent
+	for event := range events {
+		received = append(received, event)
+	}
+	assert.Equal(t, 1, len(received))
+	assert.Equal(t, letter.LetterStatusReceived, received[0].Change.To)
+	assert.Equal(t, "7", received[0].Data.LetterID().String())
+
+	delivered := WatchEvent{Change: letter.StatusChange{From: letter.LetterStatusReceived, To: letter.LetterStatusDelivered}, LetterID: "7"}
+	mockClient = NewMockClient(WithWatchLetterEventsNext([]WatchEvent{delivered}))
+	events, _ = mockClient.WatchLetter(context.Background(), "7", WatchOptions{})
+	assert.Equal(t, letter.LetterStatusDelivered, (<-events).Change.To)
+
+	mockClient = NewMockClient(WithWatchLetterFailNext(true), WithCodeNext(503))
+	_, apiErr = mockClient.WatchLetter(context.Background(), "7", WatchOptions{})
+	assert.Equal(t, 503, apiErr.Code)
+
+	mockClient = NewMockClient(WithGetLetterFailNext(true))
+	_, apiErr = mockClient.GetLetter(context.Background(), "7")
+	assert.Equal(t, 500, apiErr.Code)
+}
+
+func TestMockClient_Files(t *testing.T) {
+	ctx := context.Background()
+	mockClient := NewMockClient()
+
+	folderRes, _ := mockClient.CreateFolder(ctx, "inserts")
+	folderID := folderRes.Data.ID.String()
+
+	var lastSent int64
+	uploadRes, apiErr := mockClient.UploadFile(ctx, &files.UploadReq{
+		Contents: bytes.NewBufferString("%PDF-1.4"),
+		FolderID: folderID,
+		Name:     "insert.pdf",
+		Progress: func(sent, _ int64) { lastSent = sent },
+	})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, int64(8), lastSent)
+	_, _ = mockClient.UploadFile(ctx, &files.UploadReq{Contents: bytes.NewBufferString("png"), Name: "logo.png"})
+
+	listRes, _ := mockClient.ListFiles(ctx, folderID)
+	assert.Equal(t, 1, len(listRes.Data))
+	assert.Equal(t, json.Number("8"), listRes.Data[0].Size)
+
+	allFiles, _ := mockClient.IterateFiles(ctx, "", WithPageSize(1)).Collect(0)
+	assert.Equal(t, 2, len(allFiles))
+
+	_, apiErr = mockClient.DeleteFile(ctx, uploadRes.Data.ID.String())
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	_, apiErr = mockClient.DeleteFile(ctx, uploadRes.Data.ID.String())
+	assert.Equal(t, 404, apiErr.Code)
+
+	_, apiErr = mockClient.DeleteFolder(ctx, folderID)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	foldersRes, _ := mockClient.ListFolders(ctx)
+	assert.Equal(t, 0, len(foldersRes.Data))
+
+	mockClient = NewMockClient(WithFilesFailNext(true))
+	_, apiErr = mockClient.UploadFile(ctx, &files.UploadReq{Contents: bytes.NewBufferString(""), Name: "logo.png"})
+	assert.Equal(t, 500, apiErr.Code)
+}
+
 func TestInterface(t *testing.T) {
 	newReal := func() Client {
 		return New()
@@ -0,0 +1,155 @@
+package stannp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/copilotiq/stannp-client-golang/address"
+	"github.com/copilotiq/stannp-client-golang/budget"
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// QuoteLetter estimates what SendLetter would charge for request, applying the same client defaults, overrides and
+// mail options. The breakdown comes from the client's price table and nothing is fetched or sent, unless
+// WithQuoteLookups lets it look up the letter's page count. WithRemoteQuotes, the request is also submitted to Stannp
+// as a free test letter and the quote's total is Stannp's price, with any difference from the price table shown as an
+// adjustment line; if that fails the price table's total is used and a warning says why.
+func (s *Stannp) QuoteLetter(ctx context.Context, request *letter.SendReq) (*letter.Quote, *util.APIError) {
+	pages := &pageCount{}
+	quote, request, quoteErr := s.localQuote(ctx, request, pages, s.quoteLookups)
+	if quoteErr != nil {
+		return nil, quoteErr
+	}
+
+	if s.remoteQuotes {
+		if remoteErr := s.reconcileRemoteQuote(ctx, request, quote, pages); remoteErr != nil {
+			quote.Warnings = append(quote.Warnings, fmt.Sprintf("unable to get a price from Stannp, using the price table with err [%s]", remoteErr.ErrorMessage))
+		}
+	}
+
+	return quote, nil
+}
+
+// localQuote prices request from the price table, returning the request as it would be sent. lookups allows fetching
+// the template and attachments to count the letter's pages.
+func (s *Stannp) localQuote(ctx context.Context, request *letter.SendReq, pages *pageCount, lookups bool) (*letter.Quote, *letter.SendReq, *util.APIError) {
+	options := request.Options(s.SendOptions())
+
+	if mailErr := request.Mail.Validate(s.region); mailErr != nil {
+		return nil, nil, mailErr
+	}
+
+	if s.countryChecks {
+		var countryErr *util.APIError
+		request, countryErr = checkCountry(request)
+		if countryErr != nil {
+			return nil, nil, countryErr
+		}
+	}
+
+	table, ok := s.priceTableFor()
+	if !ok {
+		return nil, nil, util.BuildError(400, fmt.Sprintf("no price table for region [%s]", s.region))
+	}
+
+	pageTotal, warnings, countErr := pages.count(ctx, s, request, options.Duplex, lookups)
+	if countErr != nil {
+		return nil, nil, countErr
+	}
+
+	sheets := letter.Sheets(pageTotal, options.Duplex)
+	if pageTotal == 0 {
+		sheets = 1
+		warnings = append(warnings, "page count unknown, quoting a single sheet")
+	}
+
+	quote, quoteErr := table.Quote(request.Mail, sheets, s.isInternational(request.Recipient))
+	if quoteErr != nil {
+		return nil, nil, quoteErr
+	}
+
+	quote.Options = options
+	quote.Pages = pageTotal
+	quote.Warnings = warnings
+	return quote, request, nil
+}
+
+// reserveBudget holds the letter's estimated price against its budgets, or the budget's fallback estimate with a
+// warning when the letter can't be priced. Test letters aren't charged, so they are never counted or refused. The
+// template is only looked up for a letter with attachments, whose pages the send counts anyway; otherwise the letter
+// is priced from a cached page count or as a single sheet.
+func (s *Stannp) reserveBudget(ctx context.Context, request *letter.SendReq, pages *pageCount) (*budget.Reservation, []string, *util.APIError) {
+	if s.budget == nil || request.Options(s.SendOptions()).Test {
+		return nil, nil, nil
+	}
+
+	var warnings []string
+	estimate := s.budget.FallbackEstimate()
+	if quote, _, quoteErr := s.localQuote(ctx, request, pages, len(request.Attachments) > 0); quoteErr == nil {
+		estimate = quote.Total
+	} else {
+		warnings = append(warnings, fmt.Sprintf("unable to price the letter for its budget, reserving [%d] with err [%s]", estimate, quoteErr.ErrorMessage))
+	}
+
+	reservation, reserveErr := s.budget.Reserve(ctx, request.BudgetKey, estimate)
+	return reservation, warnings, reserveErr
+}
+
+// settleBudget records what a sent letter cost, falling back to the estimate when Stannp's cost can't be read, and
+// releases the reservation of a letter that wasn't sent
+func settleBudget(ctx context.Context, reservation *budget.Reservation, letterRes *letter.SendRes, sendErr *util.APIError) {
+	if sendErr != nil || letterRes == nil || !letterRes.Success {
+		reservation.Release()
+		return
+	}
+
+	cost, parseErr := letterRes.Data.CostAmount("")
+	if parseErr != nil {
+		cost.Amount = reservation.Estimate
+	}
+
+	letterRes.BudgetError = reservation.Commit(ctx, cost.Amount)
+}
+
+func (s *Stannp) priceTableFor() (letter.PriceTable, bool) {
+	if s.priceTable != nil {
+		return *s.priceTable, true
+	}
+
+	return letter.DefaultPriceTable(s.region)
+}
+
+// isInternational is true when the recipient has a country other than the one the client's region posts from
+func (s *Stannp) isInternational(recipient letter.RecipientDetails) bool {
+	home := address.CountryUS
+	if s.region == letter.RegionUK {
+		home = address.CountryGB
+	}
+
+	if country, ok := address.ParseCountry(recipient.Country); ok {
+		return country != home
+	}
+
+	return recipient.Country != "" && recipient.Country != string(home)
+}
+
+// reconcileRemoteQuote prices request with a test letter, which Stannp charges nothing for
+func (s *Stannp) reconcileRemoteQuote(ctx context.Context, request *letter.SendReq, quote *letter.Quote, pages *pageCount) *util.APIError {
+	testReq := *request
+	testReq.IdempotenceyKey = ""
+	testReq.Test = letter.Bool(true)
+
+	letterRes, sendErr := s.sendLetter(ctx, &testReq, pages)
+	if sendErr != nil {
+		return sendErr
+	}
+
+	total, parseErr := letterRes.Data.CostAmount(quote.Currency)
+	if parseErr != nil {
+		return parseErr
+	}
+
+	quote.Reconcile(total.Amount, letter.QuoteSourceStannp)
+	return nil
+}
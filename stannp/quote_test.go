@@ -0,0 +1,232 @@
+package stannp
+
+import (
+	"context"
+	"github.com/copilotiq/stannp-client-golang/budget"
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/jgroeneveld/trial/assert"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestQuoteLetter(t *testing.T) {
+	var created []url.Values
+	var idempotencyKeys []string
+	var lookups int
+	failCreate := false
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/templates/get/42":
+			lookups++
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":42,"pages":3}}`))
+		case "/letters/create":
+			assert.Nil(t, r.ParseForm())
+			created = append(created, r.PostForm)
+			idempotencyKeys = append(idempotencyKeys, r.Header.Get(XIdempotenceyHeaderKey))
+			if failCreate {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"success":false,"error":"unavailable"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":1,"cost":"1.50","status":"test"}}`))
+		default:
+			t.Fatalf("unexpected path [%s]", r.URL.Path)
+		}
+	}
+
+	request := &letter.SendReq{
+		IdempotenceyKey: "live-key",
+		Mail:            letter.MailOptions{AddOns: []letter.AddOn{letter.AddOnCertified}, Colour: letter.ColourBlackAndWhite},
+		Template:        "42",
+		Test:            letter.Bool(false),
+	}
+
+	// by default the quote comes from the price table without fetching or sending anything, so the template's three
+	// pages aren't known and a single sheet is quoted
+	api := newTestAPI(t, handler, WithTest(false))
+	quote, apiErr := api.QuoteLetter(context.Background(), request)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, letter.QuoteSourcePriceTable, quote.Source)
+	assert.Equal(t, int64(35+73+485), quote.Total)
+	assert.Equal(t, 2, len(quote.Warnings))
+	assert.Equal(t, 0, lookups)
+	assert.Equal(t, 0, len(created))
+
+	api = newTestAPI(t, handler, WithQuoteLookups(true), WithTest(false))
+	quote, apiErr = api.QuoteLetter(context.Background(), request)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, int64(45+73+485), quote.Total)
+	assert.Equal(t, 1, lookups)
+	assert.Equal(t, 0, len(created))
+
+	api = newTestAPI(t, handler, WithQuoteLookups(true), WithRemoteQuotes(true), WithTest(false))
+	quote, apiErr = api.QuoteLetter(context.Background(), request)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	assert.Equal(t, letter.QuoteSourceStannp, quote.Source)
+	assert.Equal(t, "USD", quote.Currency)
+	assert.Equal(t, 3, quote.Pages)
+	assert.Equal(t, 2, quote.Sheets)
+	assert.False(t, quote.Options.Test)
+	assert.Equal(t, int64(35+10), quote.Subtotal(letter.QuoteCategoryPrint))
+	assert.Equal(t, int64(73), quote.Subtotal(letter.QuoteCategoryPostage))
+	assert.Equal(t, int64(485), quote.Subtotal(letter.QuoteCategoryAddOn))
+	assert.Equal(t, int64(150-(45+73+485)), quote.Subtotal(letter.QuoteCategoryAdjustment))
+	assert.Equal(t, int64(150), quote.Total)
+
+	// the price is fetched with a test letter that can't collide with the live send
+	assert.Equal(t, 1, len(created))
+	assert.Equal(t, "true", created[0].Get("test"))
+	assert.Equal(t, "certified", created[0].Get("addons"))
+	assert.Equal(t, "", idempotencyKeys[0])
+	assert.Equal(t, "live-key", request.IdempotenceyKey)
+
+	failCreate = true
+	quote, apiErr = api.QuoteLetter(context.Background(), request)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, letter.QuoteSourcePriceTable, quote.Source)
+	assert.Equal(t, int64(45+73+485), quote.Total)
+	assert.Equal(t, 1, len(quote.Warnings))
+
+	table := letter.PriceTable{
+		Currency:     "USD",
+		DefaultClass: letter.MailClassFirst,
+		FirstSheet:   map[letter.Colour]int64{letter.ColourBlackAndWhite: 100},
+		ExtraSheet:   map[letter.Colour]int64{letter.ColourBlackAndWhite: 50},
+		Postage:      map[letter.MailClass]int64{letter.MailClassFirst: 200},
+		AddOns:       map[letter.AddOn]int64{letter.AddOnCertified: 300},
+	}
+	api = newTestAPI(t, handler, WithPriceTable(table), WithQuoteLookups(true))
+	created = nil
+	quote, apiErr = api.QuoteLetter(context.Background(), request)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, int64(100+50+200+300), quote.Total)
+	assert.Equal(t, 0, len(created))
+
+	_, apiErr = api.QuoteLetter(context.Background(), &letter.SendReq{Mail: letter.MailOptions{Class: letter.MailClassSecond}, Template: "42"})
+	assert.Equal(t, 400, apiErr.Code)
+}
+
+func TestSendLetterBudget(t *testing.T) {
+	var created int
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/letters/create":
+			created++
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":1,"cost":"1.20","status":"received"}}`))
+		default:
+			t.Fatalf("unexpected path [%s]", r.URL.Path)
+		}
+	}
+
+	// without attachments the template isn't looked up, and the default US price table estimates a one sheet colour
+	// first class letter at 128 cents
+	guard := budget.New(budget.NewMemoryStore(), budget.WithLimit("campaign-a", budget.PeriodDay, 200))
+	api := newTestAPI(t, handler, WithTest(false), WithBudget(guard))
+	request := &letter.SendReq{BudgetKey: "campaign-a", Template: "42"}
+
+	letterRes, apiErr := api.SendLetter(context.Background(), request)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.True(t, letterRes.BudgetError == nil)
+
+	spent, _ := guard.Spent(context.Background(), "campaign-a", budget.PeriodDay)
+	assert.Equal(t, int64(120), spent)
+
+	_, apiErr = api.SendLetter(context.Background(), request)
+	assert.Equal(t, 402, apiErr.Code)
+	assert.Equal(t, budget.ExceededErrorType, apiErr.Type)
+	assert.Equal(t, 1, created)
+
+	// test letters aren't charged so they are never refused
+	_, apiErr = api.SendLetter(context.Background(), &letter.SendReq{BudgetKey: "campaign-a", Template: "42", Test: letter.Bool(true)})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, 2, created)
+
+	// other campaigns aren't limited
+	_, apiErr = api.SendLetter(context.Background(), &letter.SendReq{BudgetKey: "campaign-b", Template: "42"})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	spent, _ = guard.Spent(context.Background(), budget.GlobalKey, budget.PeriodMonth)
+	assert.Equal(t, int64(240), spent)
+}
+
+func TestSendLetterBudgetReusesThePageCount(t *testing.T) {
+	var lookups, downloads int
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/templates/get/42":
+			lookups++
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":42,"pages":1}}`))
+		case "/storage/insert.pdf":
+			downloads++
+			w.Header().Set(ContentTypeHeaderKey, "application/pdf")
+			_, _ = w.Write([]byte("%PDF-1.4\n1 0 obj << /Type /Page >> endobj"))
+		case "/letters/create":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":1,"cost":"1.20","status":"received"}}`))
+		default:
+			t.Fatalf("unexpected path [%s]", r.URL.Path)
+		}
+	}
+
+	guard := budget.New(budget.NewMemoryStore())
+	api := newTestAPI(t, handler, WithTest(false), WithBudget(guard))
+	request := &letter.SendReq{
+		Attachments: []letter.Attachment{{Name: "insert.pdf", URL: api.baseUrl + "/storage/insert.pdf"}},
+		Template:    "42",
+	}
+
+	letterRes, apiErr := api.SendLetter(context.Background(), request)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, 2, letterRes.PageCount)
+	assert.Equal(t, 1, lookups)
+	assert.Equal(t, 1, downloads)
+
+	// the template's page count is remembered for later sends
+	_, apiErr = api.SendLetter(context.Background(), request)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, 1, lookups)
+	assert.Equal(t, 2, downloads)
+}
+
+func TestSendLetterBudgetFallbackEstimate(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/letters/create":
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":1,"cost":"5.00","status":"received"}}`))
+		default:
+			t.Fatalf("unexpected path [%s]", r.URL.Path)
+		}
+	}
+
+	// the table has no price for certified mail, so the letter can't be priced
+	table := letter.PriceTable{
+		Currency:     "USD",
+		DefaultClass: letter.MailClassFirst,
+		FirstSheet:   map[letter.Colour]int64{letter.ColourBlackAndWhite: 100},
+		Postage:      map[letter.MailClass]int64{letter.MailClassFirst: 200},
+	}
+	guard := budget.New(budget.NewMemoryStore(), budget.WithFallbackEstimate(600), budget.WithLimit(budget.GlobalKey, budget.PeriodDay, 1000))
+	api := newTestAPI(t, handler, WithBudget(guard), WithPriceTable(table), WithTest(false))
+	request := &letter.SendReq{
+		Mail:     letter.MailOptions{AddOns: []letter.AddOn{letter.AddOnCertified}, Colour: letter.ColourBlackAndWhite},
+		Template: "42",
+	}
+
+	letterRes, apiErr := api.SendLetter(context.Background(), request)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, 1, len(letterRes.Warnings))
+
+	spent, _ := guard.Spent(context.Background(), budget.GlobalKey, budget.PeriodDay)
+	assert.Equal(t, int64(500), spent)
+
+	// the fallback estimate still counts against the limit
+	_, apiErr = api.SendLetter(context.Background(), request)
+	assert.Equal(t, budget.ExceededErrorType, apiErr.Type)
+}
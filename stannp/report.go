@@ -0,0 +1,68 @@
+package stannp
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/report"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+// IterateLetters pages through the letters created in the request's date range, optionally only those with its status.
+// An invalid request returns an iterator that reads nothing and reports why from Err.
+func (s *Stannp) IterateLetters(ctx context.Context, request *report.ListReq, opts ...IteratorOption) *Iterator[letter.Data] {
+	if request == nil {
+		return newFailedIterator[letter.Data](util.BuildError(400, "request must not be nil"))
+	}
+
+	if validateErr := request.Validate(); validateErr != nil {
+		return newFailedIterator[letter.Data](validateErr)
+	}
+
+	parts := []string{s.baseUrl, report.URL, report.ListURL, request.Start.Format(report.DateFormat), request.End.Format(report.DateFormat)}
+	if request.Status != "" {
+		parts = append(parts, url.PathEscape(string(request.Status)))
+	}
+	listURL := strings.Join(parts, "/")
+
+	return NewKeyedIterator(ctx, func(ctx context.Context, offset, limit int) ([]letter.Data, *util.APIError) {
+		var listRes report.ListRes
+		if listErr := s.listPage(ctx, pageURL(listURL, offset, limit), &listRes, &listRes.Success); listErr != nil {
+			return nil, listErr
+		}
+
+		return listRes.Data, nil
+	}, letterKey, opts...)
+}
+
+// letterKey tells letters apart for NewKeyedIterator. Test letters all have the ID 0, so they aren't told apart.
+func letterKey(data letter.Data) string {
+	if letterID := data.LetterID().String(); letterID != "0" {
+		return letterID
+	}
+
+	return ""
+}
+
+// ListLetters returns every letter IterateLetters finds for request, fetching as many pages as it takes
+func (s *Stannp) ListLetters(ctx context.Context, request *report.ListReq) (*report.ListRes, *util.APIError) {
+	letters, iterErr := s.IterateLetters(ctx, request).Collect(0)
+	if iterErr != nil {
+		return nil, iterErr
+	}
+
+	return &report.ListRes{Data: letters, Success: true}, nil
+}
+
+// SummarizeLetters counts and costs the letters ListLetters returns for request, by status, in the client region's
+// currency
+func (s *Stannp) SummarizeLetters(ctx context.Context, request *report.ListReq) (*report.Summary, *util.APIError) {
+	listRes, listErr := s.ListLetters(ctx, request)
+	if listErr != nil {
+		return nil, listErr
+	}
+
+	return request.Summarize(listRes.Data, s.region.Currency()), nil
+}
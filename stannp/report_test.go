@@ -0,0 +1,60 @@
+package stannp
+
+import (
+	"context"
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/report"
+	"github.com/jgroeneveld/trial/assert"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSummarizeLetters(t *testing.T) {
+	var paths []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":[{"id":1,"cost":"0.84","status":"delivered"},{"id":"2","cost":0.84,"status":"delivered"}]}`))
+	}
+
+	api := newTestAPI(t, handler, WithRegion(letter.RegionUK))
+
+	request := report.Month(2024, time.January)
+	request.Status = letter.LetterStatusDelivered
+	summary, apiErr := api.SummarizeLetters(context.Background(), request)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, 2, summary.Count)
+	assert.Equal(t, letter.Money{Amount: 168, Currency: "GBP"}, summary.Cost)
+
+	listRes, apiErr := api.ListLetters(context.Background(), &report.ListReq{End: request.End, Start: request.Start})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, letter.LetterID("2"), listRes.Data[1].LetterID())
+
+	assert.True(t, reflect.DeepEqual([]string{"/reporting/list/2024-01-01/2024-01-31/delivered", "/reporting/list/2024-01-01/2024-01-31"}, paths))
+
+	_, apiErr = api.ListLetters(context.Background(), &report.ListReq{Start: request.End, End: request.Start})
+	assert.Equal(t, 400, apiErr.Code)
+	assert.Equal(t, 2, len(paths))
+
+	letters := api.IterateLetters(context.Background(), nil)
+	assert.False(t, letters.Next())
+	assert.Equal(t, 400, letters.Err().Code)
+	assert.Equal(t, 2, len(paths))
+}
+
+func TestIterateLettersStopsOnARepeatedPage(t *testing.T) {
+	calls := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":[{"id":1,"status":"delivered"},{"id":2,"status":"delivered"}]}`))
+	}
+
+	api := newTestAPI(t, handler)
+	letters, iterErr := api.IterateLetters(context.Background(), report.Month(2024, time.January), WithPageSize(2)).Collect(0)
+	assert.Equal(t, 502, iterErr.Code)
+	assert.Equal(t, 2, len(letters))
+	assert.Equal(t, 2, calls)
+}
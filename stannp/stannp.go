@@ -1,17 +1,27 @@
 package stannp
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/copilotiq/stannp-client-golang/address"
+	"github.com/copilotiq/stannp-client-golang/address/cache"
+	"github.com/copilotiq/stannp-client-golang/archive"
+	"github.com/copilotiq/stannp-client-golang/budget"
 	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/storage"
+	"github.com/copilotiq/stannp-client-golang/template"
 	"github.com/copilotiq/stannp-client-golang/util"
 )
 
@@ -19,19 +29,51 @@ const APIKeyQSP = "api_key"
 const BaseURL = "https://us.stannp.com/api/v1"
 const ContentTypeHeaderKey = "Content-Type"
 const CreateURL = "create"
+const DefaultStorageDir = "stannp"
+const DefaultMaxPDFSize = 50 << 20
+const DefaultPreviewPollInterval = 2 * time.Second
+const DefaultPreviewTimeout = time.Minute
+const DefaultWatchBatchInterval = time.Second
+const DefaultWatchBatchSize = 10
+const DefaultWatchLookupTimeout = 30 * time.Second
+const GetURL = "get"
+const ListURL = "list"
 const PDFURLPrefix = "https://us.stannp.com/api/v1/storage"
 const URLEncodedHeaderVal = "application/x-www-form-urlencoded"
+const StorageURL = "storage"
+const UKBaseURL = "https://dash.stannp.com/api/v1"
 const ValidateURL = "validate"
 const XIdempotenceyHeaderKey = "X-Idempotency-Key"
 
 type Stannp struct {
-	apiKey         string
-	baseUrl        string
-	clearZone      bool
-	client         *http.Client
-	duplex         bool
-	postUnverified bool
-	test           bool
+	addressCache      *cache.Cache
+	addressPolicy     letter.AddressPolicy
+	apiKey            string
+	archiver          *archive.Archiver
+	baseUrl           string
+	budget            *budget.Guard
+	clearZone         bool
+	client            *http.Client
+	countryChecks     bool
+	duplex            bool
+	maxPDFSize        int64
+	postUnverified    bool
+	previewInterval   time.Duration
+	previewTimeout    time.Duration
+	priceTable        *letter.PriceTable
+	quoteLookups      bool
+	region            letter.Region
+	remoteQuotes      bool
+	sender            *letter.RecipientDetails
+	sheetBoundaries   []int
+	store             storage.Store
+	templatePages     *templatePageCache
+	test              bool
+	verifyMergeFields bool
+	watchBatchSize    int
+	watchInterval     time.Duration
+	watchLookups      *letterLookups
+	watchTimeout      time.Duration
 }
 
 type APIOption func(*Stannp)
@@ -42,6 +84,29 @@ func WithTest(test bool) APIOption {
 	}
 }
 
+// WithArchiver archives the PDF and request of every successful SendLetter in the background, once SendLetter has
+// returned. Archiving failures go to the archiver's archive.WithOnError callback rather than failing the send.
+func WithArchiver(archiver *archive.Archiver) APIOption {
+	return func(s *Stannp) {
+		s.archiver = archiver
+	}
+}
+
+// WithAddressCache makes ValidateAddress serve repeat lookups of the same address from addressCache
+func WithAddressCache(addressCache *cache.Cache) APIOption {
+	return func(s *Stannp) {
+		s.addressCache = addressCache
+	}
+}
+
+// WithBudget makes SendLetter refuse live letters that would take spend over any of guard's limits. Letters are
+// estimated from the price table before sending and their spend recorded from the cost Stannp returns.
+func WithBudget(guard *budget.Guard) APIOption {
+	return func(s *Stannp) {
+		s.budget = guard
+	}
+}
+
 func WithAPIKey(apiKey string) APIOption {
 	return func(s *Stannp) {
 		s.apiKey = apiKey
@@ -60,43 +125,227 @@ func WithClearZone(clearZone bool) APIOption {
 	}
 }
 
+// WithCountryChecks makes SendLetter resolve a recipient's free text country to its ISO 3166-1 code and refuse an
+// address missing something that country's post needs, before anything is sent. Off by default, so recipients are
+// sent as given.
+func WithCountryChecks(countryChecks bool) APIOption {
+	return func(s *Stannp) {
+		s.countryChecks = countryChecks
+	}
+}
+
 func WithDuplex(duplex bool) APIOption {
 	return func(s *Stannp) {
 		s.duplex = duplex
 	}
 }
 
+// WithMaxPDFSize caps the number of bytes GetPDFContents will download for a single letter
+func WithMaxPDFSize(maxPDFSize int64) APIOption {
+	return func(s *Stannp) {
+		s.maxPDFSize = maxPDFSize
+	}
+}
+
 func WithHTTPClient(hc *http.Client) APIOption {
 	return func(s *Stannp) {
 		s.client = hc
 	}
 }
 
+func WithBaseURL(baseURL string) APIOption {
+	return func(s *Stannp) {
+		s.baseUrl = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithPreviewPolling controls how often and for how long PreviewLetter, and archiving, wait for a letter PDF to become
+// available
+func WithPreviewPolling(interval, timeout time.Duration) APIOption {
+	return func(s *Stannp) {
+		s.previewInterval = interval
+		s.previewTimeout = timeout
+	}
+}
+
+// WithPriceTable sets the prices QuoteLetter itemises letters with, in place of the indicative defaults for the region
+func WithPriceTable(priceTable letter.PriceTable) APIOption {
+	return func(s *Stannp) {
+		s.priceTable = &priceTable
+	}
+}
+
+// WithQuoteLookups makes QuoteLetter look up the template and download attachments given by URL to count the letter's
+// pages. Off by default, so quoting works offline from the price table, using template page counts the client already
+// knows and quoting a single sheet with a warning otherwise.
+func WithQuoteLookups(quoteLookups bool) APIOption {
+	return func(s *Stannp) {
+		s.quoteLookups = quoteLookups
+	}
+}
+
+// WithRemoteQuotes makes QuoteLetter also ask Stannp for the price by submitting the letter as a test letter, which
+// shows up on the account and goes through any address validation SendLetter would do. Off by default, so quotes come
+// from the price table alone and have no side effects.
+func WithRemoteQuotes(remoteQuotes bool) APIOption {
+	return func(s *Stannp) {
+		s.remoteQuotes = remoteQuotes
+	}
+}
+
+// WithRegion selects the Stannp platform to send through, setting the base url, unless WithBaseURL sets one, and the
+// mail options letters are validated against. Defaults to letter.RegionUS.
+func WithRegion(region letter.Region) APIOption {
+	return func(s *Stannp) {
+		s.region = region
+	}
+}
+
+// WithSender sets the return address printed on letters that don't set SendReq.Sender
+func WithSender(sender letter.RecipientDetails) APIOption {
+	return func(s *Stannp) {
+		s.sender = &sender
+	}
+}
+
+// WithSheetBoundaries sets the sheet counts SendLetter warns about when attachments take a letter past them
+func WithSheetBoundaries(sheetBoundaries ...int) APIOption {
+	return func(s *Stannp) {
+		s.sheetBoundaries = sheetBoundaries
+	}
+}
+
+// WithStorage sets where SavePDFContents writes letter PDFs. Defaults to a private LocalStore in the user's cache
+// directory, see DefaultStorage.
+func WithStorage(store storage.Store) APIOption {
+	return func(s *Stannp) {
+		s.store = store
+	}
+}
+
+// WithValidateBeforeSend makes SendLetter validate the recipient's address first and act on the result according to
+// policy. The outcome is reported on SendRes.AddressCheck. An empty policy turns validation off.
+func WithValidateBeforeSend(policy letter.AddressPolicy) APIOption {
+	return func(s *Stannp) {
+		s.addressPolicy = policy
+	}
+}
+
+// WithVerifyMergeFields makes SendLetter look up the template first and refuse to send when any of its merge fields
+// would be blank
+func WithVerifyMergeFields(verifyMergeFields bool) APIOption {
+	return func(s *Stannp) {
+		s.verifyMergeFields = verifyMergeFields
+	}
+}
+
+// WithWatchBatching limits WatchLetter to at most size letter lookups every interval, shared by every letter being
+// watched. Defaults to DefaultWatchBatchSize every DefaultWatchBatchInterval.
+func WithWatchBatching(size int, interval time.Duration) APIOption {
+	return func(s *Stannp) {
+		s.watchBatchSize = size
+		s.watchInterval = interval
+	}
+}
+
+// WithWatchLookupTimeout bounds each of WatchLetter's lookups, which no single watcher's context covers since watchers
+// of the same letter share them. Defaults to DefaultWatchLookupTimeout.
+func WithWatchLookupTimeout(timeout time.Duration) APIOption {
+	return func(s *Stannp) {
+		s.watchTimeout = timeout
+	}
+}
+
 func New(options ...APIOption) *Stannp {
 	api := &Stannp{
-		apiKey:         "test123456",
-		baseUrl:        BaseURL,
-		clearZone:      true,
-		client:         http.DefaultClient,
-		duplex:         true,
-		postUnverified: false,
-		test:           true,
+		apiKey:          "test123456",
+		clearZone:       true,
+		client:          http.DefaultClient,
+		duplex:          true,
+		maxPDFSize:      DefaultMaxPDFSize,
+		postUnverified:  false,
+		previewInterval: DefaultPreviewPollInterval,
+		previewTimeout:  DefaultPreviewTimeout,
+		region:          letter.RegionUS,
+		sheetBoundaries: letter.DefaultSheetBoundaries,
+		store:           DefaultStorage(),
+		templatePages:   &templatePageCache{pages: map[string]int{}},
+		test:            true,
+		watchBatchSize:  DefaultWatchBatchSize,
+		watchInterval:   DefaultWatchBatchInterval,
+		watchTimeout:    DefaultWatchLookupTimeout,
 	}
 
 	for _, option := range options {
 		option(api)
 	}
+
+	if api.baseUrl == "" {
+		api.baseUrl = regionBaseURL(api.region)
+	}
+
+	api.watchLookups = newLetterLookups(api.GetLetter, api.watchBatchSize, api.watchInterval, api.watchTimeout)
 	return api
 }
 
+// DefaultStorage is where letter PDFs are saved without WithStorage: DefaultStorageDir in the user's cache directory, or
+// in os.TempDir() when there isn't one. Either way saving is refused unless only the current user can access the
+// directory, so PDFs are never written somewhere another user created.
+func DefaultStorage() *storage.LocalStore {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return storage.NewPrivateLocalStore(filepath.Join(dir, DefaultStorageDir))
+}
+
+// regionBaseURL is the Stannp platform for region
+func regionBaseURL(region letter.Region) string {
+	if region == letter.RegionUK {
+		return UKBaseURL
+	}
+
+	return BaseURL
+}
+
 func (s *Stannp) PostUnverified() bool {
 	return s.postUnverified
 }
 
+func (s *Stannp) Region() letter.Region {
+	return s.region
+}
+
 func (s *Stannp) IsTest() bool {
 	return s.test
 }
 
+// SendOptions are the client defaults applied to letters that don't override them
+func (s *Stannp) SendOptions() letter.SendOptions {
+	return letter.SendOptions{
+		ClearZone:      s.clearZone,
+		Duplex:         s.duplex,
+		PostUnverified: s.postUnverified,
+		Test:           s.test,
+	}
+}
+
+// pdfURLPrefix is PDFURLPrefix for the default base url, and the storage path under any other base url
+func (s *Stannp) pdfURLPrefix() string {
+	return strings.Join([]string{s.baseUrl, StorageURL}, "/")
+}
+
+// pdfURLPrefixes are the storage paths GetPDFContents downloads from: the one under the configured base url, and
+// PDFURLPrefix, which Stannp returns for letter PDFs whichever base url the request was sent to
+func (s *Stannp) pdfURLPrefixes() []string {
+	if prefix := s.pdfURLPrefix(); prefix != PDFURLPrefix {
+		return []string{prefix, PDFURLPrefix}
+	}
+
+	return []string{PDFURLPrefix}
+}
+
 func (s *Stannp) wrapAuth(inputURL string) (string, *util.APIError) {
 	u, err := url.Parse(inputURL)
 	if err != nil {
@@ -109,7 +358,30 @@ func (s *Stannp) wrapAuth(inputURL string) (string, *util.APIError) {
 	return u.String(), nil
 }
 
+func (s *Stannp) get(ctx context.Context, inputURL string) (*http.Response, *util.APIError) {
+	authURL, wrapErr := s.wrapAuth(inputURL)
+	if wrapErr != nil {
+		return nil, wrapErr
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, authURL, nil)
+	if err != nil {
+		return nil, util.BuildError(500, fmt.Sprintf("error generating GET req [%+v] for req [%+v]", err, req))
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, util.BuildError(500, fmt.Sprintf("error sending req [%+v]", req))
+	}
+
+	return res, nil
+}
+
 func (s *Stannp) post(ctx context.Context, inputReader io.Reader, inputURL, idempotenceyHeaderVal string) (*http.Response, *util.APIError) {
+	return s.postWithContentType(ctx, inputReader, inputURL, idempotenceyHeaderVal, URLEncodedHeaderVal)
+}
+
+func (s *Stannp) postWithContentType(ctx context.Context, inputReader io.Reader, inputURL, idempotenceyHeaderVal, contentType string) (*http.Response, *util.APIError) {
 	authURL, wrapErr := s.wrapAuth(inputURL)
 	if wrapErr != nil {
 		return nil, wrapErr
@@ -120,7 +392,7 @@ func (s *Stannp) post(ctx context.Context, inputReader io.Reader, inputURL, idem
 		return nil, util.BuildError(500, fmt.Sprintf("error generating POST req [%+v] for req [%+v]", err, req))
 	}
 
-	req.Header.Set(ContentTypeHeaderKey, URLEncodedHeaderVal)
+	req.Header.Set(ContentTypeHeaderKey, contentType)
 
 	if idempotenceyHeaderVal != "" {
 		req.Header.Set(XIdempotenceyHeaderKey, idempotenceyHeaderVal)
@@ -135,10 +407,18 @@ func (s *Stannp) post(ctx context.Context, inputReader io.Reader, inputURL, idem
 }
 
 func (s *Stannp) GetPDFContents(ctx context.Context, pdfURL string) (*letter.PDFRes, *util.APIError) {
-	if !strings.HasPrefix(pdfURL, PDFURLPrefix) {
-		return nil, util.BuildError(400, fmt.Sprintf("pdfURL must begin with [%s]. your input was [%s]", PDFURLPrefix, pdfURL))
+	prefixes := s.pdfURLPrefixes()
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(pdfURL, prefix) {
+			return s.downloadPDF(ctx, pdfURL)
+		}
 	}
 
+	return nil, util.BuildError(400, fmt.Sprintf("pdfURL must begin with [%s]. your input was [%s]", strings.Join(prefixes, "] or ["), pdfURL))
+}
+
+// downloadPDF fetches and validates a PDF from any url, leaving it to callers to decide which urls they trust
+func (s *Stannp) downloadPDF(ctx context.Context, pdfURL string) (*letter.PDFRes, *util.APIError) {
 	fileURL, err := url.Parse(pdfURL)
 	if err != nil {
 		return nil, util.BuildError(500, err.Error())
@@ -156,71 +436,407 @@ func (s *Stannp) GetPDFContents(ctx context.Context, pdfURL string) (*letter.PDF
 	if err != nil {
 		return nil, util.BuildError(500, err.Error())
 	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		code := http.StatusBadGateway
+		if resp.StatusCode == http.StatusNotFound {
+			code = http.StatusNotFound
+		}
+		return nil, util.BuildTypedError(code, letter.PDFStatusErrorType, fmt.Sprintf("unexpected status code [%d] fetching pdf [%s]", resp.StatusCode, pdfURL))
+	}
+
+	if !isPDFContentType(resp.Header.Get(ContentTypeHeaderKey)) {
+		return nil, util.BuildTypedError(http.StatusUnsupportedMediaType, letter.PDFContentTypeErrorType, fmt.Sprintf("unexpected content type [%s] fetching pdf [%s]", resp.Header.Get(ContentTypeHeaderKey), pdfURL))
+	}
+
+	if resp.ContentLength > s.maxPDFSize {
+		return nil, util.BuildTypedError(http.StatusRequestEntityTooLarge, letter.PDFTooLargeErrorType, fmt.Sprintf("pdf [%s] is [%d] bytes which exceeds the limit of [%d]", pdfURL, resp.ContentLength, s.maxPDFSize))
+	}
+
+	// read one byte past the limit so a missing or lying Content-Length is still caught
+	contents, readErr := io.ReadAll(io.LimitReader(resp.Body, s.maxPDFSize+1))
+	if readErr != nil {
+		return nil, util.BuildTypedError(http.StatusBadGateway, letter.PDFReadErrorType, fmt.Sprintf("error reading pdf [%s] with err [%+v]", pdfURL, readErr))
+	}
 
+	if int64(len(contents)) > s.maxPDFSize {
+		return nil, util.BuildTypedError(http.StatusRequestEntityTooLarge, letter.PDFTooLargeErrorType, fmt.Sprintf("pdf [%s] exceeds the limit of [%d] bytes", pdfURL, s.maxPDFSize))
+	}
+
+	if !bytes.HasPrefix(contents, []byte(letter.PDFMagicBytes)) {
+		return nil, util.BuildTypedError(http.StatusUnprocessableEntity, letter.PDFInvalidErrorType, fmt.Sprintf("contents of [%s] are not a pdf", pdfURL))
+	}
+
+	checksum := sha256.Sum256(contents)
 	return &letter.PDFRes{
-		Contents: resp.Body,
-		Name:     fileName,
+		Checksum:      hex.EncodeToString(checksum[:]),
+		ContentLength: int64(len(contents)),
+		Contents:      io.NopCloser(bytes.NewReader(contents)),
+		Name:          fileName,
 	}, nil
 }
 
-func (s *Stannp) SavePDFContents(pdfContents io.Reader) (*os.File, *util.APIError) {
-	tmpFile, err := os.CreateTemp("", "stannp_letter.*.pdf")
-	if err != nil {
-		return nil, util.BuildError(500, err.Error())
+// isPDFContentType accepts the content types Stannp storage serves PDFs with, or none at all
+func isPDFContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch mediaType {
+	case "", "application/pdf", "application/octet-stream", "binary/octet-stream":
+		return true
+	default:
+		return false
+	}
+}
+
+// LoadPDFContents reads back a PDF previously saved for letterID by SavePDFContents
+func (s *Stannp) LoadPDFContents(ctx context.Context, letterID string) (*letter.PDFRes, *util.APIError) {
+	if idErr := storage.ValidateLetterID(letterID); idErr != nil {
+		return nil, idErr
+	}
+
+	key := storage.LetterKey(letterID)
+	contents, metadata, openErr := s.store.Open(ctx, key)
+	if openErr != nil {
+		return nil, openErr
+	}
+
+	return &letter.PDFRes{
+		Checksum:      metadata.Checksum,
+		ContentLength: metadata.ContentLength,
+		Contents:      contents,
+		Name:          key,
+	}, nil
+}
+
+// SavePDFContents stores pdfContents under a name derived from letterID, replacing any previous copy
+func (s *Stannp) SavePDFContents(ctx context.Context, letterID string, pdfContents io.Reader) (*storage.Metadata, *util.APIError) {
+	if idErr := storage.ValidateLetterID(letterID); idErr != nil {
+		return nil, idErr
+	}
+
+	return s.store.Save(ctx, storage.LetterKey(letterID), pdfContents, &storage.Metadata{LetterID: letterID})
+}
+
+// GetLetter looks up a letter by its ID
+func (s *Stannp) GetLetter(ctx context.Context, letterID string) (*letter.GetRes, *util.APIError) {
+	if letterID == "" {
+		return nil, util.BuildError(400, "letterID must not be empty")
+	}
+
+	res, getErr := s.get(ctx, strings.Join([]string{s.baseUrl, letter.URL, GetURL, url.PathEscape(letterID)}, "/"))
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	var letterRes letter.GetRes
+	resErr := util.ResToType(res.StatusCode, res.Body, &letterRes)
+	return &letterRes, resErr
+}
+
+func (s *Stannp) GetTemplate(ctx context.Context, templateID string) (*template.GetRes, *util.APIError) {
+	if templateID == "" {
+		return nil, util.BuildError(400, "templateID must not be empty")
+	}
+
+	res, getErr := s.get(ctx, strings.Join([]string{s.baseUrl, template.URL, GetURL, url.PathEscape(templateID)}, "/"))
+	if getErr != nil {
+		return nil, getErr
 	}
 
-	_, copyErr := io.Copy(tmpFile, pdfContents)
-	if copyErr != nil {
-		removeErr := os.Remove(tmpFile.Name())
-		if removeErr != nil {
-			return nil, util.BuildError(500, removeErr.Error())
+	var templateRes template.GetRes
+	resErr := util.ResToType(res.StatusCode, res.Body, &templateRes)
+	return &templateRes, resErr
+}
+
+// IterateTemplates reads the templates on the account a page at a time. Stannp sends every template in one response,
+// so they are fetched once and served from memory.
+func (s *Stannp) IterateTemplates(ctx context.Context, opts ...IteratorOption) *Iterator[template.Data] {
+	return NewIterator(ctx, listFetcher(func(ctx context.Context) ([]template.Data, *util.APIError) {
+		templatesRes, listErr := s.ListTemplates(ctx)
+		if listErr != nil {
+			return nil, listErr
 		}
 
-		return nil, util.BuildError(500, copyErr.Error())
+		return templatesRes.Data, nil
+	}), opts...)
+}
+
+func (s *Stannp) ListTemplates(ctx context.Context) (*template.ListRes, *util.APIError) {
+	res, getErr := s.get(ctx, strings.Join([]string{s.baseUrl, template.URL, ListURL}, "/"))
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	var templatesRes template.ListRes
+	resErr := util.ResToType(res.StatusCode, res.Body, &templatesRes)
+	return &templatesRes, resErr
+}
+
+// listPage fetches one page of a list endpoint into listRes, failing when Stannp reports it wasn't successful
+func (s *Stannp) listPage(ctx context.Context, inputURL string, listRes interface{}, success *bool) *util.APIError {
+	res, getErr := s.get(ctx, inputURL)
+	if getErr != nil {
+		return getErr
+	}
+
+	if resErr := util.ResToType(res.StatusCode, res.Body, listRes); resErr != nil {
+		return resErr
+	}
+
+	if !*success {
+		return util.BuildError(502, fmt.Sprintf("listing [%s] was not successful", strings.SplitN(inputURL, "?", 2)[0]))
+	}
+
+	return nil
+}
+
+func (s *Stannp) checkMergeFields(ctx context.Context, request *letter.SendReq, formData url.Values) *util.APIError {
+	templateRes, getErr := s.GetTemplate(ctx, request.Template)
+	if getErr != nil {
+		return getErr
 	}
 
-	return tmpFile, nil
+	// merge fields may be satisfied by either the recipient details or the custom merge variables
+	values := map[string]string{}
+	for key := range formData {
+		if strings.HasPrefix(key, "recipient[") && strings.HasSuffix(key, "]") {
+			values[strings.TrimSuffix(strings.TrimPrefix(key, "recipient["), "]")] = formData.Get(key)
+		}
+	}
+
+	missing := templateRes.Data.MissingMergeFields(values)
+	if len(missing) > 0 {
+		return util.BuildError(400, fmt.Sprintf("template [%s] is missing merge fields [%s]", request.Template, strings.Join(missing, ", ")))
+	}
+
+	return nil
+}
+
+// PreviewLetter renders request as a test letter, regardless of the client's test setting, and returns the proof PDF
+// once Stannp has made it available. Nothing is mailed.
+func (s *Stannp) PreviewLetter(ctx context.Context, request *letter.SendReq) (*letter.PDFRes, *util.APIError) {
+	// never reuse the caller's idempotency key, otherwise the live send would be answered with this test letter
+	previewReq := *request
+	previewReq.IdempotenceyKey = ""
+	previewReq.Test = letter.Bool(true)
+
+	letterRes, sendErr := s.sendLetter(ctx, &previewReq, &pageCount{})
+	if sendErr != nil {
+		return nil, sendErr
+	}
+
+	if letterRes.Data.PDFURL == "" {
+		return nil, util.BuildError(500, fmt.Sprintf("preview of template [%s] did not return a pdf url", request.Template))
+	}
+
+	waitErr := s.waitForPDF(ctx, letterRes.Data.PDFURL)
+	if waitErr != nil {
+		return nil, waitErr
+	}
+
+	return s.GetPDFContents(ctx, letterRes.Data.PDFURL)
+}
+
+func (s *Stannp) waitForPDF(parent context.Context, pdfURL string) *util.APIError {
+	ctx, cancel := context.WithTimeout(parent, s.previewTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(s.previewInterval)
+	defer ticker.Stop()
+
+	for {
+		headReq, reqErr := http.NewRequestWithContext(ctx, http.MethodHead, pdfURL, nil)
+		if reqErr != nil {
+			return util.BuildError(500, reqErr.Error())
+		}
+
+		resp, err := s.client.Do(headReq)
+		if err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			// the caller giving up is not the pdf timing out
+			if parent.Err() != nil {
+				return util.BuildError(500, parent.Err().Error())
+			}
+
+			return util.BuildError(504, fmt.Sprintf("timed out waiting for pdf [%s] to become available", pdfURL))
+		case <-ticker.C:
+		}
+	}
 }
 
 func (s *Stannp) SendLetter(ctx context.Context, request *letter.SendReq) (*letter.SendRes, *util.APIError) {
+	pages := &pageCount{}
+	reservation, budgetWarnings, budgetErr := s.reserveBudget(ctx, request, pages)
+	if budgetErr != nil {
+		return nil, budgetErr
+	}
+
+	letterRes, sendErr := s.sendLetter(ctx, request, pages)
+	if reservation != nil {
+		settleBudget(ctx, reservation, letterRes, sendErr)
+	}
+
+	if letterRes != nil {
+		letterRes.Warnings = append(letterRes.Warnings, budgetWarnings...)
+	}
+
+	if sendErr != nil || s.archiver == nil || !letterRes.Success {
+		return letterRes, sendErr
+	}
+
+	// archive the address the letter actually went to
+	archivedReq := *request
+	if letterRes.AddressCheck != nil {
+		archivedReq.Recipient = letterRes.AddressCheck.Used
+	}
+
+	s.archiver.Submit(ctx, waitingPDFFetcher{s}, &archivedReq, letterRes, time.Now())
+	return letterRes, nil
+}
+
+// waitingPDFFetcher waits for a freshly sent letter's PDF to be rendered before downloading it
+type waitingPDFFetcher struct {
+	s *Stannp
+}
+
+func (f waitingPDFFetcher) GetPDFContents(ctx context.Context, pdfURL string) (*letter.PDFRes, *util.APIError) {
+	if waitErr := f.s.waitForPDF(ctx, pdfURL); waitErr != nil {
+		return nil, waitErr
+	}
+
+	return f.s.GetPDFContents(ctx, pdfURL)
+}
+
+func (s *Stannp) sendLetter(ctx context.Context, request *letter.SendReq, pages *pageCount) (*letter.SendRes, *util.APIError) {
+	options := request.Options(s.SendOptions())
+
+	if mailErr := request.Mail.Validate(s.region); mailErr != nil {
+		return nil, mailErr
+	}
+
+	if s.countryChecks {
+		var countryErr *util.APIError
+		request, countryErr = checkCountry(request)
+		if countryErr != nil {
+			return nil, countryErr
+		}
+	}
+
+	var addressCheck *letter.AddressCheck
+	if s.addressPolicy != "" {
+		var checkErr *util.APIError
+		request, addressCheck, checkErr = s.checkRecipient(ctx, request)
+		if checkErr != nil {
+			return nil, checkErr
+		}
+	}
+
 	formData := url.Values{}
-	formData.Set("clearzone", strconv.FormatBool(s.clearZone))
-	formData.Set("duplex", strconv.FormatBool(s.duplex))
-	formData.Set("post_unverified", strconv.FormatBool(s.postUnverified))
-	formData.Set("recipient[address1]", request.Recipient.Address1)
-	formData.Set("recipient[address2]", request.Recipient.Address2)
-	formData.Set("recipient[country]", request.Recipient.Country)
-	formData.Set("recipient[firstname]", request.Recipient.Firstname)
-	formData.Set("recipient[lastname]", request.Recipient.Lastname)
-	formData.Set("recipient[state]", request.Recipient.State)
-	formData.Set("recipient[title]", request.Recipient.Title)
-	formData.Set("recipient[town]", request.Recipient.Town)
-	formData.Set("recipient[zipcode]", request.Recipient.Zipcode)
+	formData.Set("clearzone", strconv.FormatBool(options.ClearZone))
+	formData.Set("duplex", strconv.FormatBool(options.Duplex))
+	formData.Set("post_unverified", strconv.FormatBool(options.PostUnverified))
+	setAddressFields(formData, "recipient", request.Recipient)
 	formData.Set("template", request.Template)
-	formData.Set("test", strconv.FormatBool(s.test))
+	formData.Set("test", strconv.FormatBool(options.Test))
+
+	if request.PostDate != nil {
+		formData.Set("post_date", request.PostDate.Format(letter.PostDateFormat))
+	}
+
+	// without a sender Stannp uses the account's return address
+	if sender := s.senderFor(request); sender != nil {
+		setAddressFields(formData, "sender", *sender)
+	}
+
+	for key, value := range request.Mail.FormValues() {
+		formData.Set(key, value)
+	}
 
 	// set custom merge variables in the formData
 	for key, value := range request.MergeVariables {
 		formData.Set("recipient["+key+"]", value)
 	}
 
-	res, postErr := s.post(ctx, strings.NewReader(formData.Encode()), strings.Join([]string{s.baseUrl, letter.URL, CreateURL}, "/"), request.IdempotenceyKey)
+	if s.verifyMergeFields {
+		mergeErr := s.checkMergeFields(ctx, request, formData)
+		if mergeErr != nil {
+			return nil, mergeErr
+		}
+	}
+
+	body := io.Reader(strings.NewReader(formData.Encode()))
+	contentType := URLEncodedHeaderVal
+	totalPages := 0
+	var warnings []string
+
+	if len(request.Attachments) > 0 {
+		var attachErr *util.APIError
+		totalPages, warnings, attachErr = pages.count(ctx, s, request, options.Duplex, true)
+		if attachErr != nil {
+			return nil, attachErr
+		}
+
+		body, contentType, attachErr = attachmentBody(formData, request.Attachments)
+		if attachErr != nil {
+			return nil, attachErr
+		}
+	}
+
+	res, postErr := s.postWithContentType(ctx, body, strings.Join([]string{s.baseUrl, letter.URL, CreateURL}, "/"), request.IdempotenceyKey, contentType)
 	if postErr != nil {
 		return nil, postErr
 	}
 
 	var letterRes letter.SendRes
 	resErr := util.ResToType(res.StatusCode, res.Body, &letterRes)
+	letterRes.AddressCheck = addressCheck
+	letterRes.MailOptions = request.Mail
+	letterRes.PageCount = totalPages
+	letterRes.Warnings = warnings
 	return &letterRes, resErr
 }
 
+func (s *Stannp) senderFor(request *letter.SendReq) *letter.RecipientDetails {
+	if request.Sender != nil {
+		return request.Sender
+	}
+
+	return s.sender
+}
+
+func setAddressFields(formData url.Values, prefix string, details letter.RecipientDetails) {
+	formData.Set(prefix+"[address1]", details.Address1)
+	formData.Set(prefix+"[address2]", details.Address2)
+	formData.Set(prefix+"[country]", details.Country)
+	formData.Set(prefix+"[firstname]", details.Firstname)
+	formData.Set(prefix+"[lastname]", details.Lastname)
+	formData.Set(prefix+"[state]", details.State)
+	formData.Set(prefix+"[title]", details.Title)
+	formData.Set(prefix+"[town]", details.Town)
+	formData.Set(prefix+"[zipcode]", details.Zipcode)
+}
+
 func (s *Stannp) ValidateAddress(ctx context.Context, request *address.ValidateReq) (*address.ValidateRes, *util.APIError) {
+	if s.addressCache != nil {
+		if cached, ok := s.addressCache.Get(ctx, request); ok {
+			return cached, nil
+		}
+	}
+
 	// Create URL values
 	formData := url.Values{}
 	formData.Set("company", request.Company)
 	formData.Set("address1", request.Address1)
 	formData.Set("address2", request.Address2)
 	formData.Set("city", request.City)
+	formData.Set("state", request.State)
 	formData.Set("zipcode", request.Zipcode)
 	formData.Set("country", request.Country)
 
@@ -231,5 +847,10 @@ func (s *Stannp) ValidateAddress(ctx context.Context, request *address.ValidateR
 
 	var addressRes address.ValidateRes
 	resErr := util.ResToType(res.StatusCode, res.Body, &addressRes)
+	if resErr == nil && s.addressCache != nil {
+		// a cache failure is counted in its Stats and shouldn't fail a validation that succeeded
+		_ = s.addressCache.Set(ctx, request, &addressRes)
+	}
+
 	return &addressRes, resErr
 }
@@ -6,16 +6,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/copilotiq/stannp-client-golang/address"
+	"github.com/copilotiq/stannp-client-golang/address/cache"
+	"github.com/copilotiq/stannp-client-golang/archive"
 	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/storage"
 	"github.com/copilotiq/stannp-client-golang/util"
 	"github.com/jgroeneveld/trial/assert"
 	"github.com/joho/godotenv"
+	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -54,6 +60,15 @@ func setup() {
 	}
 }
 
+// newTestAPI serves handler from a test server, closed when the test ends, and returns a client pointed at it with opts
+// applied
+func newTestAPI(t *testing.T, handler http.HandlerFunc, opts ...APIOption) *Stannp {
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	return New(append([]APIOption{WithBaseURL(ts.URL), WithHTTPClient(ts.Client())}, opts...)...)
+}
+
 //goland:noinspection GoBoolExpressions
 func TestNew(t *testing.T) {
 	err := godotenv.Load("../.env")
@@ -118,6 +133,449 @@ func TestPost(t *testing.T) {
 	assert.Equal(t, http.StatusOK, res.StatusCode)
 }
 
+func TestSendLetterVerifyMergeFields(t *testing.T) {
+	sent := false
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/templates/get/42":
+			assert.Equal(t, http.MethodGet, r.Method)
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":42,"template_name":"reminder","merge_fields":["firstname","appointment_date","clinic"]}}`))
+		case "/letters/create":
+			sent = true
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":1,"status":"test"}}`))
+		default:
+			t.Fatalf("unexpected path [%s]", r.URL.Path)
+		}
+	}
+
+	api := newTestAPI(t, handler, WithVerifyMergeFields(true))
+
+	request := &letter.SendReq{
+		MergeVariables: letter.MergeVariables{"appointment_date": "2023-07-01", "clinic": ""},
+		Recipient:      letter.RecipientDetails{Firstname: "Judge"},
+		Template:       "42",
+	}
+
+	res, apiErr := api.SendLetter(context.Background(), request)
+	assert.True(t, reflect.ValueOf(res).IsNil())
+	assert.NotNil(t, apiErr)
+	assert.Equal(t, 400, apiErr.Code)
+	assert.Equal(t, "template [42] is missing merge fields [clinic]", apiErr.ErrorMessage)
+	assert.False(t, sent)
+
+	request.MergeVariables["clinic"] = "Beverly Hills"
+	res, apiErr = api.SendLetter(context.Background(), request)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.True(t, res.Success)
+	assert.True(t, sent)
+}
+
+func TestSendLetterOverrides(t *testing.T) {
+	var forms []url.Values
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, r.ParseForm())
+		forms = append(forms, r.PostForm)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":1,"status":"test"}}`))
+	}
+
+	api := newTestAPI(t, handler,
+		WithClearZone(true),
+		WithDuplex(true),
+		WithPostUnverified(false),
+		WithTest(false),
+	)
+
+	_, apiErr := api.SendLetter(context.Background(), &letter.SendReq{Template: "42"})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	_, apiErr = api.SendLetter(context.Background(), &letter.SendReq{
+		ClearZone:      letter.Bool(false),
+		Duplex:         letter.Bool(false),
+		PostUnverified: letter.Bool(true),
+		Template:       "42",
+		Test:           letter.Bool(true),
+	})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	assert.Equal(t, 2, len(forms))
+	for key, expected := range map[string][]string{
+		"clearzone":       {"true", "false"},
+		"duplex":          {"true", "false"},
+		"post_unverified": {"false", "true"},
+		"test":            {"false", "true"},
+	} {
+		assert.Equal(t, expected[0], forms[0].Get(key), key)
+		assert.Equal(t, expected[1], forms[1].Get(key), key)
+	}
+}
+
+func TestSendLetterMailOptions(t *testing.T) {
+	var form url.Values
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, r.ParseForm())
+		form = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":1,"status":"test"}}`))
+	}
+
+	api := newTestAPI(t, handler)
+
+	mail := letter.MailOptions{AddOns: []letter.AddOn{letter.AddOnCertified}, Class: letter.MailClassFirst, Colour: letter.ColourFull}
+	res, apiErr := api.SendLetter(context.Background(), &letter.SendReq{Mail: mail, Template: "42"})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.True(t, reflect.DeepEqual(mail, res.MailOptions))
+	assert.Equal(t, "first", form.Get("class"))
+	assert.Equal(t, "colour", form.Get("colour"))
+	assert.Equal(t, "certified", form.Get("addons"))
+	assert.Equal(t, "", form.Get("envelope"))
+
+	// invalid options are rejected before anything is sent
+	form = nil
+	res, apiErr = api.SendLetter(context.Background(), &letter.SendReq{Mail: letter.MailOptions{Class: letter.MailClassSecond}, Template: "42"})
+	assert.True(t, reflect.ValueOf(res).IsNil())
+	assert.Equal(t, 400, apiErr.Code)
+	assert.True(t, form == nil)
+
+	assert.Equal(t, UKBaseURL, New(WithRegion(letter.RegionUK)).baseUrl)
+	// an explicit base url wins whichever order the options are given in
+	assert.Equal(t, "http://localhost:8080", New(WithBaseURL("http://localhost:8080/"), WithRegion(letter.RegionUK)).baseUrl)
+	assert.Equal(t, "http://localhost:8080", New(WithRegion(letter.RegionUK), WithBaseURL("http://localhost:8080")).baseUrl)
+}
+
+func TestSendLetterSender(t *testing.T) {
+	var forms []url.Values
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, r.ParseForm())
+		forms = append(forms, r.PostForm)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":1,"status":"test"}}`))
+	}
+
+	clinic := letter.RecipientDetails{Address1: "1 Clinic Way", Firstname: "Westside", Lastname: "Clinic", State: "CA", Town: "Los Angeles", Zipcode: "90001"}
+	other := letter.RecipientDetails{Address1: "2 Other St", Firstname: "Eastside", Lastname: "Clinic", State: "NY", Town: "New York", Zipcode: "10001"}
+
+	_, apiErr := newTestAPI(t, handler).SendLetter(context.Background(), &letter.SendReq{Template: "42"})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	api := newTestAPI(t, handler, WithSender(clinic))
+	_, apiErr = api.SendLetter(context.Background(), &letter.SendReq{Template: "42"})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	_, apiErr = api.SendLetter(context.Background(), &letter.SendReq{Sender: &other, Template: "42"})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	assert.Equal(t, 3, len(forms))
+	_, hasSender := forms[0]["sender[address1]"]
+	assert.False(t, hasSender)
+	assert.Equal(t, "1 Clinic Way", forms[1].Get("sender[address1]"))
+	assert.Equal(t, "Westside", forms[1].Get("sender[firstname]"))
+	assert.Equal(t, "2 Other St", forms[2].Get("sender[address1]"))
+	assert.Equal(t, "10001", forms[2].Get("sender[zipcode]"))
+}
+
+func TestSendLetterPostDate(t *testing.T) {
+	postDates := []string{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, r.ParseForm())
+		postDates = append(postDates, r.PostForm.Get("post_date"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":1,"status":"test"}}`))
+	}
+
+	api := newTestAPI(t, handler)
+
+	_, apiErr := api.SendLetter(context.Background(), &letter.SendReq{Template: "42"})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	postDate := time.Date(2023, time.July, 5, 15, 0, 0, 0, time.UTC)
+	_, apiErr = api.SendLetter(context.Background(), &letter.SendReq{PostDate: &postDate, Template: "42"})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	assert.True(t, reflect.DeepEqual([]string{"", "2023-07-05"}, postDates))
+}
+
+func TestPreviewLetter(t *testing.T) {
+	pdfHeadCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/letters/create":
+			assert.Nil(t, r.ParseForm())
+			assert.Equal(t, "true", r.PostForm.Get("test"))
+			assert.Equal(t, "", r.Header.Get(XIdempotenceyHeaderKey))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":0,"status":"test","pdf":"http://` + r.Host + `/storage/get/proof.pdf"}}`))
+		case "/storage/get/proof.pdf":
+			// the first check finds the pdf still rendering
+			if r.Method == http.MethodHead {
+				pdfHeadCount++
+				if pdfHeadCount == 1 {
+					w.WriteHeader(http.StatusNotFound)
+				}
+				return
+			}
+			w.Header().Set(ContentTypeHeaderKey, "application/pdf")
+			_, _ = w.Write([]byte("%PDF-1.4 proof"))
+		default:
+			t.Fatalf("unexpected path [%s]", r.URL.Path)
+		}
+	}
+
+	api := newTestAPI(t, handler,
+		WithPreviewPolling(time.Millisecond, time.Second),
+		WithTest(false),
+	)
+
+	pdfRes, apiErr := api.PreviewLetter(context.Background(), &letter.SendReq{IdempotenceyKey: util.RandomString(10), Template: "42"})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, "proof.pdf", pdfRes.Name)
+	assert.Equal(t, 2, pdfHeadCount)
+
+	contents, readErr := io.ReadAll(pdfRes.Contents)
+	assert.Nil(t, readErr)
+	assert.Equal(t, "%PDF-1.4 proof", string(contents))
+}
+
+func TestPreviewLetterNeverRendered(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/letters/create" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":0,"status":"test","pdf":"http://` + r.Host + `/storage/get/proof.pdf"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}
+
+	api := newTestAPI(t, handler, WithPreviewPolling(time.Millisecond, 20*time.Millisecond))
+
+	_, apiErr := api.PreviewLetter(context.Background(), &letter.SendReq{Template: "42"})
+	assert.Equal(t, 504, apiErr.Code)
+
+	// the caller cancelling is reported as such, not as the pdf timing out
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	api = newTestAPI(t, handler, WithPreviewPolling(time.Millisecond, time.Minute))
+
+	_, apiErr = api.PreviewLetter(ctx, &letter.SendReq{Template: "42"})
+	assert.Equal(t, 500, apiErr.Code)
+	assert.Equal(t, context.DeadlineExceeded.Error(), apiErr.ErrorMessage)
+}
+
+func TestSendLetterArchiveFailureDoesNotFailSend(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/letters/create":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":7,"status":"received","pdf":"http://` + r.Host + `/storage/get/7.pdf"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	var failedLetterID string
+	store := storage.NewMemoryStore()
+	archiver := archive.New(store, []byte("secret"), archive.WithOnError(func(letterID string, _ *util.APIError) {
+		failedLetterID = letterID
+	}))
+	api := newTestAPI(t, handler, WithArchiver(archiver), WithPreviewPolling(time.Millisecond, 10*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	res, apiErr := api.SendLetter(ctx, &letter.SendReq{Template: "42"})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.True(t, res.Success)
+
+	// archiving happens after the send returns and isn't stopped by the caller's context ending
+	cancel()
+	archiver.Wait()
+	assert.Equal(t, "7", failedLetterID)
+	assert.Equal(t, 0, len(store.Keys()))
+}
+
+func TestValidateAddressDecodesFullResult(t *testing.T) {
+	var form url.Values
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/addresses/validate", r.URL.Path)
+		assert.Nil(t, r.ParseForm())
+		form = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+  "success": true,
+  "data": {
+    "is_valid": true,
+    "address1": "9355 BURTON WAY",
+    "address2": "",
+    "city": "BEVERLY HILLS",
+    "state": "CA",
+    "zipcode": "90210-3669",
+    "country": "US",
+    "deliverability": "deliverable",
+    "dpv_match_code": "Y",
+    "is_residential": "N",
+    "is_vacant": false,
+    "components": [{"component": "city", "input": "Beverly Hils", "matched": "BEVERLY HILLS", "status": "corrected"}]
+  }
+}`))
+	}
+
+	api := newTestAPI(t, handler)
+	request := &address.ValidateReq{Address1: "9355 Burton Way", City: "Beverly Hils", Country: "US", State: "CA", Zipcode: "90210"}
+
+	validateRes, apiErr := api.ValidateAddress(context.Background(), request)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, "CA", form.Get("state"))
+
+	data := validateRes.Data
+	assert.True(t, data.IsValid)
+	assert.True(t, data.IsDeliverable())
+	assert.Equal(t, "Y", data.DPVMatchCode)
+	assert.Equal(t, address.FlagNo, data.IsResidential)
+	assert.Equal(t, address.FlagNo, data.IsVacant)
+	assert.Equal(t, "corrected", data.Components[0].Status)
+
+	recipient := data.RecipientDetails(letter.RecipientDetails{Firstname: "Judge", Lastname: "Judy", Town: "Beverly Hils", Zipcode: "90210"})
+	assert.Equal(t, "Judge", recipient.Firstname)
+	assert.Equal(t, "BEVERLY HILLS", recipient.Town)
+	assert.Equal(t, "90210-3669", recipient.Zipcode)
+}
+
+func TestValidateAddressCache(t *testing.T) {
+	var calls atomic.Int32
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success": true, "data": {"is_valid": true, "deliverability": "deliverable", "zipcode": "90210-3669"}}`))
+	}
+
+	addressCache := cache.New()
+	api := newTestAPI(t, handler, WithAddressCache(addressCache))
+
+	validateRes, apiErr := api.ValidateAddress(context.Background(), &address.ValidateReq{Address1: "9355 Burton Way", Zipcode: "90210"})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, "90210-3669", validateRes.Data.Zipcode)
+
+	validateRes, apiErr = api.ValidateAddress(context.Background(), &address.ValidateReq{Address1: "9355 BURTON WAY", Country: "US", Zipcode: "90210"})
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+	assert.Equal(t, "90210-3669", validateRes.Data.Zipcode)
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, cache.Stats{Hits: 1, Misses: 1}, addressCache.Stats())
+}
+
+func TestGetPDFContents(t *testing.T) {
+	tests := []struct {
+		name              string
+		status            int
+		contentType       string
+		body              string
+		expectedCode      int
+		expectedErrorType string
+	}{
+		{
+			name:        "valid pdf",
+			status:      http.StatusOK,
+			contentType: "application/pdf",
+			body:        "%PDF-1.4 letter",
+		},
+		{
+			name:              "not found",
+			status:            http.StatusNotFound,
+			contentType:       "text/html",
+			body:              "<html>not found</html>",
+			expectedCode:      http.StatusNotFound,
+			expectedErrorType: letter.PDFStatusErrorType,
+		},
+		{
+			name:              "storage error",
+			status:            http.StatusForbidden,
+			contentType:       "application/xml",
+			body:              "<Error>AccessDenied</Error>",
+			expectedCode:      http.StatusBadGateway,
+			expectedErrorType: letter.PDFStatusErrorType,
+		},
+		{
+			name:              "html content type",
+			status:            http.StatusOK,
+			contentType:       "text/html; charset=utf-8",
+			body:              "%PDF-1.4 letter",
+			expectedCode:      http.StatusUnsupportedMediaType,
+			expectedErrorType: letter.PDFContentTypeErrorType,
+		},
+		{
+			name:              "missing magic bytes",
+			status:            http.StatusOK,
+			contentType:       "application/octet-stream",
+			body:              "definitely not a pdf",
+			expectedCode:      http.StatusUnprocessableEntity,
+			expectedErrorType: letter.PDFInvalidErrorType,
+		},
+		{
+			name:              "too large",
+			status:            http.StatusOK,
+			contentType:       "application/pdf",
+			body:              "%PDF-1.4 " + strings.Repeat("x", 64),
+			expectedCode:      http.StatusRequestEntityTooLarge,
+			expectedErrorType: letter.PDFTooLargeErrorType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(ContentTypeHeaderKey, tt.contentType)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}
+			api := newTestAPI(t, handler, WithMaxPDFSize(32))
+
+			pdfRes, apiErr := api.GetPDFContents(context.Background(), api.baseUrl+"/storage/get/letter.pdf")
+			if tt.expectedErrorType != "" {
+				assert.True(t, reflect.ValueOf(pdfRes).IsNil())
+				assert.NotNil(t, apiErr)
+				assert.Equal(t, tt.expectedCode, apiErr.Code)
+				assert.Equal(t, tt.expectedErrorType, apiErr.Type)
+				return
+			}
+
+			assert.True(t, reflect.ValueOf(apiErr).IsNil())
+			assert.Equal(t, "letter.pdf", pdfRes.Name)
+			assert.Equal(t, int64(len(tt.body)), pdfRes.ContentLength)
+			assert.Equal(t, "d9c2649d18ac2776f94479589c8247d72e7c070977240abf79e33a2ffd54302c", pdfRes.Checksum)
+		})
+	}
+}
+
+// roundTripFunc lets a test answer requests to hosts it can't reach
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestGetPDFContentsPrefixes(t *testing.T) {
+	var requested []string
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		requested = append(requested, r.URL.String())
+		return &http.Response{
+			Body:       io.NopCloser(strings.NewReader("%PDF-1.4 letter")),
+			Header:     http.Header{ContentTypeHeaderKey: []string{"application/pdf"}},
+			StatusCode: http.StatusOK,
+		}, nil
+	})}
+	api := New(WithBaseURL("https://proxy.example.com/stannp"), WithHTTPClient(client))
+
+	// Stannp returns the canonical storage url whichever base url the letter was sent through
+	for _, pdfURL := range []string{"https://proxy.example.com/stannp/storage/get/letter.pdf", PDFURLPrefix + "/get/letter.pdf"} {
+		pdfRes, apiErr := api.GetPDFContents(context.Background(), pdfURL)
+		assert.True(t, reflect.ValueOf(apiErr).IsNil())
+		assert.Equal(t, "letter.pdf", pdfRes.Name)
+	}
+
+	_, apiErr := api.GetPDFContents(context.Background(), "https://elsewhere.example.com/storage/get/letter.pdf")
+	assert.Equal(t, http.StatusBadRequest, apiErr.Code)
+	assert.Equal(t, 2, len(requested))
+}
+
 func TestStannp(t *testing.T) {
 	t.Run("test SendLetter and verify the response is correct", func(t *testing.T) {
 		request := &letter.SendReq{
@@ -159,17 +617,24 @@ func TestStannp(t *testing.T) {
 			assert.NotNil(t, pdfRes)
 
 			t.Run("test SavePDFContents and verify the response is correct", func(t *testing.T) {
-				fileRes, fileErr := TestClient.SavePDFContents(pdfRes.Contents)
-				assert.True(t, reflect.ValueOf(fileErr).IsNil())
+				// every test letter has id 0, which can't be stored, so save this one under an id of its own
+				letterID := "test-" + util.RandomString(10)
+				metadata, saveErr := TestClient.SavePDFContents(context.Background(), letterID, pdfRes.Contents)
+				assert.True(t, reflect.ValueOf(saveErr).IsNil())
+				assert.Equal(t, pdfRes.Checksum, metadata.Checksum)
 				defer func() {
-					removeErr := os.Remove(fileRes.Name())
+					removeErr := TestClient.store.Delete(context.Background(), metadata.Key)
 					if removeErr != nil {
-						panic(fmt.Sprintf("Error deleting temp file while testing. Please verify [%s] does not exist on your local disk.", fileRes.Name()))
+						panic(fmt.Sprintf("Error deleting temp file while testing. Please verify [%s] does not exist on your local disk.", metadata.Key))
 					}
 				}()
 
-				content, err := os.ReadFile(fileRes.Name())
+				savedRes, loadErr := TestClient.LoadPDFContents(context.Background(), letterID)
+				assert.True(t, reflect.ValueOf(loadErr).IsNil())
+
+				content, err := io.ReadAll(savedRes.Contents)
 				assert.Nil(t, err)
+				assert.Nil(t, savedRes.Contents.Close())
 
 				// Compare the length of the original data versus the expected return result to see if the PDF changed
 				assert.True(t, len(content) > 600000)
@@ -0,0 +1,260 @@
+package stannp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+const DefaultWatchInterval = time.Minute
+const DefaultWatchMaxInterval = 6 * time.Hour
+
+// WatchOptions controls how often WatchLetter looks a letter up
+type WatchOptions struct {
+	Interval    time.Duration       // wait after a change before looking again, DefaultWatchInterval when zero
+	Last        letter.LetterStatus // status already known, e.g. from SendRes; only changes from it are reported
+	MaxInterval time.Duration       // the wait doubles while nothing changes, up to this; DefaultWatchMaxInterval when zero
+}
+
+// WatchEvent reports a change in a watched letter's status, or a failure to find out
+type WatchEvent struct {
+	Change   letter.StatusChange
+	Data     letter.Data    // the letter as last looked up
+	Err      *util.APIError // set when the lookup failed or Stannp reported an impossible transition; watching carries on
+	LetterID string
+}
+
+// WatchLetter follows a letter's status, sending an event on the returned channel each time it changes. The letter is
+// looked up straight away, then again after opts.Interval, backing off while its status stays the same. Statuses are
+// reconciled through letter.Lifecycle, so events never go backwards. The channel is closed once the letter reaches a
+// terminal status or ctx is done. Lookups for every watched letter share the limit set by WithWatchBatching.
+func (s *Stannp) WatchLetter(ctx context.Context, letterID string, opts WatchOptions) (<-chan WatchEvent, *util.APIError) {
+	if letterID == "" {
+		return nil, util.BuildError(400, "letterID must not be empty")
+	}
+
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultWatchInterval
+	}
+
+	if opts.MaxInterval <= 0 {
+		opts.MaxInterval = DefaultWatchMaxInterval
+	}
+
+	opts.MaxInterval = max(opts.MaxInterval, opts.Interval)
+
+	events := make(chan WatchEvent)
+	go s.watch(ctx, letterID, opts, events)
+	return events, nil
+}
+
+func (s *Stannp) watch(ctx context.Context, letterID string, opts WatchOptions, events chan<- WatchEvent) {
+	defer close(events)
+
+	lifecycle := letter.Lifecycle{LetterID: letter.LetterID(letterID), Status: opts.Last}
+	var wait time.Duration // look up straight away
+	for !lifecycle.IsTerminal() {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		data, lookupErr := s.watchLookups.lookup(ctx, letterID)
+		if ctx.Err() != nil {
+			return
+		}
+
+		event := WatchEvent{Data: data, Err: lookupErr, LetterID: letterID}
+		changed := false
+		if lookupErr == nil {
+			changed, event.Err = lifecycle.Observe(data.LetterStatus(), time.Now().UTC())
+		}
+
+		switch {
+		case changed:
+			event.Change = lifecycle.History[len(lifecycle.History)-1]
+			wait = opts.Interval
+		case wait == 0:
+			wait = opts.Interval
+		default:
+			wait = min(2*wait, opts.MaxInterval)
+		}
+
+		if !changed && event.Err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case events <- event:
+		}
+	}
+}
+
+// letterLookups runs WatchLetter's lookups in rounds of at most size letters every interval, so watching many letters
+// stays within Stannp's rate limits. Stannp has no call to look up several letters at once, so a round is a set of
+// concurrent single lookups, and watchers of the same letter share one lookup. Each lookup is given timeout to finish
+// and is cancelled once every watcher waiting on it has given up.
+type letterLookups struct {
+	get      func(ctx context.Context, letterID string) (*letter.GetRes, *util.APIError)
+	interval time.Duration
+	mu       sync.Mutex
+	queue    []string
+	running  bool
+	size     int
+	timeout  time.Duration
+	waiting  map[string]*pendingLookup
+}
+
+// pendingLookup is a lookup of one letter and the watchers waiting on it. ctx and cancel are set once its round starts.
+type pendingLookup struct {
+	cancel  context.CancelFunc
+	ctx     context.Context
+	waiters []chan lookupResult
+}
+
+type lookupResult struct {
+	data letter.Data
+	err  *util.APIError
+}
+
+func newLetterLookups(get func(ctx context.Context, letterID string) (*letter.GetRes, *util.APIError), size int, interval, timeout time.Duration) *letterLookups {
+	if size <= 0 {
+		size = DefaultWatchBatchSize
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultWatchLookupTimeout
+	}
+
+	return &letterLookups{
+		get:      get,
+		interval: interval,
+		size:     size,
+		timeout:  timeout,
+		waiting:  map[string]*pendingLookup{},
+	}
+}
+
+// lookup queues letterID for the next round with room for it and waits for the result
+func (l *letterLookups) lookup(ctx context.Context, letterID string) (letter.Data, *util.APIError) {
+	result := make(chan lookupResult, 1)
+
+	l.mu.Lock()
+	pending, ok := l.waiting[letterID]
+	if !ok {
+		pending = &pendingLookup{}
+		l.waiting[letterID] = pending
+		l.queue = append(l.queue, letterID)
+	}
+	pending.waiters = append(pending.waiters, result)
+	if !l.running {
+		l.running = true
+		go l.run()
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		l.abandon(letterID, pending, result)
+		return letter.Data{}, util.BuildError(500, ctx.Err().Error())
+	case res := <-result:
+		return res.data, res.err
+	}
+}
+
+// abandon stops waiting on a lookup. Once no one else is waiting on it, a queued lookup is dropped from the queue and
+// one already running is cancelled.
+func (l *letterLookups) abandon(letterID string, pending *pendingLookup, result chan lookupResult) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, waiter := range pending.waiters {
+		if waiter == result {
+			pending.waiters = append(pending.waiters[:i], pending.waiters[i+1:]...)
+			break
+		}
+	}
+
+	if len(pending.waiters) > 0 {
+		return
+	}
+
+	if pending.cancel != nil {
+		pending.cancel()
+		return
+	}
+
+	delete(l.waiting, letterID)
+	for i, queued := range l.queue {
+		if queued == letterID {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+			break
+		}
+	}
+}
+
+func (l *letterLookups) run() {
+	for {
+		l.mu.Lock()
+		if len(l.queue) == 0 {
+			l.running = false
+			l.mu.Unlock()
+			return
+		}
+
+		n := min(l.size, len(l.queue))
+		round := make(map[string]*pendingLookup, n)
+		for _, letterID := range l.queue[:n] {
+			pending := l.waiting[letterID]
+			pending.ctx, pending.cancel = context.WithTimeout(context.Background(), l.timeout)
+			round[letterID] = pending
+			delete(l.waiting, letterID)
+		}
+		l.queue = append([]string(nil), l.queue[n:]...)
+		l.mu.Unlock()
+
+		var wg sync.WaitGroup
+		for letterID, pending := range round {
+			wg.Add(1)
+			go func(letterID string, pending *pendingLookup) {
+				defer wg.Done()
+				res := l.fetch(pending.ctx, letterID)
+				pending.cancel()
+
+				l.mu.Lock()
+				waiters := pending.waiters
+				l.mu.Unlock()
+
+				for _, waiter := range waiters {
+					waiter <- res
+				}
+			}(letterID, pending)
+		}
+		wg.Wait()
+
+		time.Sleep(l.interval)
+	}
+}
+
+// fetch looks a letter up on behalf of every watcher, so no single watcher's context applies; ctx is the lookup's own
+func (l *letterLookups) fetch(ctx context.Context, letterID string) lookupResult {
+	res, getErr := l.get(ctx, letterID)
+	if getErr != nil {
+		return lookupResult{err: getErr}
+	}
+
+	if !res.Success {
+		return lookupResult{data: res.Data, err: util.BuildError(502, fmt.Sprintf("lookup of letter [%s] was not successful", letterID))}
+	}
+
+	return lookupResult{data: res.Data}
+}
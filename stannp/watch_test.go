@@ -0,0 +1,168 @@
+package stannp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/copilotiq/stannp-client-golang/letter"
+	"github.com/copilotiq/stannp-client-golang/util"
+	"github.com/jgroeneveld/trial/assert"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchLetter(t *testing.T) {
+	statuses := map[string][]string{
+		"1": {"received", "received", "producing", "handed_over", "producing", "delivered"},
+		"2": {"test"},
+		"3": {"received"},
+	}
+	var lookups sync.Map
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		letterID := strings.TrimPrefix(r.URL.Path, "/letters/get/")
+		count, _ := lookups.LoadOrStore(letterID, new(atomic.Int32))
+		n := int(count.(*atomic.Int32).Add(1)) - 1
+		sequence := statuses[letterID]
+		status := sequence[min(n, len(sequence)-1)]
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"success":true,"data":{"id":%s,"status":"%s"}}`, letterID, status)))
+	}
+
+	api := newTestAPI(t, handler, WithWatchBatching(10, time.Millisecond))
+	opts := WatchOptions{Interval: time.Millisecond, MaxInterval: 4 * time.Millisecond}
+
+	events, apiErr := api.WatchLetter(context.Background(), "1", opts)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	var changes []string
+	var rejected int
+	for event := range events {
+		if event.Err != nil {
+			assert.Equal(t, letter.StatusTransitionErrorType, event.Err.Type)
+			rejected++
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s>%s", event.Change.From, event.Change.To))
+	}
+
+	// the repeated received isn't reported, the step back to producing is refused and delivered ends the watch
+	assert.True(t, reflect.DeepEqual([]string{">received", "received>producing", "producing>handed_over", "handed_over>delivered"}, changes))
+	assert.Equal(t, 1, rejected)
+
+	// test letters never change
+	events, _ = api.WatchLetter(context.Background(), "2", opts)
+	event := <-events
+	assert.Equal(t, letter.LetterStatusTest, event.Change.To)
+	_, open := <-events
+	assert.False(t, open)
+
+	// cancelling stops the watch
+	ctx, cancel := context.WithCancel(context.Background())
+	events, _ = api.WatchLetter(ctx, "3", opts)
+	event = <-events
+	assert.Equal(t, letter.LetterStatusReceived, event.Change.To)
+	cancel()
+	_, open = <-events
+	assert.False(t, open)
+
+	_, apiErr = api.WatchLetter(context.Background(), "", opts)
+	assert.Equal(t, 400, apiErr.Code)
+}
+
+func TestWatchLetterBatching(t *testing.T) {
+	interval := 20 * time.Millisecond
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var calls []string
+	var times []time.Time
+	get := func(_ context.Context, letterID string) (*letter.GetRes, *util.APIError) {
+		mu.Lock()
+		calls = append(calls, letterID)
+		times = append(times, time.Now())
+		mu.Unlock()
+		if letterID == "x" {
+			<-release
+		}
+		return &letter.GetRes{Data: letter.Data{ID: json.Number(letterID), Status: "received"}, Success: true}, nil
+	}
+
+	lookups := newLetterLookups(get, 1, interval, time.Second)
+
+	var wg sync.WaitGroup
+	lookup := func(letterID string) {
+		defer wg.Done()
+		data, lookupErr := lookups.lookup(context.Background(), letterID)
+		assert.True(t, reflect.ValueOf(lookupErr).IsNil())
+		assert.Equal(t, letterID, data.LetterID().String())
+	}
+
+	wg.Add(1)
+	go lookup("x")
+	for started := false; !started; time.Sleep(time.Millisecond) {
+		mu.Lock()
+		started = len(calls) == 1
+		mu.Unlock()
+	}
+
+	for _, letterID := range []string{"a", "a", "b", "c"} {
+		wg.Add(1)
+		go lookup(letterID)
+	}
+
+	// hold the first round open until everything else is queued
+	for queued := false; !queued; time.Sleep(time.Millisecond) {
+		lookups.mu.Lock()
+		queued = len(lookups.queue) == 3 && lookups.waiting["a"] != nil && len(lookups.waiting["a"].waiters) == 2
+		lookups.mu.Unlock()
+	}
+	close(release)
+	wg.Wait()
+
+	// both watchers of a shared one lookup, and each round waited for the interval
+	assert.Equal(t, 4, len(calls))
+	assert.Equal(t, "x", calls[0])
+	for i := 1; i < len(times); i++ {
+		assert.True(t, times[i].Sub(times[i-1]) >= interval)
+	}
+}
+
+func TestWatchLetterLookupCancellation(t *testing.T) {
+	started := make(chan struct{}, 1)
+	cancelled := make(chan struct{})
+	get := func(ctx context.Context, letterID string) (*letter.GetRes, *util.APIError) {
+		started <- struct{}{}
+		<-ctx.Done()
+		if letterID == "abandoned" {
+			close(cancelled)
+		}
+		return nil, util.BuildError(500, ctx.Err().Error())
+	}
+
+	// a lookup that never answers gives up after the timeout
+	lookups := newLetterLookups(get, 1, time.Millisecond, 10*time.Millisecond)
+	_, lookupErr := lookups.lookup(context.Background(), "slow")
+	assert.Equal(t, context.DeadlineExceeded.Error(), lookupErr.ErrorMessage)
+	<-started
+
+	// and one every watcher has abandoned is cancelled rather than left running
+	lookups = newLetterLookups(get, 1, time.Millisecond, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, lookupErr = lookups.lookup(ctx, "abandoned")
+	assert.Equal(t, context.Canceled.Error(), lookupErr.ErrorMessage)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("the abandoned lookup was not cancelled")
+	}
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+const MetadataExtension = ".json"
+
+// LocalStore keeps each PDF as a file in a directory with a <key>.json metadata sidecar next to it. Files are written
+// with util.WriteFileAtomic so readers never observe a partial PDF.
+type LocalStore struct {
+	dir     string
+	private bool
+}
+
+var _ Store = (*LocalStore)(nil)
+
+func NewLocalStore(dir string) *LocalStore {
+	return &LocalStore{dir: dir}
+}
+
+// NewPrivateLocalStore is a LocalStore that refuses to save unless dir is a real directory owned by the current user
+// that no one else can read or write, for directories in shared locations another user could have created first
+func NewPrivateLocalStore(dir string) *LocalStore {
+	return &LocalStore{dir: dir, private: true}
+}
+
+func (ls *LocalStore) Dir() string {
+	return ls.dir
+}
+
+// Path is the location of key on disk
+func (ls *LocalStore) Path(key string) string {
+	return filepath.Join(ls.dir, key)
+}
+
+func (ls *LocalStore) Delete(_ context.Context, key string) *util.APIError {
+	if keyErr := validateKey(key); keyErr != nil {
+		return keyErr
+	}
+
+	err := os.Remove(ls.Path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return notFound(key)
+	}
+	if err != nil {
+		return util.BuildError(500, err.Error())
+	}
+
+	err = os.Remove(ls.Path(key) + MetadataExtension)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return util.BuildError(500, err.Error())
+	}
+
+	return nil
+}
+
+func (ls *LocalStore) Open(ctx context.Context, key string) (io.ReadCloser, *Metadata, *util.APIError) {
+	metadata, statErr := ls.Stat(ctx, key)
+	if statErr != nil {
+		return nil, nil, statErr
+	}
+
+	file, err := os.Open(ls.Path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, notFound(key)
+	}
+	if err != nil {
+		return nil, nil, util.BuildError(500, err.Error())
+	}
+
+	return file, metadata, nil
+}
+
+func (ls *LocalStore) Save(_ context.Context, key string, contents io.Reader, metadata *Metadata) (*Metadata, *util.APIError) {
+	if keyErr := validateKey(key); keyErr != nil {
+		return nil, keyErr
+	}
+
+	if err := os.MkdirAll(ls.dir, 0o700); err != nil {
+		return nil, util.BuildError(500, err.Error())
+	}
+
+	if ls.private {
+		if privateErr := checkPrivate(ls.dir); privateErr != nil {
+			return nil, privateErr
+		}
+	}
+
+	hash := sha256.New()
+	contentLength, writeErr := util.WriteFileAtomic(ls.Path(key), io.TeeReader(contents, hash))
+	if writeErr != nil {
+		return nil, writeErr
+	}
+
+	saved := newMetadata(key, metadata, hex.EncodeToString(hash.Sum(nil)), contentLength)
+	sidecar, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return nil, util.BuildError(500, err.Error())
+	}
+
+	_, sidecarErr := util.WriteFileAtomic(ls.Path(key)+MetadataExtension, bytes.NewReader(sidecar))
+	if sidecarErr != nil {
+		return nil, sidecarErr
+	}
+
+	return saved, nil
+}
+
+func (ls *LocalStore) Stat(_ context.Context, key string) (*Metadata, *util.APIError) {
+	if keyErr := validateKey(key); keyErr != nil {
+		return nil, keyErr
+	}
+
+	sidecar, err := os.ReadFile(ls.Path(key) + MetadataExtension)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, notFound(key)
+	}
+	if err != nil {
+		return nil, util.BuildError(500, err.Error())
+	}
+
+	var metadata Metadata
+	if jsonErr := json.Unmarshal(sidecar, &metadata); jsonErr != nil {
+		return nil, util.BuildError(500, fmt.Sprintf("error unmarshalling metadata for [%s] with err [%+v]", key, jsonErr))
+	}
+
+	return &metadata, nil
+}
+
+// checkPrivate fails unless dir is a directory, not a link to one, that only the current user can use
+func checkPrivate(dir string) *util.APIError {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return util.BuildError(500, err.Error())
+	}
+
+	if !info.IsDir() || info.Mode().Perm()&0o077 != 0 || !ownedByCurrentUser(info) {
+		return util.BuildError(500, fmt.Sprintf("storage directory [%s] must be a directory only the current user can access", dir))
+	}
+
+	return nil
+}
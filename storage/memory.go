@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+type memoryObject struct {
+	contents []byte
+	metadata Metadata
+}
+
+// MemoryStore keeps PDFs in memory and is intended for tests
+type MemoryStore struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{objects: map[string]memoryObject{}}
+}
+
+func (ms *MemoryStore) Delete(_ context.Context, key string) *util.APIError {
+	if keyErr := validateKey(key); keyErr != nil {
+		return keyErr
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, ok := ms.objects[key]; !ok {
+		return notFound(key)
+	}
+
+	delete(ms.objects, key)
+	return nil
+}
+
+// Keys returns every stored key in sorted order
+func (ms *MemoryStore) Keys() []string {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	keys := make([]string, 0, len(ms.objects))
+	for key := range ms.objects {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func (ms *MemoryStore) Open(_ context.Context, key string) (io.ReadCloser, *Metadata, *util.APIError) {
+	if keyErr := validateKey(key); keyErr != nil {
+		return nil, nil, keyErr
+	}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	object, ok := ms.objects[key]
+	if !ok {
+		return nil, nil, notFound(key)
+	}
+
+	metadata := object.metadata
+	return io.NopCloser(bytes.NewReader(object.contents)), &metadata, nil
+}
+
+func (ms *MemoryStore) Save(_ context.Context, key string, contents io.Reader, metadata *Metadata) (*Metadata, *util.APIError) {
+	if keyErr := validateKey(key); keyErr != nil {
+		return nil, keyErr
+	}
+
+	// read everything before taking the lock so a slow reader doesn't block other callers
+	data, err := io.ReadAll(contents)
+	if err != nil {
+		return nil, util.BuildError(500, err.Error())
+	}
+
+	checksum := sha256.Sum256(data)
+	saved := newMetadata(key, metadata, hex.EncodeToString(checksum[:]), int64(len(data)))
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.objects[key] = memoryObject{contents: data, metadata: *saved}
+	return saved, nil
+}
+
+func (ms *MemoryStore) Stat(_ context.Context, key string) (*Metadata, *util.APIError) {
+	if keyErr := validateKey(key); keyErr != nil {
+		return nil, keyErr
+	}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	object, ok := ms.objects[key]
+	if !ok {
+		return nil, notFound(key)
+	}
+
+	metadata := object.metadata
+	return &metadata, nil
+}
@@ -0,0 +1,15 @@
+//go:build unix
+
+package storage
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// ownedByCurrentUser reports whether info belongs to the user running this process
+func ownedByCurrentUser(info fs.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid()
+}
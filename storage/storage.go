@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/copilotiq/stannp-client-golang/util"
+)
+
+const LetterKeyPrefix = "stannp_letter_"
+const PDFContentType = "application/pdf"
+const PDFExtension = ".pdf"
+
+type Metadata struct {
+	Checksum      string            `json:"checksum"`
+	ContentLength int64             `json:"contentLength"`
+	ContentType   string            `json:"contentType"`
+	Created       time.Time         `json:"created"`
+	Extra         map[string]string `json:"extra,omitempty"`
+	Key           string            `json:"key"`
+	LetterID      string            `json:"letterId,omitempty"`
+}
+
+// Store persists letter PDFs along with their Metadata. Saving an existing key replaces it.
+type Store interface {
+	Delete(ctx context.Context, key string) *util.APIError
+	Open(ctx context.Context, key string) (io.ReadCloser, *Metadata, *util.APIError)
+	Save(ctx context.Context, key string, contents io.Reader, metadata *Metadata) (*Metadata, *util.APIError)
+	Stat(ctx context.Context, key string) (*Metadata, *util.APIError)
+}
+
+// LetterKey is the deterministic key a letter's PDF is stored under, e.g. stannp_letter_12345.pdf
+func LetterKey(letterID string) string {
+	return LetterKeyPrefix + EscapeKey(letterID) + PDFExtension
+}
+
+// EscapeKey makes name safe to use in a key by replacing every byte other than a letter, digit or dash with an
+// underscore and its hex code, e.g. "a/b" becomes "a_2Fb" and "a_b" becomes "a_5Fb". Distinct names never share a key.
+func EscapeKey(name string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' {
+			escaped.WriteByte(c)
+			continue
+		}
+
+		_, _ = fmt.Fprintf(&escaped, "_%02X", c)
+	}
+
+	return escaped.String()
+}
+
+// ValidateLetterID rejects letter IDs that can't identify a single letter's files. Stannp gives every test letter the
+// ID 0, so they would all be stored under the same key.
+func ValidateLetterID(letterID string) *util.APIError {
+	if letterID == "" {
+		return util.BuildError(400, "letterID must not be empty")
+	}
+
+	if letterID == "0" {
+		return util.BuildError(400, "letterID [0] is shared by every test letter and cannot be stored")
+	}
+
+	return nil
+}
+
+func validateKey(key string) *util.APIError {
+	if key == "" || strings.HasPrefix(key, ".") || strings.ContainsAny(key, `/\`) {
+		return util.BuildError(400, fmt.Sprintf("invalid storage key [%s]", key))
+	}
+
+	return nil
+}
+
+func notFound(key string) *util.APIError {
+	return util.BuildError(404, fmt.Sprintf("storage key [%s] not found", key))
+}
+
+// newMetadata copies the caller supplied metadata and fills in the fields the store is responsible for
+func newMetadata(key string, metadata *Metadata, checksum string, contentLength int64) *Metadata {
+	saved := Metadata{}
+	if metadata != nil {
+		saved = *metadata
+	}
+
+	if saved.ContentType == "" {
+		saved.ContentType = PDFContentType
+	}
+
+	saved.Checksum = checksum
+	saved.ContentLength = contentLength
+	saved.Created = time.Now().UTC()
+	saved.Key = key
+	return &saved
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestLetterKey(t *testing.T) {
+	assert.Equal(t, "stannp_letter_12345.pdf", LetterKey("12345"))
+	assert.Equal(t, "stannp_letter__2E_2E_2Fetc_2Fpasswd.pdf", LetterKey("../etc/passwd"))
+	// ids that differ only in their unsafe characters still get their own keys
+	assert.NotEqual(t, LetterKey("a/b"), LetterKey("a_b"))
+	assert.NotEqual(t, LetterKey("a/b"), LetterKey("a_2Fb"))
+}
+
+func TestValidateLetterID(t *testing.T) {
+	assert.True(t, reflect.ValueOf(ValidateLetterID("12345")).IsNil())
+	assert.Equal(t, 400, ValidateLetterID("").Code)
+	assert.Equal(t, 400, ValidateLetterID("0").Code)
+}
+
+func TestStores(t *testing.T) {
+	stores := map[string]Store{
+		"local":  NewLocalStore(t.TempDir()),
+		"memory": NewMemoryStore(),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := LetterKey("12345")
+
+			_, apiErr := store.Stat(ctx, key)
+			assert.Equal(t, 404, apiErr.Code)
+
+			// every store refuses the same keys in every method
+			_, apiErr = store.Save(ctx, "../escape.pdf", bytes.NewBufferString("%PDF-"), nil)
+			assert.Equal(t, 400, apiErr.Code)
+			_, _, apiErr = store.Open(ctx, "../escape.pdf")
+			assert.Equal(t, 400, apiErr.Code)
+			_, apiErr = store.Stat(ctx, ".hidden")
+			assert.Equal(t, 400, apiErr.Code)
+			assert.Equal(t, 400, store.Delete(ctx, "").Code)
+
+			saved, apiErr := store.Save(ctx, key, bytes.NewBufferString("%PDF-1.4 first"), &Metadata{LetterID: "12345", Extra: map[string]string{"template": "42"}})
+			assert.True(t, reflect.ValueOf(apiErr).IsNil())
+			assert.Equal(t, key, saved.Key)
+			assert.Equal(t, "12345", saved.LetterID)
+			assert.Equal(t, PDFContentType, saved.ContentType)
+			assert.Equal(t, int64(14), saved.ContentLength)
+			assert.Equal(t, "42", saved.Extra["template"])
+
+			// saving again replaces the previous copy
+			saved, apiErr = store.Save(ctx, key, bytes.NewBufferString("%PDF-1.4 second"), &Metadata{LetterID: "12345"})
+			assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+			contents, metadata, apiErr := store.Open(ctx, key)
+			assert.True(t, reflect.ValueOf(apiErr).IsNil())
+			assert.Equal(t, saved.Checksum, metadata.Checksum)
+
+			data, err := io.ReadAll(contents)
+			assert.Nil(t, err)
+			assert.Nil(t, contents.Close())
+			assert.Equal(t, "%PDF-1.4 second", string(data))
+
+			assert.True(t, reflect.ValueOf(store.Delete(ctx, key)).IsNil())
+			assert.Equal(t, 404, store.Delete(ctx, key).Code)
+		})
+	}
+}
+
+func TestLocalStoreLeavesNoTemporaryFiles(t *testing.T) {
+	store := NewLocalStore(t.TempDir())
+
+	_, apiErr := store.Save(context.Background(), LetterKey("1"), bytes.NewBufferString("%PDF-1.4"), nil)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	entries, err := os.ReadDir(store.Dir())
+	assert.Nil(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.True(t, reflect.DeepEqual([]string{"stannp_letter_1.pdf", "stannp_letter_1.pdf.json"}, names))
+}
+
+func TestPrivateLocalStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "stannp")
+	store := NewPrivateLocalStore(dir)
+
+	_, apiErr := store.Save(context.Background(), LetterKey("1"), bytes.NewBufferString("%PDF-1.4"), nil)
+	assert.True(t, reflect.ValueOf(apiErr).IsNil())
+
+	// a directory others can read, such as one another user created first, is refused
+	assert.Nil(t, os.Chmod(dir, 0o755))
+	_, apiErr = store.Save(context.Background(), LetterKey("2"), bytes.NewBufferString("%PDF-1.4"), nil)
+	assert.Equal(t, 500, apiErr.Code)
+
+	// as is a link to a directory elsewhere
+	link := filepath.Join(t.TempDir(), "link")
+	assert.Nil(t, os.Symlink(t.TempDir(), link))
+	_, apiErr = NewPrivateLocalStore(link).Save(context.Background(), LetterKey("3"), bytes.NewBufferString("%PDF-1.4"), nil)
+	assert.Equal(t, 500, apiErr.Code)
+}
@@ -0,0 +1,39 @@
+package template
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+const URL = "templates"
+
+type Data struct {
+	ID          json.Number `json:"id"`
+	MergeFields []string    `json:"merge_fields"`
+	Name        string      `json:"template_name"`
+	Pages       json.Number `json:"pages"`
+	Size        string      `json:"size"`
+}
+
+type GetRes struct {
+	Data    Data `json:"data"`
+	Success bool `json:"success"`
+}
+
+type ListRes struct {
+	Data    []Data `json:"data"`
+	Success bool   `json:"success"`
+}
+
+// MissingMergeFields returns the merge fields the template expects that are absent or blank in values, sorted by name
+func (d *Data) MissingMergeFields(values map[string]string) []string {
+	var missing []string
+	for _, field := range d.MergeFields {
+		if values[field] == "" {
+			missing = append(missing, field)
+		}
+	}
+
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,65 @@
+package template
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/jgroeneveld/trial/assert"
+)
+
+func TestData_MissingMergeFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   []string
+		values   map[string]string
+		expected []string
+	}{
+		{name: "no merge fields", values: map[string]string{"first_name": "Ada"}},
+		{name: "all present", fields: []string{"first_name", "plan"}, values: map[string]string{"first_name": "Ada", "plan": "gold"}},
+		{name: "absent", fields: []string{"plan", "first_name"}, values: map[string]string{"first_name": "Ada"}, expected: []string{"plan"}},
+		{name: "blank counts as missing", fields: []string{"first_name"}, values: map[string]string{"first_name": ""}, expected: []string{"first_name"}},
+		{name: "sorted by name", fields: []string{"zip", "plan", "first_name"}, values: nil, expected: []string{"first_name", "plan", "zip"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := Data{MergeFields: tt.fields}
+			missing := data.MissingMergeFields(tt.values)
+			assert.True(t, reflect.DeepEqual(tt.expected, missing), "missing merge fields", missing)
+		})
+	}
+}
+
+func TestGetRes_Unmarshal(t *testing.T) {
+	var res GetRes
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"success": true,
+		"data": {"id": "42", "template_name": "Welcome", "pages": 2, "size": "US-LETTER", "merge_fields": ["first_name", "plan"]}
+	}`), &res))
+
+	assert.True(t, res.Success)
+	assert.Equal(t, json.Number("42"), res.Data.ID)
+	assert.Equal(t, "Welcome", res.Data.Name)
+	assert.Equal(t, json.Number("2"), res.Data.Pages)
+	assert.Equal(t, "US-LETTER", res.Data.Size)
+	assert.True(t, reflect.DeepEqual([]string{"first_name", "plan"}, res.Data.MergeFields))
+}
+
+func TestListRes_Unmarshal(t *testing.T) {
+	var res ListRes
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"success": true,
+		"data": [
+			{"id": 1, "template_name": "Welcome", "pages": "1"},
+			{"id": 2, "template_name": "Renewal", "pages": 3, "merge_fields": []}
+		]
+	}`), &res))
+
+	assert.True(t, res.Success)
+	assert.Equal(t, 2, len(res.Data))
+	assert.Equal(t, json.Number("1"), res.Data[0].ID)
+	assert.Equal(t, json.Number("1"), res.Data[0].Pages)
+	assert.Equal(t, "Renewal", res.Data[1].Name)
+	assert.Equal(t, 0, len(res.Data[1].MergeFields))
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes contents to a temporary file next to path, syncs it and renames it into place, so readers
+// see either the previous file or all of the new one. It returns how many bytes were written.
+//
+// Every store that keeps its state in local files writes through here. None of them lock those files, so a file or
+// directory must only be used by a single process at a time.
+func WriteFileAtomic(path string, contents io.Reader) (int64, *APIError) {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, BuildError(500, err.Error())
+	}
+
+	written, writeErr := io.Copy(tmpFile, contents)
+	if writeErr == nil {
+		writeErr = tmpFile.Sync()
+	}
+
+	closeErr := tmpFile.Close()
+	if writeErr == nil {
+		writeErr = closeErr
+	}
+
+	if writeErr == nil {
+		writeErr = os.Rename(tmpFile.Name(), path)
+	}
+
+	if writeErr != nil {
+		_ = os.Remove(tmpFile.Name())
+		return 0, BuildError(500, writeErr.Error())
+	}
+
+	return written, nil
+}
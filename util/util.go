@@ -12,6 +12,7 @@ type APIError struct {
 	Code         int    `json:"code"`
 	ErrorMessage string `json:"error"`
 	Success      bool   `json:"success"`
+	Type         string `json:"type,omitempty"`
 }
 
 func (apiError *APIError) Error() string {
@@ -30,6 +31,13 @@ func BuildError(code int, errorMessage string) *APIError {
 	}
 }
 
+// BuildTypedError is BuildError for failures callers are expected to tell apart by Type rather than by message
+func BuildTypedError(code int, errorType, errorMessage string) *APIError {
+	apiErr := BuildError(code, errorMessage)
+	apiErr.Type = errorType
+	return apiErr
+}
+
 func RandomString(n int) string {
 	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	bytes := make([]byte, n)